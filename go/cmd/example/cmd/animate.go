@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/input"
+)
+
+var (
+	startFrame int
+	endFrame   int
+)
+
+func init() {
+	animateCmd.Flags().StringVarP(&renderFilename, "filename", "f", "", "Start of each frame's filename, defaults to the scene's name")
+	animateCmd.Flags().IntVar(&startFrame, "start", 0, "First frame to render, overrides the scene")
+	animateCmd.Flags().IntVar(&endFrame, "end", 0, "Last frame to render, overrides the scene")
+	addSceneFlags(animateCmd)
+	animateCmd.Flags().StringVar(&stereoLayout, "stereo", "", "Render both eyes as side-by-side, over-under or anaglyph (red/cyan)")
+	animateCmd.Flags().Float64Var(&interocular, "interocular", 0.3, "Distance between the eyes for --stereo, in world units")
+	animateCmd.Flags().Float64Var(&convergence, "convergence", 0, "Distance where the eyes converge for --stereo, defaults to the focal distance")
+	rootCmd.AddCommand(animateCmd)
+}
+
+var animateCmd = &cobra.Command{
+	Use:   "animate <scene>",
+	Short: "Render an animated scene file's frames",
+	Long: `This renders each frame of an animated scene file to a numbered png, name_0001.png and so on.
+Frames already rendered are skipped, so stopping and running it again carries on where it left off.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		sf, err := readSceneFile(args[0], cmd.Flags().Changed)
+		if err != nil {
+			return err
+		}
+		first, last := 1, 1
+		if sf.Frames != nil {
+			first, last = sf.Frames.Start, sf.Frames.End
+		}
+		if cmd.Flags().Changed("start") {
+			first = startFrame
+		}
+		if cmd.Flags().Changed("end") {
+			last = endFrame
+		}
+		if last < first {
+			return fmt.Errorf("last frame %v is before the first %v", last, first)
+		}
+
+		name := renderFilename
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+		}
+		// built the first time a frame needs rendering
+		var anim *input.Animation
+		for frame := first; frame <= last; frame++ {
+			fname := fmt.Sprintf("%s_%04d.png", name, frame)
+			if _, err := os.Stat(fname); err == nil {
+				fmt.Println(fmt.Sprintf("Skipping: %s already exists", fname))
+				continue
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+
+			start := time.Now()
+			if anim == nil {
+				if anim, err = input.NewAnimation(sf, filepath.Dir(args[0])); err != nil {
+					return err
+				}
+			}
+			s, err := anim.Frame(float64(frame))
+			if err != nil {
+				return fmt.Errorf("frame %v: %w", frame, err)
+			}
+			img, err := render(s.Camera, s.World, s.Settings)
+			if err != nil {
+				return err
+			}
+			if err = writePNG(fname, img.GenerateImg()); err != nil {
+				return err
+			}
+			fmt.Println(fmt.Sprintf("Wrote: %s in %s", fname, time.Since(start)))
+		}
+		return nil
+	},
+}
+
+// writePNG writes to a temporary file first, so a frame that was stopped
+// part way through isn't skipped next time.
+func writePNG(fname string, img image.Image) (err error) {
+	part := fname + ".part"
+	f, err := os.Create(part)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			// closing again after a failed rename does no harm
+			_ = f.Close()
+			_ = os.Remove(part)
+		}
+	}()
+	if err = png.Encode(f, img); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(part, fname)
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+func TestExampleCommands(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		defaults map[string]string
+	}{
+		{
+			name: "teapot",
+			// the high res teapot takes most of a minute at any size
+			args:     []string{"--low-res"},
+			defaults: map[string]string{"samples": "1", "integrator": "whitted", "width": "640", "camera": "perspective"},
+		},
+		{
+			name:     "cubes",
+			defaults: map[string]string{"samples": "1", "integrator": "whitted", "width": "640", "camera": "perspective"},
+		},
+		{
+			name:     "hexagon",
+			defaults: map[string]string{"samples": "1", "integrator": "whitted", "width": "640", "camera": "perspective"},
+		},
+	}
+
+	// before any of them run, flags of the same names on the scene file
+	// commands mustn't have changed them
+	for _, tc := range testCases {
+		c, _, err := rootCmd.Find([]string{tc.name})
+		require.NoError(t, err)
+		for name, value := range tc.defaults {
+			assert.Equal(t, value, c.Flags().Lookup(name).Value.String(), "%s --%s", tc.name, name)
+		}
+	}
+
+	dir := t.TempDir()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// small enough to be quick, the rest of the flags as they are
+			fname := filepath.Join(dir, tc.name)
+			rootCmd.SetArgs(append([]string{tc.name, "--width", "32", "--filename", fname}, tc.args...))
+			require.NoError(t, rootCmd.Execute())
+
+			f, err := os.Open(fname + ".ppm")
+			require.NoError(t, err)
+			defer func() {
+				_ = f.Close()
+			}()
+			header := bufio.NewScanner(f)
+			var lines []string
+			for i := 0; i < 2 && header.Scan(); i++ {
+				lines = append(lines, header.Text())
+			}
+			assert.Equal(t, []string{"P3", "32 18"}, lines)
+		})
+	}
+
+	t.Run("rejects an image with no pixels", func(t *testing.T) {
+		rootCmd.SetArgs([]string{"cubes", "--width", "1", "--filename", filepath.Join(dir, "empty")})
+		assert.ErrorIs(t, rootCmd.Execute(), scene.ErrEmptyImage)
+		assert.NoFileExists(t, filepath.Join(dir, "empty.ppm"))
+	})
+}
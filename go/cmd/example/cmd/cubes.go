@@ -18,9 +18,17 @@ var (
 func init() {
 	// TODO: Move flags to root/global
 	cubesCmd.Flags().BoolVarP(&isJpeg, "jpeg", "j", false, "Switch output to jpeg")
+	cubesCmd.Flags().BoolVar(&toTerminal, "terminal", false, "Draw the image in the terminal instead of writing a file, for terminals with 24-bit colour")
 	cubesCmd.Flags().StringVarP(&cubeFilename, "filename", "f", "cube", "Filename of the output")
-	cubesCmd.Flags().Int16VarP(&samplesPerPixel, "samples", "s", 4, "Number of samples per pixel")
+	cubesCmd.Flags().Int16VarP(&samplesPerPixel, "samples", "s", 1, "Number of samples per pixel, more smooths the edges but takes longer")
+	cubesCmd.Flags().StringVar(&integratorName, "integrator", "whitted", "Rendering algorithm, whitted or path (Monte Carlo path tracing)")
 	cubesCmd.Flags().Int64VarP(&nx, "width", "w", 640, "Image width in pixels")
+	cubesCmd.Flags().StringVar(&cameraName, "camera", "perspective", "Camera projection, perspective, orthographic, fisheye or equirectangular")
+	cubesCmd.Flags().Float64Var(&fieldOfView, "fov", 0, "Field of view in degrees across the image, defaults to 60")
+	cubesCmd.Flags().Float64Var(&viewWidth, "view-width", 10, "World units across the image for the orthographic camera")
+	cubesCmd.Flags().StringVar(&stereoLayout, "stereo", "", "Render both eyes as side-by-side, over-under or anaglyph (red/cyan)")
+	cubesCmd.Flags().Float64Var(&interocular, "interocular", 0.3, "Distance between the eyes for --stereo, in world units")
+	cubesCmd.Flags().Float64Var(&convergence, "convergence", 0, "Distance where the eyes converge for --stereo, defaults to the focal distance")
 	rootCmd.AddCommand(cubesCmd)
 }
 
@@ -89,14 +97,7 @@ var cubesCmd = &cobra.Command{
 }
 
 func getBasicCamera(nx, ny int) (camera scene.Camera, err error) {
-	camera, err = scene.NewBasicCamera(nx, ny, math.Pi/3)
-	if err != nil {
-		return nil, err
-	}
-	err = camera.SetTransform(
-		ray.ViewTransform(
-			ray.NewPoint(0, 1.5, -5), ray.NewPoint(0, 1, 0), ray.NewVec(0, 1, 0)))
-	return camera, err
+	return newCamera(nx, ny, ray.NewPoint(0, 1.5, -5), ray.NewPoint(0, 1, 0), math.Pi/3)
 }
 
 func getBasicRoom() (world scene.World, err error) {
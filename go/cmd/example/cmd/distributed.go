@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/distributed"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/input"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+var (
+	coordinatorAddr string
+	coordinatorURL  string
+	lease           time.Duration
+	tileSize        int
+	workerThreads   int
+)
+
+func init() {
+	addSceneFlags(coordinatorCmd)
+	addSceneFlags(workerCmd)
+	coordinatorCmd.Flags().BoolVarP(&isJpeg, "jpeg", "j", false, "Switch output to jpeg")
+	coordinatorCmd.Flags().BoolVar(&toTerminal, "terminal", false, "Draw the image in the terminal instead of writing a file, for terminals with 24-bit colour")
+	coordinatorCmd.Flags().StringVarP(&renderFilename, "filename", "f", "", "Filename of the output, defaults to the scene's name")
+	coordinatorCmd.Flags().StringVar(&coordinatorAddr, "listen", ":7878", "Address to hand out tiles on")
+	coordinatorCmd.Flags().DurationVar(&lease, "lease", distributed.DefaultLease, "How long a worker has to send a tile back before it's given to another")
+	coordinatorCmd.Flags().IntVar(&tileSize, "tile-size", 0, "Width and height of the tiles in pixels, defaults to 32")
+	workerCmd.Flags().StringVar(&coordinatorURL, "coordinator", "http://localhost:7878", "Address of the coordinator")
+	workerCmd.Flags().IntVar(&workerThreads, "threads", 0, "Tiles to render at once, defaults to the number of CPUs")
+	rootCmd.AddCommand(coordinatorCmd, workerCmd)
+}
+
+var coordinatorCmd = &cobra.Command{
+	Use:   "coordinator <scene>",
+	Short: "Render a scene file across workers",
+	Long: `This hands the tiles of a scene file's image out to workers, started with "example worker" and the same scene and flags,
+and writes the image once they've all been sent back. Tiles from workers that stop are given to another.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		start := time.Now()
+		s, hash, err := loadDistributedScene(args[0], cmd.Flags().Changed)
+		if err != nil {
+			return err
+		}
+		fname := renderFilename
+		if fname == "" {
+			fname = strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+		}
+
+		// events that don't fit are dropped rather than holding up the workers
+		events := make(chan scene.TileEvent, 64)
+		coordinator := distributed.NewCoordinator(
+			distributed.Job{Hash: hash, Width: s.Camera.HSize(), Height: s.Camera.VSize()},
+			distributed.WithLease(lease), distributed.WithTileSize(tileSize), distributed.WithTileEvents(events))
+		printed := make(chan struct{})
+		go func() {
+			for e := range events {
+				fmt.Printf("\rRendered %v/%v tiles (%.0f%%), about %s left   ",
+					e.Done, e.Total, 100*float64(e.Done)/float64(e.Total), e.Remaining().Round(time.Second))
+			}
+			fmt.Println()
+			close(printed)
+		}()
+		srv := &http.Server{Addr: coordinatorAddr, Handler: coordinator}
+		served := make(chan error, 1)
+		go func() {
+			served <- srv.ListenAndServe()
+		}()
+		fmt.Println(fmt.Sprintf("Waiting for workers on %s to render %v,%v with samples: %v",
+			coordinatorAddr, s.Camera.HSize(), s.Camera.VSize(), s.Settings.Samples))
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		var img scene.Canvas
+		select {
+		case err = <-served:
+			return err
+		case <-ctx.Done():
+			_ = srv.Close()
+			return fmt.Errorf("render stopped: %w", ctx.Err())
+		case <-coordinator.Done():
+			img, err = coordinator.Wait(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		// there are no more events once it's done
+		close(events)
+		<-printed
+
+		name, err := writeImage(img, fname)
+		if err != nil {
+			_ = srv.Close()
+			return err
+		}
+		fmt.Println(fmt.Sprintf("Wrote: %s in %s", name, time.Since(start)))
+
+		// keep answering until the workers have been told, so they don't
+		// keep trying to reach a coordinator that's gone
+		if coordinator.Drain(ctx) != nil {
+			// stopped waiting with Ctrl-C
+			return srv.Close()
+		}
+		return srv.Shutdown(context.Background())
+	},
+}
+
+var workerCmd = &cobra.Command{
+	Use:   "worker <scene>",
+	Short: "Render tiles of a scene file for a coordinator",
+	Long: `This renders tiles for "example coordinator" until its image is finished.
+It needs the same scene file, and any models it loads, with the same flags as the coordinator.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		s, hash, err := loadDistributedScene(args[0], cmd.Flags().Changed)
+		if err != nil {
+			return err
+		}
+		threads := workerThreads
+		if threads <= 0 {
+			threads = runtime.GOMAXPROCS(0)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		fmt.Println(fmt.Sprintf("Rendering tiles for %s with %v threads", coordinatorURL, threads))
+		err = distributed.NewWorker(coordinatorURL, hash, s.Camera, s.World, s.Settings,
+			distributed.WithConcurrency(threads)).Work(ctx)
+		if errors.Is(err, distributed.ErrSceneMismatch) {
+			return fmt.Errorf("%w, check the scene file and flags match", err)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println("Finished")
+		return nil
+	},
+}
+
+// loadDistributedScene reads the scene for a coordinator or worker, with a
+// hash so they can check they're rendering the same.
+func loadDistributedScene(fname string, changed func(flag string) bool) (s input.Scene, hash string, err error) {
+	sf, err := readSceneFile(fname, changed)
+	if err != nil {
+		return s, "", err
+	}
+	// the hash leaves out the samples so checkpoints can take more, but
+	// tiles with different samples don't belong in the same image
+	if hash, err = sf.Hash(filepath.Dir(fname)); err != nil {
+		return s, "", err
+	}
+	hash = fmt.Sprintf("%s-%v", hash, sf.Render.Samples)
+	if s, err = sf.Build(filepath.Dir(fname)); err != nil {
+		return s, "", err
+	}
+	// the workers render tiles, nothing checks the size before the image
+	// is written
+	if s.Camera.HSize() < 1 || s.Camera.VSize() < 1 {
+		return s, "", fmt.Errorf("%w: %v,%v", scene.ErrEmptyImage, s.Camera.HSize(), s.Camera.VSize())
+	}
+	return s, hash, nil
+}
@@ -19,9 +19,17 @@ var (
 func init() {
 	// TODO: Move flags to root/global
 	hexagonCmd.Flags().BoolVarP(&isJpeg, "jpeg", "j", false, "Switch output to jpeg")
+	hexagonCmd.Flags().BoolVar(&toTerminal, "terminal", false, "Draw the image in the terminal instead of writing a file, for terminals with 24-bit colour")
 	hexagonCmd.Flags().StringVarP(&hexagonFilename, "filename", "f", "cube", "Filename of the output")
-	hexagonCmd.Flags().Int16VarP(&samplesPerPixel, "samples", "s", 4, "Number of samples per pixel")
+	hexagonCmd.Flags().Int16VarP(&samplesPerPixel, "samples", "s", 1, "Number of samples per pixel, more smooths the edges but takes longer")
+	hexagonCmd.Flags().StringVar(&integratorName, "integrator", "whitted", "Rendering algorithm, whitted or path (Monte Carlo path tracing)")
 	hexagonCmd.Flags().Int64VarP(&nx, "width", "w", 640, "Image width in pixels")
+	hexagonCmd.Flags().StringVar(&cameraName, "camera", "perspective", "Camera projection, perspective, orthographic, fisheye or equirectangular")
+	hexagonCmd.Flags().Float64Var(&fieldOfView, "fov", 0, "Field of view in degrees across the image, defaults to 60")
+	hexagonCmd.Flags().Float64Var(&viewWidth, "view-width", 10, "World units across the image for the orthographic camera")
+	hexagonCmd.Flags().StringVar(&stereoLayout, "stereo", "", "Render both eyes as side-by-side, over-under or anaglyph (red/cyan)")
+	hexagonCmd.Flags().Float64Var(&interocular, "interocular", 0.3, "Distance between the eyes for --stereo, in world units")
+	hexagonCmd.Flags().Float64Var(&convergence, "convergence", 0, "Distance where the eyes converge for --stereo, defaults to the focal distance")
 	rootCmd.AddCommand(hexagonCmd)
 }
 
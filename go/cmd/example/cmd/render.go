@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/input"
+)
+
+var (
+	renderFilename string
+	// the flags overriding a scene file have their own variables, the
+	// example scenes' flags of the same names have their own defaults
+	sceneSamples    int16
+	sceneIntegrator string
+	sceneWidth      int64
+	sceneCamera     string
+	shutter         float64
+)
+
+func init() {
+	renderCmd.Flags().BoolVarP(&isJpeg, "jpeg", "j", false, "Switch output to jpeg")
+	renderCmd.Flags().BoolVar(&toTerminal, "terminal", false, "Draw the image in the terminal instead of writing a file, for terminals with 24-bit colour")
+	renderCmd.Flags().StringVarP(&renderFilename, "filename", "f", "", "Filename of the output, defaults to the scene's name")
+	addSceneFlags(renderCmd)
+	renderCmd.Flags().BoolVar(&progressive, "progressive", false, "Render pass after pass, writing the image so far as it goes")
+	renderCmd.Flags().DurationVar(&timeLimit, "time-limit", 0, "Stop a --progressive render after this long, rendering as many samples as there's time for unless --samples is given")
+	renderCmd.Flags().DurationVar(&snapshotEvery, "snapshot-every", 0, "Write the --progressive image so far this often, like 30s")
+	renderCmd.Flags().StringVar(&checkpointFile, "checkpoint", "", "Save the --progressive render's samples with every snapshot to this file, resuming from it when it's there")
+	renderCmd.Flags().IntVar(&snapshotPasses, "snapshot-passes", 0, "Write the --progressive image so far every this many passes")
+	renderCmd.Flags().StringVar(&stereoLayout, "stereo", "", "Render both eyes as side-by-side, over-under or anaglyph (red/cyan)")
+	renderCmd.Flags().Float64Var(&interocular, "interocular", 0.3, "Distance between the eyes for --stereo, in world units")
+	renderCmd.Flags().Float64Var(&convergence, "convergence", 0, "Distance where the eyes converge for --stereo, defaults to the focal distance")
+	rootCmd.AddCommand(renderCmd)
+}
+
+var renderCmd = &cobra.Command{
+	Use:   "render <scene>",
+	Short: "Render a scene file",
+	Long:  `This renders a scene described in a YAML or JSON file, see test/scenes for examples.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		start := time.Now()
+		sf, err := readSceneFile(args[0], cmd.Flags().Changed)
+		if err != nil {
+			return err
+		}
+		if checkpointFile != "" {
+			if !progressive {
+				return errors.New("--checkpoint needs --progressive")
+			}
+			if checkpointHash, err = sf.Hash(filepath.Dir(args[0])); err != nil {
+				return err
+			}
+		}
+		s, err := sf.Build(filepath.Dir(args[0]))
+		if err != nil {
+			return err
+		}
+		fname := renderFilename
+		if fname == "" {
+			fname = strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+		}
+		fmt.Println(fmt.Sprintf("Generating image %v,%v with samples: %v", s.Camera.HSize(), s.Camera.VSize(), s.Settings.Samples))
+		return renderSceneWith(s.Camera, s.World, s.Settings, start, fname)
+	},
+}
+
+// addSceneFlags adds the flags that override what a scene file says.
+func addSceneFlags(c *cobra.Command) {
+	c.Flags().Int16VarP(&sceneSamples, "samples", "s", 0, "Number of samples per pixel, overrides the scene")
+	c.Flags().StringVar(&sceneIntegrator, "integrator", "", "Rendering algorithm, whitted or path, overrides the scene")
+	c.Flags().Int64VarP(&sceneWidth, "width", "w", 0, "Image width in pixels, overrides the scene keeping its aspect ratio")
+	c.Flags().StringVar(&sceneCamera, "camera", "", "Camera projection, overrides the scene")
+	c.Flags().Float64Var(&shutter, "shutter", 0, "How long the shutter stays open from time 0 to blur moving objects, overrides the scene")
+}
+
+// loadSceneFile reads a scene file, letting the command line flags that
+// were changed override it.
+func loadSceneFile(fname string, changed func(flag string) bool) (s input.Scene, err error) {
+	sf, err := readSceneFile(fname, changed)
+	if err != nil {
+		return s, err
+	}
+	return sf.Build(filepath.Dir(fname))
+}
+
+// readSceneFile is loadSceneFile without building the scene, for commands
+// that change it first.
+func readSceneFile(fname string, changed func(flag string) bool) (sf input.SceneFile, err error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return sf, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	sf, err = input.ParseSceneFile(f)
+	if err != nil {
+		return sf, err
+	}
+
+	if changed("width") && sceneWidth > 0 {
+		setWidth(&sf, int(sceneWidth))
+	}
+	if changed("samples") {
+		sf.Render.Samples = int(sceneSamples)
+	}
+	if changed("integrator") {
+		sf.Render.Integrator = sceneIntegrator
+	}
+	if changed("camera") {
+		sf.Camera.Projection = sceneCamera
+	}
+	if changed("shutter") {
+		sf.Camera.Shutter = []float64{0, shutter}
+	}
+	if sf.Environment != "" {
+		fmt.Println(fmt.Sprintf("Using environment map: %s", sf.Environment))
+	}
+	return sf, nil
+}
+
+// setWidth changes the scene's image width, keeping its aspect ratio.
+func setWidth(sf *input.SceneFile, width int) {
+	if sf.Width > 0 && sf.Height > 0 {
+		sf.Height = int(float64(width) * float64(sf.Height) / float64(sf.Width))
+	} else {
+		sf.Height = int(float64(width) / ratio)
+	}
+	sf.Width = width
+}
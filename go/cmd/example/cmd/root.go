@@ -2,17 +2,24 @@ package cmd
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"image/jpeg"
 	"io"
+	"math"
 	"os"
+	"os/signal"
 	"runtime"
+	"strconv"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/input"
 	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/output"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
 	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
 )
 
@@ -22,11 +29,37 @@ var (
 	lowRes          bool
 	nx              int64
 	isJpeg          bool
+	toTerminal      bool
 	rootCmd         = &cobra.Command{
 		Use:   "example",
 		Short: "Some example 3D renders",
 		Long:  "A selection of 3D renders using the engine build here",
 	}
+
+	environmentFile    string
+	environmentSamples int
+	integratorName     string
+
+	aperture      float64
+	focalDistance float64
+	bokehBlades   int
+
+	cameraName  string
+	fieldOfView float64
+	viewWidth   float64
+
+	stereoLayout string
+	interocular  float64
+	convergence  float64
+
+	progressive    bool
+	timeLimit      time.Duration
+	snapshotEvery  time.Duration
+	snapshotPasses int
+	// checkpointFile keeps a --progressive render's samples so it can be
+	// resumed, checkpointHash is the scene and settings they're for
+	checkpointFile string
+	checkpointHash string
 )
 
 func initConfig() {
@@ -42,59 +75,259 @@ func init() {
 }
 
 func renderScene(c scene.Camera, world scene.World, start time.Time, fname string) (err error) {
+	settings, err := renderSettings()
+	if err != nil {
+		return err
+	}
+	return renderSceneWith(c, world, settings, start, fname)
+}
+
+func renderSceneWith(c scene.Camera, world scene.World, settings scene.RenderSettings, start time.Time, fname string) (err error) {
 	//img := scene.Render(camera, world)
 	workerCount := runtime.GOMAXPROCS(0)
 	fmt.Println(fmt.Sprintf("Setting no of workers to: %v", workerCount))
-	img := scene.MultiThreadedRender(c, world, 8, 1024)
-	generateImg := img.GenerateImg()
+	var img scene.Canvas
+	if progressive {
+		img, err = renderProgressive(c, world, settings, fname)
+	} else {
+		img, err = render(c, world, settings)
+	}
+	if err != nil {
+		return err
+	}
 
-	var outFile *os.File
+	name, err := writeImage(img, fname)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	elapsed := time.Since(start)
+	fmt.Println(fmt.Sprintf("Wrote: %s in %s", name, elapsed))
+	return nil
+}
+
+// writeImage writes the canvas to fname as a jpeg or ppm. It's written to
+// a temporary file first, so stopping part way leaves the last one whole.
+// With --terminal it's drawn in the terminal instead.
+func writeImage(img scene.Canvas, fname string) (name string, err error) {
+	if toTerminal {
+		return "the terminal", drawImage(img)
+	}
+	name = fmt.Sprintf("%s.ppm", fname)
 	if isJpeg {
-		outFile, err = os.Create(fmt.Sprintf("%s.jpg", fname))
+		name = fmt.Sprintf("%s.jpg", fname)
+	}
+	part := name + ".part"
+	outFile, err := os.Create(part)
+	if err != nil {
+		return name, err
+	}
+	defer func() {
 		if err != nil {
-			fmt.Println(err)
-			return err
+			_ = outFile.Close()
+			_ = os.Remove(part)
 		}
+	}()
 
-		err = jpeg.Encode(outFile, generateImg, nil)
-		if err != nil {
-			fmt.Println(err)
-			return err
+	generateImg := img.GenerateImg()
+	if isJpeg {
+		if err = jpeg.Encode(outFile, generateImg, nil); err != nil {
+			return name, err
 		}
 	} else {
-
 		ppmImage, err := output.NewPPMOutput(generateImg)
 		if err != nil {
-			fmt.Println(err)
-			return err
-		}
-		outFile, err = os.Create(fmt.Sprintf("%s.ppm", fname))
-		if err != nil {
-			fmt.Println(err)
-			return err
+			return name, err
 		}
 		writer := bufio.NewWriter(outFile)
-		_, err = io.Copy(writer, ppmImage)
-		if err != nil {
-			fmt.Println(err)
-			return err
+		if _, err = io.Copy(writer, ppmImage); err != nil {
+			return name, err
 		}
 		if err = writer.Flush(); err != nil {
-			return err
+			return name, err
 		}
 	}
 
-	err = outFile.Close()
+	if err = outFile.Close(); err != nil {
+		return name, err
+	}
+	return name, os.Rename(part, name)
+}
+
+// drawImage draws the canvas across the terminal, or $COLUMNS characters
+// when it can't tell how wide it is.
+func drawImage(img scene.Canvas) error {
+	columns, ok := terminalColumns()
+	if !ok {
+		columns = 80
+		if c, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && c > 0 {
+			columns = c
+		}
+	}
+	out, err := output.NewTerminalOutput(img.GenerateImg(), columns)
 	if err != nil {
-		fmt.Println(err)
 		return err
 	}
-
-	elapsed := time.Since(start)
-	fmt.Println(fmt.Sprintf("Wrote: %s in %s", outFile.Name(), elapsed))
+	fmt.Println()
+	_, err = io.Copy(os.Stdout, out)
 	return err
 }
 
+// renderProgressive renders pass after pass, writing what it has so far
+// every --snapshot-every or --snapshot-passes. Ctrl-C stops it, keeping
+// the image so far, and with --checkpoint it carries on where it stopped
+// next time.
+func renderProgressive(c scene.Camera, world scene.World, settings scene.RenderSettings, fname string) (scene.Canvas, error) {
+	if stereoLayout != "" {
+		return nil, errors.New("--progressive can't render --stereo")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	acc := scene.NewAccumulator(c.HSize(), c.VSize())
+	if checkpointFile != "" {
+		loaded, err := scene.LoadCheckpoint(checkpointFile, checkpointHash)
+		switch {
+		case err == nil:
+			acc = loaded
+			fmt.Println(fmt.Sprintf("Resuming from: %s with %v samples per pixel", checkpointFile, acc.MinSamples()))
+		case errors.Is(err, os.ErrNotExist):
+		case errors.Is(err, scene.ErrCheckpointMismatch):
+			return nil, fmt.Errorf("can't resume from %s: %w", checkpointFile, err)
+		default:
+			return nil, err
+		}
+	}
+
+	p := scene.ProgressiveSettings{
+		Samples:        settings.Samples,
+		TimeLimit:      timeLimit,
+		SnapshotEvery:  snapshotEvery,
+		SnapshotPasses: snapshotPasses,
+		Snapshot: func(s scene.Snapshot) error {
+			if checkpointFile != "" {
+				if err := s.Accumulator.SaveCheckpoint(checkpointFile, checkpointHash); err != nil {
+					return err
+				}
+			}
+			name, err := writeImage(s.Canvas, fname)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\rWrote: %s with %v samples per pixel after %s   ", name, s.Samples, s.Elapsed.Round(time.Second))
+			return nil
+		},
+	}
+	if timeLimit > 0 && sceneSamples <= 0 {
+		// as many samples as there's time for
+		p.Samples = 0
+	}
+	acc, err := scene.ResumeProgressive(ctx, acc, c, world, settings, p, scene.WithWorkers(runtime.GOMAXPROCS(0)))
+	fmt.Println()
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("Stopped, keeping the image so far")
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return acc.Canvas(), nil
+}
+
+// render draws the scene, for both eyes when --stereo was asked for.
+func render(c scene.Camera, world scene.World, settings scene.RenderSettings) (scene.Canvas, error) {
+	workers := runtime.GOMAXPROCS(0)
+	if stereoLayout == "" {
+		return renderTiles(c, world, settings, workers)
+	}
+	layout, err := scene.ParseStereoLayout(stereoLayout)
+	if err != nil {
+		return nil, err
+	}
+	distance := convergence
+	if distance <= 0 {
+		distance = c.FocalDistance()
+	}
+	fmt.Println(fmt.Sprintf("Rendering %s stereo with eyes %v apart converging at: %v", layout, interocular, distance))
+	return scene.NewStereoRig(c, interocular, distance).Render(world, settings, layout, workers)
+}
+
+// renderTiles renders with a progress line, stopping on Ctrl-C.
+func renderTiles(c scene.Camera, world scene.World, settings scene.RenderSettings, workers int) (scene.Canvas, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	events := make(chan scene.TileEvent, workers)
+	printed := make(chan struct{})
+	go func() {
+		for e := range events {
+			fmt.Printf("\rRendered %v/%v tiles (%.0f%%), about %s left   ",
+				e.Done, e.Total, 100*float64(e.Done)/float64(e.Total), e.Remaining().Round(time.Second))
+		}
+		fmt.Println()
+		close(printed)
+	}()
+	canvas, err := scene.RenderTiles(ctx, c, world, settings,
+		scene.WithWorkers(workers), scene.WithTileEvents(events))
+	close(events)
+	<-printed
+	if err != nil {
+		return nil, fmt.Errorf("render stopped: %w", err)
+	}
+	return canvas, nil
+}
+
+func renderSettings() (settings scene.RenderSettings, err error) {
+	settings = scene.DefaultRenderSettings()
+	if samplesPerPixel > 0 {
+		settings.Samples = int(samplesPerPixel)
+	}
+	settings.Integrator, err = scene.ParseIntegrator(integratorName)
+	return settings, err
+}
+
+// newCamera makes the camera picked with --camera at from looking at to,
+// seeing fov radians across unless --fov was given, and focused on to.
+func newCamera(nx, ny int, from, to ray.Vector, fov float64) (scene.Camera, error) {
+	projection, err := scene.ParseProjection(cameraName)
+	if err != nil {
+		return nil, err
+	}
+	if fieldOfView > 0 {
+		fov = fieldOfView * math.Pi / 180
+	}
+	opts := []scene.CameraOption{scene.WithProjection(projection)}
+	switch projection {
+	case scene.Orthographic:
+		opts = append(opts, scene.WithFieldOfView(viewWidth))
+	case scene.Equirectangular:
+		// always sees all the way around
+	default:
+		opts = append(opts, scene.WithFieldOfView(fov))
+	}
+	c, err := scene.NewLookAtCamera(nx, ny, from, to, ray.NewVec(0, 1, 0), opts...)
+	if err != nil {
+		return nil, err
+	}
+	setLens(c, to.Subtract(from).Magnitude())
+	return c, nil
+}
+
+// setLens focuses the camera at focus unless a focal distance was given,
+// with depth of field when an aperture was asked for.
+func setLens(c scene.Camera, focus float64) {
+	if focalDistance > 0 {
+		focus = focalDistance
+	}
+	// a pinhole keeps the focal distance too, stereo converges there
+	c.SetLens(aperture, focus)
+	if aperture <= 0 {
+		return
+	}
+	fmt.Println(fmt.Sprintf("Using aperture: %v focused at: %v", aperture, focus))
+	c.SetBokeh(bokehBlades, 0)
+}
+
 func MaxParallelism() int {
 	maxProcs := runtime.GOMAXPROCS(0)
 	numCPU := runtime.NumCPU()
@@ -103,3 +336,17 @@ func MaxParallelism() int {
 	}
 	return numCPU
 }
+
+// addEnvironment uses the map as both the background and a light source.
+func addEnvironment(world scene.World, fname string, samples int) error {
+	c, err := input.LoadImage(fname)
+	if err != nil {
+		return err
+	}
+	fmt.Println(fmt.Sprintf("Using environment map: %s (%vx%v)", fname, c.Width(), c.Height()))
+	light := scene.NewEnvironmentLight(c)
+	light.Samples = samples
+	world.SetEnvironmentLight(light)
+	world.SetBackground(light)
+	return nil
+}
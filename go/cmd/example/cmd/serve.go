@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/server"
+)
+
+var (
+	listenAddr     string
+	sceneDir       string
+	concurrentJobs int
+	queueSize      int
+	keepJobs       int
+	limits         = server.DefaultLimits()
+)
+
+func init() {
+	serveCmd.Flags().StringVar(&listenAddr, "listen", ":8080", "Address to serve the API on")
+	serveCmd.Flags().StringVar(&sceneDir, "dir", ".", "Directory the models and environment maps scenes load are relative to")
+	serveCmd.Flags().IntVar(&concurrentJobs, "jobs", 1, "Number of jobs to render at once")
+	serveCmd.Flags().IntVar(&queueSize, "queue", 100, "Number of jobs that can wait to be rendered")
+	serveCmd.Flags().IntVar(&keepJobs, "keep", 100, "Number of finished jobs to keep the images of")
+	serveCmd.Flags().IntVar(&limits.Width, "max-width", limits.Width, "Widest image a job can ask for, 0 for no limit")
+	serveCmd.Flags().IntVar(&limits.Height, "max-height", limits.Height, "Tallest image a job can ask for, 0 for no limit")
+	serveCmd.Flags().IntVar(&limits.Samples, "max-samples", limits.Samples, "Most samples per pixel a job can ask for, 0 for no limit")
+	rootCmd.AddCommand(serveCmd)
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Render scene files submitted over HTTP",
+	Long: `This serves an HTTP API to render scene files, queueing them as jobs:
+
+  POST   /jobs                 submit a YAML or JSON scene file, with samples, integrator, width and camera query parameters
+  GET    /jobs                 list the jobs
+  GET    /jobs/{id}            a job's status and progress
+  GET    /jobs/{id}/image.png  the finished image, or image.ppm
+  DELETE /jobs/{id}            cancel the job`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		workers := runtime.GOMAXPROCS(0)
+		s := server.NewServer(
+			server.WithDir(sceneDir),
+			server.WithWorkers(workers),
+			server.WithConcurrentJobs(concurrentJobs),
+			server.WithQueueSize(queueSize),
+			server.WithRetention(keepJobs),
+			server.WithLimits(limits))
+		defer s.Close()
+
+		srv := &http.Server{Addr: listenAddr, Handler: s}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			_ = srv.Shutdown(context.Background())
+		}()
+
+		fmt.Println(fmt.Sprintf("Serving on %s with %v workers per job", listenAddr, workers))
+		if err = srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		fmt.Println("Stopped, cancelling the jobs")
+		return nil
+	},
+}
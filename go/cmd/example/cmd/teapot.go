@@ -29,11 +29,24 @@ var (
 func init() {
 	// TODO: Move flags to root/global
 	teapotCmd.Flags().BoolVarP(&isJpeg, "jpeg", "j", false, "Switch output to jpeg")
+	teapotCmd.Flags().BoolVar(&toTerminal, "terminal", false, "Draw the image in the terminal instead of writing a file, for terminals with 24-bit colour")
 	teapotCmd.Flags().StringVarP(&filename, "filename", "f", "teapot", "Filename of the output")
-	teapotCmd.Flags().Int16VarP(&samplesPerPixel, "samples", "s", 4, "Number of samples per pixel")
+	teapotCmd.Flags().Int16VarP(&samplesPerPixel, "samples", "s", 1, "Number of samples per pixel, more smooths the edges but takes longer")
+	teapotCmd.Flags().StringVar(&integratorName, "integrator", "whitted", "Rendering algorithm, whitted or path (Monte Carlo path tracing)")
 	teapotCmd.Flags().Int64VarP(&nx, "width", "w", 640, "Image width in pixels")
 
 	teapotCmd.Flags().BoolVarP(&lowRes, "low-res", "l", false, "Select between low res and high rest")
+	teapotCmd.Flags().StringVarP(&environmentFile, "environment", "e", "", "Light the scene with an equirectangular environment map (.hdr, .png or .jpg)")
+	teapotCmd.Flags().IntVar(&environmentSamples, "environment-samples", 16, "Number of shadow rays per hit toward the environment map")
+	teapotCmd.Flags().StringVar(&cameraName, "camera", "perspective", "Camera projection, perspective, orthographic, fisheye or equirectangular")
+	teapotCmd.Flags().Float64Var(&fieldOfView, "fov", 0, "Field of view in degrees across the image, defaults to 60")
+	teapotCmd.Flags().Float64Var(&viewWidth, "view-width", 10, "World units across the image for the orthographic camera")
+	teapotCmd.Flags().StringVar(&stereoLayout, "stereo", "", "Render both eyes as side-by-side, over-under or anaglyph (red/cyan)")
+	teapotCmd.Flags().Float64Var(&interocular, "interocular", 0.3, "Distance between the eyes for --stereo, in world units")
+	teapotCmd.Flags().Float64Var(&convergence, "convergence", 0, "Distance where the eyes converge for --stereo, defaults to the focal distance")
+	teapotCmd.Flags().Float64Var(&aperture, "aperture", 0, "Lens radius for depth of field, 0 keeps everything in focus")
+	teapotCmd.Flags().Float64Var(&focalDistance, "focal-distance", 0, "Distance in front of the camera that is in focus, defaults to the teapot")
+	teapotCmd.Flags().IntVar(&bokehBlades, "bokeh-blades", 0, "Number of aperture blades shaping out of focus highlights, fewer than 3 is round")
 	rootCmd.AddCommand(teapotCmd)
 }
 
@@ -72,10 +85,12 @@ var teapotCmd = &cobra.Command{
 			object.WithMaterial(planesMaterial),
 		)
 
+		// the backdrop is only there to look at, don't let it block any light
 		p2 := object.NewPlane(
 			object.WithMaterial(planesMaterial),
 			object.WithTransform(ray.Translation(0, 0, -10).Multiply(
 				ray.Rotation(ray.X, math.Pi/2))),
+			object.WithShadows(false, true),
 		)
 
 		teapotMaterial := object.DefaultMaterial()
@@ -97,6 +112,12 @@ var teapotCmd = &cobra.Command{
 
 		world.AddObjects(p, p2, obj)
 
+		if environmentFile != "" {
+			if err = addEnvironment(world, environmentFile, environmentSamples); err != nil {
+				return err
+			}
+		}
+
 		var c scene.Camera
 		c, err = getCamera(int(nx), int(ny))
 		if err != nil {
@@ -119,12 +140,5 @@ func loadTeapot(tp []byte, opts ...object.Option) (o object.Object, err error) {
 }
 
 func getCamera(nx, ny int) (camera scene.Camera, err error) {
-	camera, err = scene.NewBasicCamera(nx, ny, math.Pi/3)
-	if err != nil {
-		return nil, err
-	}
-	err = camera.SetTransform(
-		ray.ViewTransform(
-			ray.NewPoint(0, 7, 13), ray.NewPoint(0, 1, 0), ray.NewVec(0, 1, 0)))
-	return camera, err
+	return newCamera(nx, ny, ray.NewPoint(0, 7, 13), ray.NewPoint(0, 1, 0), math.Pi/3)
 }
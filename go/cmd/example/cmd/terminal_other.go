@@ -0,0 +1,9 @@
+//go:build windows || plan9 || js
+
+package cmd
+
+// terminalColumns can't tell how wide the terminal is here, the COLUMNS
+// environment variable is used instead.
+func terminalColumns() (int, bool) {
+	return 0, false
+}
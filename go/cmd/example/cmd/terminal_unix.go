@@ -0,0 +1,19 @@
+//go:build !windows && !plan9 && !js
+
+package cmd
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalColumns is how wide the terminal on stdout is, false when stdout
+// isn't one.
+func terminalColumns() (int, bool) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 {
+		return 0, false
+	}
+	return int(ws.Col), true
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/input"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+const (
+	// editors write a file in a few steps, wait for them all
+	watchDebounce = 100 * time.Millisecond
+)
+
+var (
+	previewWidth   int
+	previewSamples int
+	settle         time.Duration
+)
+
+func init() {
+	watchCmd.Flags().BoolVarP(&isJpeg, "jpeg", "j", false, "Switch output to jpeg")
+	watchCmd.Flags().BoolVar(&toTerminal, "terminal", false, "Draw the image in the terminal instead of writing a file, for terminals with 24-bit colour")
+	watchCmd.Flags().StringVarP(&renderFilename, "filename", "f", "", "Filename of the output, defaults to the scene's name")
+	addSceneFlags(watchCmd)
+	watchCmd.Flags().IntVar(&previewWidth, "preview-width", 160, "Image width in pixels of the preview")
+	watchCmd.Flags().IntVar(&previewSamples, "preview-samples", 1, "Number of samples per pixel of the preview")
+	watchCmd.Flags().DurationVar(&settle, "settle", 2*time.Second, "How long the scene has to stay unchanged after the preview for the full render")
+	rootCmd.AddCommand(watchCmd)
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <scene>",
+	Short: "Render a scene file again whenever it changes",
+	Long: `This renders a quick preview of a scene file whenever it or the models and environment map it loads change,
+and once they've stopped changing for --settle the full render, both to the same file for an image viewer to pick up.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		fname := renderFilename
+		if fname == "" {
+			fname = strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return watchScene(ctx, args[0], cmd.Flags().Changed, fname)
+	},
+}
+
+// sceneWatcher renders a scene file and everything it loads each time they
+// change.
+type sceneWatcher struct {
+	fname   string
+	changed func(flag string) bool
+	output  string
+	watcher *fsnotify.Watcher
+	// dirs are watched rather than files, editors often save by replacing
+	dirs  map[string]bool
+	files map[string]bool
+}
+
+func watchScene(ctx context.Context, fname string, changed func(flag string) bool, output string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = watcher.Close()
+	}()
+	w := &sceneWatcher{
+		fname:   fname,
+		changed: changed,
+		output:  output,
+		watcher: watcher,
+		dirs:    map[string]bool{},
+		files:   map[string]bool{},
+	}
+
+	// render straight away the first time
+	debounce := time.NewTimer(0)
+	stopRender := func() {}
+	for {
+		select {
+		case <-ctx.Done():
+			stopRender()
+			fmt.Println("Stopped watching")
+			return nil
+		case err := <-watcher.Errors:
+			fmt.Println(fmt.Sprintf("Watching: %v", err))
+		case e := <-watcher.Events:
+			if e.Op == fsnotify.Chmod || !w.files[filepath.Clean(e.Name)] {
+				continue
+			}
+			if !debounce.Stop() {
+				select {
+				case <-debounce.C:
+				default:
+				}
+			}
+			debounce.Reset(watchDebounce)
+		case <-debounce.C:
+			stopRender()
+			stopRender = w.start(ctx)
+		}
+	}
+}
+
+// start reads the scene, watching the files it loads, and renders it in the
+// background until stopped.
+func (w *sceneWatcher) start(ctx context.Context) (stop func()) {
+	sf, err := readSceneFile(w.fname, w.changed)
+	if err := w.watch(sf, err == nil); err != nil {
+		fmt.Println(fmt.Sprintf("Watching: %v", err))
+	}
+	if err != nil {
+		fmt.Println(fmt.Sprintf("%v, waiting for it to change", err))
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		preview := sf
+		if previewWidth > 0 && (sf.Width == 0 || previewWidth < sf.Width) {
+			setWidth(&preview, previewWidth)
+		}
+		preview.Render.Samples = previewSamples
+		if err := w.render(ctx, preview, "preview"); err != nil {
+			return
+		}
+
+		select {
+		case <-time.After(settle):
+		case <-ctx.Done():
+			return
+		}
+		_ = w.render(ctx, sf, "render")
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// watch adds the scene file and, when it could be read, the files it loads.
+func (w *sceneWatcher) watch(sf input.SceneFile, loaded bool) error {
+	files := []string{w.fname}
+	if loaded {
+		files = append(files, sf.Files(filepath.Dir(w.fname))...)
+	}
+	for _, f := range files {
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return err
+		}
+		w.files[abs] = true
+		// events name files the way their directory was added
+		w.files[filepath.Clean(f)] = true
+		dir := filepath.Dir(f)
+		if w.dirs[dir] {
+			continue
+		}
+		if err = w.watcher.Add(dir); err != nil {
+			return err
+		}
+		w.dirs[dir] = true
+	}
+	return nil
+}
+
+func (w *sceneWatcher) render(ctx context.Context, sf input.SceneFile, kind string) error {
+	start := time.Now()
+	s, err := sf.Build(filepath.Dir(w.fname))
+	if err != nil {
+		fmt.Println(fmt.Sprintf("%v, waiting for it to change", err))
+		return err
+	}
+	canvas, err := scene.RenderTiles(ctx, s.Camera, s.World, s.Settings, scene.WithWorkers(runtime.GOMAXPROCS(0)))
+	if errors.Is(err, context.Canceled) {
+		fmt.Println(fmt.Sprintf("Changed, stopped the %s", kind))
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	name, err := writeImage(canvas, w.output)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	fmt.Println(fmt.Sprintf("Wrote: %s %s %v,%v with samples: %v in %s",
+		kind, name, s.Camera.HSize(), s.Camera.VSize(), s.Settings.Samples, time.Since(start).Round(time.Millisecond)))
+	return nil
+}
@@ -0,0 +1,44 @@
+package animation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Easing shapes how a value moves from one key to the next, taking how far
+// between them in time, 0 to 1, to how far between their values.
+type Easing func(t float64) float64
+
+func Linear(t float64) float64 {
+	return t
+}
+
+// EaseIn starts slowly and speeds up.
+func EaseIn(t float64) float64 {
+	return t * t
+}
+
+// EaseOut starts quickly and slows down.
+func EaseOut(t float64) float64 {
+	return t * (2 - t)
+}
+
+// EaseInOut starts and ends slowly.
+func EaseInOut(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
+
+func ParseEasing(name string) (Easing, error) {
+	switch strings.ToLower(name) {
+	case "", "linear":
+		return Linear, nil
+	case "ease-in":
+		return EaseIn, nil
+	case "ease-out":
+		return EaseOut, nil
+	case "ease-in-out":
+		return EaseInOut, nil
+	default:
+		return nil, fmt.Errorf("unknown easing %q, expected linear, ease-in, ease-out or ease-in-out", name)
+	}
+}
@@ -0,0 +1,41 @@
+package animation_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/animation"
+)
+
+func TestParseEasing(t *testing.T) {
+	testCases := []struct {
+		name  string
+		start float64
+		half  float64
+	}{
+		{name: "", half: 0.5},
+		{name: "linear", half: 0.5},
+		{name: "ease-in", half: 0.25},
+		{name: "Ease-Out", half: 0.75},
+		{name: "ease-in-out", half: 0.5},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ease, err := animation.ParseEasing(tt.name)
+			require.NoError(t, err)
+			assert.Equal(t, 0.0, ease(0), "starts at the first key")
+			assert.Equal(t, 1.0, ease(1), "ends at the next")
+			assert.InDelta(t, tt.half, ease(0.5), 1e-9)
+		})
+	}
+
+	_, err := animation.ParseEasing("bounce")
+	assert.Error(t, err)
+}
+
+func TestEaseInOut(t *testing.T) {
+	assert.Less(t, animation.EaseInOut(0.1), 0.1, "slow to start")
+	assert.Greater(t, animation.EaseInOut(0.9), 0.9, "slow to stop")
+}
@@ -0,0 +1,60 @@
+package animation
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Key is some values at a frame, eased into from the key before.
+type Key struct {
+	Frame  float64
+	Values []float64
+	Ease   Easing
+}
+
+// Track moves values through its keys, in frame order.
+type Track []Key
+
+func NewTrack(keys ...Key) (Track, error) {
+	t := make(Track, len(keys))
+	copy(t, keys)
+	sort.SliceStable(t, func(i, j int) bool {
+		return t[i].Frame < t[j].Frame
+	})
+	for i := range t {
+		if len(t[i].Values) != len(t[0].Values) {
+			return nil, fmt.Errorf("key at frame %v has %v values, expected %v",
+				t[i].Frame, len(t[i].Values), len(t[0].Values))
+		}
+	}
+	return t, nil
+}
+
+// At is the values at frame, held at the first key before it and the last
+// after.
+func (t Track) At(frame float64) []float64 {
+	if len(t) == 0 {
+		return nil
+	}
+	i := sort.Search(len(t), func(i int) bool {
+		return t[i].Frame > frame
+	})
+	switch i {
+	case 0:
+		return t[0].Values
+	case len(t):
+		return t[i-1].Values
+	}
+
+	from, to := t[i-1], t[i]
+	ease := to.Ease
+	if ease == nil {
+		ease = Linear
+	}
+	s := ease((frame - from.Frame) / (to.Frame - from.Frame))
+	values := make([]float64, len(from.Values))
+	for j := range values {
+		values[j] = from.Values[j] + (to.Values[j]-from.Values[j])*s
+	}
+	return values
+}
@@ -0,0 +1,48 @@
+package animation_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/animation"
+)
+
+func TestTrack_At(t *testing.T) {
+	track, err := animation.NewTrack(
+		animation.Key{Frame: 10, Values: []float64{10, 0}, Ease: animation.EaseIn},
+		animation.Key{Frame: 0, Values: []float64{0, 0}},
+		animation.Key{Frame: 20, Values: []float64{10, 20}},
+	)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		frame    float64
+		expected []float64
+	}{
+		{name: "before the first key", frame: -5, expected: []float64{0, 0}},
+		{name: "on a key", frame: 10, expected: []float64{10, 0}},
+		{name: "eased between keys", frame: 5, expected: []float64{2.5, 0}},
+		{name: "linear between keys", frame: 15, expected: []float64{10, 10}},
+		{name: "after the last key", frame: 25, expected: []float64{10, 20}},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDeltaSlice(t, tt.expected, track.At(tt.frame), 1e-9)
+		})
+	}
+
+	t.Run("keys must have as many values", func(t *testing.T) {
+		_, err := animation.NewTrack(
+			animation.Key{Frame: 0, Values: []float64{0, 0, 0}},
+			animation.Key{Frame: 1, Values: []float64{1}},
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Nil(t, animation.Track(nil).At(1))
+	})
+}
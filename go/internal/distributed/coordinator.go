@@ -0,0 +1,284 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+const (
+	// DefaultLease is how long a worker has to send a tile back before it's
+	// given to another
+	DefaultLease = 2 * time.Minute
+)
+
+// Coordinator splits the image into tiles and hands them out to workers
+// over HTTP, putting the pixels they send back together. Tiles are leased,
+// so ones given to a worker that dies are handed out again.
+type Coordinator struct {
+	job      Job
+	lease    time.Duration
+	tileSize int
+	events   chan<- scene.TileEvent
+
+	mu       sync.Mutex
+	tiles    []scene.Tile
+	queue    []int
+	leased   map[int]time.Time
+	finished []bool
+	done     int
+	canvas   scene.Canvas
+	start    time.Time
+	complete chan struct{}
+	workers  map[string]*workerState
+	// told is signalled when a worker hears the image is finished
+	told chan struct{}
+}
+
+type workerState struct {
+	seen time.Time
+	told bool
+}
+
+type CoordinatorOption func(c *Coordinator)
+
+// WithLease gives workers lease to send a tile back, DefaultLease if not
+// set. It should be longer than the slowest tile takes.
+func WithLease(lease time.Duration) CoordinatorOption {
+	return func(c *Coordinator) {
+		c.lease = lease
+	}
+}
+
+func WithTileSize(size int) CoordinatorOption {
+	return func(c *Coordinator) {
+		c.tileSize = size
+	}
+}
+
+// WithTileEvents sends a scene.TileEvent to events as each tile comes back.
+// Workers don't wait for them to be received, events that don't fit in the
+// buffer are dropped, so it should be buffered and read promptly. They're
+// all sent before Done, so events can be closed then.
+func WithTileEvents(events chan<- scene.TileEvent) CoordinatorOption {
+	return func(c *Coordinator) {
+		c.events = events
+	}
+}
+
+func NewCoordinator(job Job, opts ...CoordinatorOption) *Coordinator {
+	c := &Coordinator{
+		job:      job,
+		lease:    DefaultLease,
+		tileSize: scene.DefaultTileSize,
+		leased:   map[int]time.Time{},
+		canvas:   scene.NewCanvas(job.Width, job.Height),
+		start:    time.Now(),
+		complete: make(chan struct{}),
+		workers:  map[string]*workerState{},
+		told:     make(chan struct{}, 1),
+	}
+	for i := range opts {
+		opts[i](c)
+	}
+	c.tiles = scene.Tiles(job.Width, job.Height, c.tileSize)
+	c.finished = make([]bool, len(c.tiles))
+	c.queue = make([]int, len(c.tiles))
+	for i := range c.queue {
+		c.queue[i] = i
+	}
+	if len(c.tiles) == 0 {
+		close(c.complete)
+	}
+	return c
+}
+
+// Wait returns the image once every tile's back.
+func (c *Coordinator) Wait(ctx context.Context) (scene.Canvas, error) {
+	select {
+	case <-c.complete:
+		return c.canvas, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Done is closed once every tile's back.
+func (c *Coordinator) Done() <-chan struct{} {
+	return c.complete
+}
+
+// Drain waits for the image to be finished and every worker to have been
+// told, so the coordinator can stop without workers trying to reach it.
+// Workers not heard from for longer than a lease are taken to have died.
+func (c *Coordinator) Drain(ctx context.Context) error {
+	select {
+	case <-c.complete:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	for {
+		wait := c.untilDrained()
+		if wait <= 0 {
+			return nil
+		}
+		select {
+		case <-c.told:
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// untilDrained is how long until the last worker that hasn't been told is
+// taken to have died.
+func (c *Coordinator) untilDrained() (wait time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for _, ws := range c.workers {
+		if ws.told {
+			continue
+		}
+		if left := ws.seen.Add(c.lease).Sub(now); left > wait {
+			wait = left
+		}
+	}
+	return wait
+}
+
+func (c *Coordinator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == jobPath && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(c.job)
+	case r.URL.Path == tilesPath && r.Method == http.MethodPost:
+		if !c.sameScene(w, r) {
+			return
+		}
+		c.next(w, r.Header.Get(workerHeader))
+	case strings.HasPrefix(r.URL.Path, tilesPath+"/") && r.Method == http.MethodPut:
+		if !c.sameScene(w, r) {
+			return
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, tilesPath+"/"))
+		if err != nil || id < 0 || id >= len(c.tiles) {
+			http.NotFound(w, r)
+			return
+		}
+		c.receive(w, r, id)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (c *Coordinator) sameScene(w http.ResponseWriter, r *http.Request) bool {
+	if r.Header.Get(hashHeader) != c.job.Hash {
+		http.Error(w, "the worker's scene isn't the one being rendered", http.StatusConflict)
+		return false
+	}
+	return true
+}
+
+// seen notes a worker's been heard from, and whether it's been told the
+// image is finished. c.mu must be held.
+func (c *Coordinator) seen(worker string, told bool) {
+	if worker == "" {
+		return
+	}
+	ws, ok := c.workers[worker]
+	if !ok {
+		ws = &workerState{}
+		c.workers[worker] = ws
+	}
+	ws.seen = time.Now()
+	if told && !ws.told {
+		ws.told = true
+		select {
+		case c.told <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// next leases the worker a tile. There's nothing to do while the last ones
+// are out with other workers, but one of them might die.
+func (c *Coordinator) next(w http.ResponseWriter, worker string) {
+	c.mu.Lock()
+	finished := c.done == len(c.tiles)
+	c.seen(worker, finished)
+	if finished {
+		c.mu.Unlock()
+		http.Error(w, "the image is finished", http.StatusGone)
+		return
+	}
+	now := time.Now()
+	for id, expires := range c.leased {
+		if now.After(expires) {
+			delete(c.leased, id)
+			c.queue = append(c.queue, id)
+		}
+	}
+	if len(c.queue) == 0 {
+		c.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	id := c.queue[0]
+	c.queue = c.queue[1:]
+	c.leased[id] = now.Add(c.lease)
+	c.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(Lease{ID: id, Tile: c.tiles[id]})
+}
+
+// receive puts a tile's pixels in the image. A tile that was leased again
+// is kept from whichever worker sends it first.
+func (c *Coordinator) receive(w http.ResponseWriter, r *http.Request, id int) {
+	t := c.tiles[id]
+	pixels, err := readPixels(r.Body, t.Width*t.Height)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading tile %v: %v", id, err), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen(r.Header.Get(workerHeader), false)
+	if c.finished[id] {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	for i := range pixels {
+		c.canvas[t.X+i%t.Width][t.Y+i/t.Width] = pixels[i]
+	}
+	c.finished[id] = true
+	delete(c.leased, id)
+	for i := range c.queue {
+		if c.queue[i] == id {
+			c.queue = append(c.queue[:i], c.queue[i+1:]...)
+			break
+		}
+	}
+	c.done++
+	if c.events != nil {
+		// without waiting, a slow reader would hold up every other worker
+		select {
+		case c.events <- scene.TileEvent{Tile: t, Done: c.done, Total: len(c.tiles), Elapsed: time.Since(c.start)}:
+		default:
+		}
+	}
+	if c.done == len(c.tiles) {
+		close(c.complete)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
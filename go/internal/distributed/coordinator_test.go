@@ -0,0 +1,232 @@
+package distributed_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/distributed"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+func request(t *testing.T, method, url, hash string, pixels []object.RGB) *http.Response {
+	return requestAs(t, "", method, url, hash, pixels)
+}
+
+// requestAs makes the request as the worker with id.
+func requestAs(t *testing.T, id, method, url, hash string, pixels []object.RGB) *http.Response {
+	var body bytes.Buffer
+	if pixels != nil {
+		require.NoError(t, binary.Write(&body, binary.LittleEndian, pixels))
+	}
+	req, err := http.NewRequest(method, url, &body)
+	require.NoError(t, err)
+	req.Header.Set("X-Scene-Hash", hash)
+	if id != "" {
+		req.Header.Set("X-Worker-ID", id)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = resp.Body.Close()
+	})
+	return resp
+}
+
+func lease(t *testing.T, url string) distributed.Lease {
+	resp := request(t, http.MethodPost, url+"/tiles", "scene", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var l distributed.Lease
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&l))
+	return l
+}
+
+func fill(t scene.Tile, c object.RGB) []object.RGB {
+	pixels := make([]object.RGB, t.Width*t.Height)
+	for i := range pixels {
+		pixels[i] = c
+	}
+	return pixels
+}
+
+func TestCoordinator(t *testing.T) {
+	events := make(chan scene.TileEvent, 10)
+	c := distributed.NewCoordinator(distributed.Job{Hash: "scene", Width: 6, Height: 3},
+		distributed.WithTileSize(4), distributed.WithTileEvents(events))
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	resp := request(t, http.MethodGet, srv.URL+"/job", "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var job distributed.Job
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+	assert.Equal(t, distributed.Job{Hash: "scene", Width: 6, Height: 3}, job)
+
+	resp = request(t, http.MethodPost, srv.URL+"/tiles", "another scene", nil)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "a worker with a different scene")
+
+	first, second := lease(t, srv.URL), lease(t, srv.URL)
+	assert.Equal(t, scene.Tile{X: 0, Y: 0, Width: 4, Height: 3}, first.Tile)
+	assert.Equal(t, scene.Tile{X: 4, Y: 0, Width: 2, Height: 3}, second.Tile)
+	resp = request(t, http.MethodPost, srv.URL+"/tiles", "scene", nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode, "all leased")
+
+	testCases := []struct {
+		name   string
+		path   string
+		pixels []object.RGB
+		want   int
+	}{
+		{
+			name:   "unknown tile",
+			path:   "/tiles/2",
+			pixels: fill(first.Tile, object.White),
+			want:   http.StatusNotFound,
+		},
+		{
+			name:   "too few pixels",
+			path:   fmt.Sprintf("/tiles/%v", first.ID),
+			pixels: fill(second.Tile, object.White),
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:   "too many pixels",
+			path:   fmt.Sprintf("/tiles/%v", second.ID),
+			pixels: fill(first.Tile, object.White),
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:   "far more pixels than the tile",
+			path:   fmt.Sprintf("/tiles/%v", second.ID),
+			pixels: make([]object.RGB, 1<<12),
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:   "first tile",
+			path:   fmt.Sprintf("/tiles/%v", first.ID),
+			pixels: fill(first.Tile, object.White),
+			want:   http.StatusNoContent,
+		},
+		{
+			name:   "first tile again",
+			path:   fmt.Sprintf("/tiles/%v", first.ID),
+			pixels: fill(first.Tile, object.Red),
+			want:   http.StatusNoContent,
+		},
+		{
+			name:   "second tile",
+			path:   fmt.Sprintf("/tiles/%v", second.ID),
+			pixels: fill(second.Tile, object.Green),
+			want:   http.StatusNoContent,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := request(t, http.MethodPut, srv.URL+tc.path, "scene", tc.pixels)
+			assert.Equal(t, tc.want, resp.StatusCode)
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	canvas, err := c.Wait(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, object.White, canvas[3][2], "kept the first pixels sent")
+	assert.Equal(t, object.Green, canvas[5][2])
+
+	resp = request(t, http.MethodPost, srv.URL+"/tiles", "scene", nil)
+	assert.Equal(t, http.StatusGone, resp.StatusCode, "finished")
+
+	close(events)
+	var done []int
+	for e := range events {
+		done = append(done, e.Done)
+		assert.Equal(t, 2, e.Total)
+	}
+	assert.Equal(t, []int{1, 2}, done)
+}
+
+func TestCoordinator_leaseExpires(t *testing.T) {
+	c := distributed.NewCoordinator(distributed.Job{Hash: "scene", Width: 4, Height: 4},
+		distributed.WithTileSize(4), distributed.WithLease(20*time.Millisecond))
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	first := lease(t, srv.URL)
+	resp := request(t, http.MethodPost, srv.URL+"/tiles", "scene", nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, first, lease(t, srv.URL), "handed out again")
+}
+
+func TestCoordinator_Wait(t *testing.T) {
+	c := distributed.NewCoordinator(distributed.Job{Hash: "scene", Width: 4, Height: 4})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.Wait(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCoordinator_Drain(t *testing.T) {
+	c := distributed.NewCoordinator(distributed.Job{Hash: "scene", Width: 4, Height: 4},
+		distributed.WithTileSize(4), distributed.WithLease(time.Second))
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	drain := func(timeout time.Duration) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return c.Drain(ctx)
+	}
+	assert.ErrorIs(t, drain(10*time.Millisecond), context.DeadlineExceeded, "not finished")
+
+	resp := requestAs(t, "a", http.MethodPost, srv.URL+"/tiles", "scene", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var l distributed.Lease
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&l))
+	resp = requestAs(t, "b", http.MethodPost, srv.URL+"/tiles", "scene", nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp = requestAs(t, "a", http.MethodPut, fmt.Sprintf("%s/tiles/%v", srv.URL, l.ID), "scene", fill(l.Tile, object.White))
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.ErrorIs(t, drain(10*time.Millisecond), context.DeadlineExceeded, "the workers haven't been told")
+
+	resp = requestAs(t, "a", http.MethodPost, srv.URL+"/tiles", "scene", nil)
+	require.Equal(t, http.StatusGone, resp.StatusCode)
+	assert.ErrorIs(t, drain(10*time.Millisecond), context.DeadlineExceeded, "b hasn't been told")
+
+	resp = requestAs(t, "b", http.MethodPost, srv.URL+"/tiles", "scene", nil)
+	require.Equal(t, http.StatusGone, resp.StatusCode)
+	assert.NoError(t, drain(10*time.Millisecond), "every worker's been told")
+}
+
+func TestCoordinator_Drain_silentWorker(t *testing.T) {
+	c := distributed.NewCoordinator(distributed.Job{Hash: "scene", Width: 4, Height: 4},
+		distributed.WithTileSize(4), distributed.WithLease(50*time.Millisecond))
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	// leased by a worker that died, then rendered by another
+	requestAs(t, "dead", http.MethodPost, srv.URL+"/tiles", "scene", nil)
+	time.Sleep(60 * time.Millisecond)
+	resp := requestAs(t, "alive", http.MethodPost, srv.URL+"/tiles", "scene", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var l distributed.Lease
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&l))
+	requestAs(t, "alive", http.MethodPut, fmt.Sprintf("%s/tiles/%v", srv.URL, l.ID), "scene", fill(l.Tile, object.White))
+	requestAs(t, "alive", http.MethodPost, srv.URL+"/tiles", "scene", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, c.Drain(ctx), "gives up on a worker not heard from for a lease")
+}
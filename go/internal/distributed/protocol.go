@@ -0,0 +1,60 @@
+// Package distributed renders an image across worker processes. A
+// Coordinator hands out the tiles over HTTP and each Worker, with the same
+// scene loaded, renders them and sends back the pixels.
+package distributed
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+const (
+	jobPath   = "/job"
+	tilesPath = "/tiles"
+	// hashHeader carries the worker's scene hash with every tile request
+	hashHeader = "X-Scene-Hash"
+	// workerHeader tells the coordinator which worker is asking, so it knows
+	// when they've all heard the image is finished
+	workerHeader = "X-Worker-ID"
+)
+
+// Job is the image the coordinator is putting together, workers check it's
+// the scene they've loaded.
+type Job struct {
+	Hash   string `json:"hash"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Lease is a tile handed to a worker. It's handed to another worker if its
+// pixels aren't back before the lease runs out.
+type Lease struct {
+	ID   int        `json:"id"`
+	Tile scene.Tile `json:"tile"`
+}
+
+func writePixels(w io.Writer, pixels []object.RGB) error {
+	return binary.Write(w, binary.LittleEndian, pixels)
+}
+
+// readPixels reads n pixels, failing if there are more without reading
+// any further than the first extra byte.
+func readPixels(r io.Reader, n int) ([]object.RGB, error) {
+	pixels := make([]object.RGB, n)
+	r = io.LimitReader(r, int64(binary.Size(pixels))+1)
+	if err := binary.Read(r, binary.LittleEndian, pixels); err != nil {
+		return nil, err
+	}
+	extra, err := io.Copy(io.Discard, r)
+	if err != nil {
+		return nil, err
+	}
+	if extra > 0 {
+		return nil, errors.New("more pixels than the tile has")
+	}
+	return pixels, nil
+}
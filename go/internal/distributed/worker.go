@@ -0,0 +1,262 @@
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+// ErrSceneMismatch is returned when the coordinator is rendering a
+// different scene, or the same one with different settings.
+var ErrSceneMismatch = errors.New("the coordinator is rendering a different scene")
+
+// Worker renders tiles for a coordinator until the image is finished.
+type Worker struct {
+	id       string
+	url      string
+	hash     string
+	camera   scene.Camera
+	world    scene.World
+	settings scene.RenderSettings
+
+	client      *http.Client
+	concurrency int
+	poll        time.Duration
+	retryFor    time.Duration
+}
+
+type WorkerOption func(w *Worker)
+
+// WithConcurrency renders that many tiles at once, one by default.
+func WithConcurrency(n int) WorkerOption {
+	return func(w *Worker) {
+		w.concurrency = n
+	}
+}
+
+func WithClient(client *http.Client) WorkerOption {
+	return func(w *Worker) {
+		w.client = client
+	}
+}
+
+// WithPoll is how often to ask for a tile while the last ones are out with
+// other workers, half a second by default.
+func WithPoll(interval time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.poll = interval
+	}
+}
+
+// WithRetry keeps trying to reach the coordinator for that long before
+// giving up, so workers can be started first, 30 seconds by default.
+func WithRetry(d time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.retryFor = d
+	}
+}
+
+// NewWorker renders tiles for the coordinator at url, the base of its
+// address like http://localhost:7878. The camera, world and settings must
+// be the scene hash describes, the coordinator turns it away otherwise.
+func NewWorker(url, hash string, c scene.Camera, world scene.World, settings scene.RenderSettings, opts ...WorkerOption) *Worker {
+	w := &Worker{
+		id:          newWorkerID(),
+		url:         strings.TrimSuffix(url, "/"),
+		hash:        hash,
+		camera:      c,
+		world:       world,
+		settings:    settings,
+		client:      http.DefaultClient,
+		concurrency: 1,
+		poll:        500 * time.Millisecond,
+		retryFor:    30 * time.Second,
+	}
+	for i := range opts {
+		opts[i](w)
+	}
+	if w.concurrency < 1 {
+		w.concurrency = 1
+	}
+	return w
+}
+
+// Work renders tiles until the coordinator says the image is finished, or
+// ctx is cancelled. The tiles it had are handed to other workers once their
+// leases run out.
+func (w *Worker) Work(ctx context.Context) error {
+	job, err := w.job(ctx)
+	if err != nil {
+		return err
+	}
+	if job.Hash != w.hash {
+		return ErrSceneMismatch
+	}
+	if job.Width != w.camera.HSize() || job.Height != w.camera.VSize() {
+		return fmt.Errorf("%w, its image is %vx%v", ErrSceneMismatch, job.Width, job.Height)
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, w.concurrency)
+	wg := sync.WaitGroup{}
+	wg.Add(w.concurrency)
+	for i := 0; i < w.concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			err := w.work(ctx)
+			// stopped by another goroutine finishing or failing
+			if err != nil && ctx.Err() != nil && parent.Err() == nil {
+				return
+			}
+			if err != nil {
+				errs <- err
+			}
+			// once one's told the image is finished the tiles the others
+			// are on have been done by another worker
+			cancel()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+func (w *Worker) work(ctx context.Context) error {
+	for {
+		lease, finished, err := w.next(ctx)
+		if err != nil || finished {
+			return err
+		}
+		if lease == nil {
+			select {
+			case <-time.After(w.poll):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		pixels, err := scene.RenderTile(ctx, w.camera, w.world, w.settings, lease.Tile)
+		if err != nil {
+			return err
+		}
+		if err = w.send(ctx, lease.ID, pixels); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *Worker) job(ctx context.Context) (job Job, err error) {
+	resp, err := w.do(ctx, http.MethodGet, jobPath, nil)
+	if err != nil {
+		return job, err
+	}
+	defer closeBody(resp)
+	if resp.StatusCode != http.StatusOK {
+		return job, responseError(resp)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&job)
+	return job, err
+}
+
+// next asks for a tile, nil if there isn't one free yet and finished once
+// the image is.
+func (w *Worker) next(ctx context.Context) (lease *Lease, finished bool, err error) {
+	resp, err := w.do(ctx, http.MethodPost, tilesPath, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer closeBody(resp)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		lease = &Lease{}
+		return lease, false, json.NewDecoder(resp.Body).Decode(lease)
+	case http.StatusNoContent:
+		return nil, false, nil
+	case http.StatusGone:
+		return nil, true, nil
+	default:
+		return nil, false, responseError(resp)
+	}
+}
+
+func (w *Worker) send(ctx context.Context, id int, pixels []object.RGB) error {
+	var buf bytes.Buffer
+	if err := writePixels(&buf, pixels); err != nil {
+		return err
+	}
+	resp, err := w.do(ctx, http.MethodPut, fmt.Sprintf("%s/%v", tilesPath, id), buf.Bytes())
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+	if resp.StatusCode != http.StatusNoContent {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// do makes the request, trying again every second while the coordinator
+// can't be reached.
+func (w *Worker) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	var giveUp time.Time
+	for {
+		req, err := http.NewRequestWithContext(ctx, method, w.url+path, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(hashHeader, w.hash)
+		req.Header.Set(workerHeader, w.id)
+		resp, err := w.client.Do(req)
+		if err == nil {
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if giveUp.IsZero() {
+			giveUp = time.Now().Add(w.retryFor)
+		} else if time.Now().After(giveUp) {
+			return nil, fmt.Errorf("can't reach the coordinator: %w", err)
+		}
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// newWorkerID tells workers apart, even ones started on different machines
+// at the same time.
+func newWorkerID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func responseError(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode == http.StatusConflict {
+		return ErrSceneMismatch
+	}
+	return fmt.Errorf("coordinator replied %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
+
+func closeBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,114 @@
+package distributed_test
+
+import (
+	"context"
+	"math"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/distributed"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+func workerTestScene(t *testing.T) (scene.World, scene.Camera) {
+	w, err := scene.DefaultWorld()
+	require.NoError(t, err)
+	c, err := scene.NewLookAtCamera(21, 11, ray.NewPoint(0, 0, -5), ray.NewPoint(0, 0, 0), ray.NewVec(0, 1, 0),
+		scene.WithFieldOfView(math.Pi/2))
+	require.NoError(t, err)
+	return w, c
+}
+
+func TestWorker_Work(t *testing.T) {
+	w, c := workerTestScene(t)
+	settings := scene.DefaultRenderSettings()
+	want := scene.RenderWith(c, w, settings)
+	job := distributed.Job{Hash: "scene", Width: c.HSize(), Height: c.VSize()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("several workers", func(t *testing.T) {
+		coordinator := distributed.NewCoordinator(job, distributed.WithTileSize(4))
+		srv := httptest.NewServer(coordinator)
+		defer srv.Close()
+
+		errs := make(chan error, 3)
+		for i := 0; i < 3; i++ {
+			go func() {
+				errs <- distributed.NewWorker(srv.URL, "scene", c, w, settings,
+					distributed.WithConcurrency(2), distributed.WithPoll(time.Millisecond)).Work(ctx)
+			}()
+		}
+		canvas, err := coordinator.Wait(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, canvas)
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, <-errs, "stops once the image is finished")
+		}
+		assert.NoError(t, coordinator.Drain(ctx), "every worker was told it's finished")
+	})
+
+	t.Run("events nobody reads", func(t *testing.T) {
+		events := make(chan scene.TileEvent)
+		coordinator := distributed.NewCoordinator(job, distributed.WithTileSize(4), distributed.WithTileEvents(events))
+		srv := httptest.NewServer(coordinator)
+		defer srv.Close()
+
+		errs := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				errs <- distributed.NewWorker(srv.URL, "scene", c, w, settings,
+					distributed.WithPoll(time.Millisecond)).Work(ctx)
+			}()
+		}
+		canvas, err := coordinator.Wait(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, canvas)
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, <-errs, "not held up by the events")
+		}
+	})
+
+	t.Run("a worker dies", func(t *testing.T) {
+		coordinator := distributed.NewCoordinator(job,
+			distributed.WithTileSize(8), distributed.WithLease(50*time.Millisecond))
+		srv := httptest.NewServer(coordinator)
+		defer srv.Close()
+
+		// leased but never sent back
+		lease(t, srv.URL)
+		err := distributed.NewWorker(srv.URL, "scene", c, w, settings,
+			distributed.WithPoll(time.Millisecond)).Work(ctx)
+		require.NoError(t, err)
+		canvas, err := coordinator.Wait(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, canvas)
+	})
+
+	t.Run("a different scene", func(t *testing.T) {
+		srv := httptest.NewServer(distributed.NewCoordinator(job))
+		defer srv.Close()
+		err := distributed.NewWorker(srv.URL, "another scene", c, w, settings).Work(ctx)
+		assert.ErrorIs(t, err, distributed.ErrSceneMismatch)
+	})
+
+	t.Run("a different size", func(t *testing.T) {
+		srv := httptest.NewServer(distributed.NewCoordinator(distributed.Job{Hash: "scene", Width: 10, Height: 10}))
+		defer srv.Close()
+		err := distributed.NewWorker(srv.URL, "scene", c, w, settings).Work(ctx)
+		assert.ErrorIs(t, err, distributed.ErrSceneMismatch)
+	})
+
+	t.Run("no coordinator", func(t *testing.T) {
+		srv := httptest.NewServer(distributed.NewCoordinator(job))
+		srv.Close()
+		err := distributed.NewWorker(srv.URL, "scene", c, w, settings, distributed.WithRetry(0)).Work(ctx)
+		assert.Error(t, err)
+	})
+}
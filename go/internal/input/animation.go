@@ -0,0 +1,251 @@
+package input
+
+import (
+	"fmt"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/animation"
+)
+
+// FrameRange is the frames of an animation, both included.
+type FrameRange struct {
+	Start int `yaml:"start"`
+	End   int `yaml:"end"`
+}
+
+// Key is a value at a frame, eased into from the key before with linear,
+// ease-in, ease-out or ease-in-out.
+type Key struct {
+	Frame float64   `yaml:"frame"`
+	Value []float64 `yaml:"value"`
+	Ease  string    `yaml:"ease"`
+}
+
+// TransformKey is an object's transform at a frame. Every key needs the
+// same steps, their numbers move between keys so rotations can go all the
+// way round.
+type TransformKey struct {
+	Frame     float64     `yaml:"frame"`
+	Transform []Transform `yaml:"transform"`
+	Ease      string      `yaml:"ease"`
+}
+
+type CameraKeys struct {
+	From        []Key `yaml:"from"`
+	To          []Key `yaml:"to"`
+	FieldOfView []Key `yaml:"fov"`
+}
+
+type LightKeys struct {
+	Position  []Key `yaml:"position"`
+	Intensity []Key `yaml:"intensity"`
+}
+
+type MaterialKeys struct {
+	Color []Key `yaml:"color"`
+}
+
+// At is the scene as it is at frame, with everything animated moved to
+// where its keys put it.
+func (sf SceneFile) At(frame float64) (at SceneFile, err error) {
+	at = sf
+	if sf.Camera.Animate != nil {
+		if at.Camera, err = sf.Camera.at(frame); err != nil {
+			return at, fmt.Errorf("camera: %w", err)
+		}
+	}
+	if sf.Light != nil && sf.Light.Animate != nil {
+		light, err := sf.Light.at(frame)
+		if err != nil {
+			return at, fmt.Errorf("light: %w", err)
+		}
+		at.Light = &light
+	}
+	if sf.Materials != nil {
+		at.Materials = make(map[string]MaterialSpec, len(sf.Materials))
+		for name, m := range sf.Materials {
+			if at.Materials[name], err = m.at(frame); err != nil {
+				return at, fmt.Errorf("material %q: %w", name, err)
+			}
+		}
+	}
+	if at.Objects, err = objectsAt(sf.Objects, frame); err != nil {
+		return at, err
+	}
+	return at, nil
+}
+
+// Animation is a scene file built once, with what's animated moved for
+// each frame so models aren't loaded again.
+type Animation struct {
+	file  SceneFile
+	scene Scene
+	objs  []builtObject
+}
+
+// NewAnimation builds the scene, files it refers to are relative to dir.
+func NewAnimation(sf SceneFile, dir string) (a *Animation, err error) {
+	a = &Animation{file: sf}
+	if a.scene, a.objs, err = sf.build(dir); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Frame is the scene at frame. It shares its world with the other frames,
+// so render one frame before asking for the next.
+func (a *Animation) Frame(frame float64) (s Scene, err error) {
+	at, err := a.file.At(frame)
+	if err != nil {
+		return s, err
+	}
+	s = a.scene
+	if s.Camera, err = at.Camera.Build(at.Size()); err != nil {
+		return s, err
+	}
+	if at.Light != nil {
+		light, err := at.Light.Build()
+		if err != nil {
+			return s, err
+		}
+		s.World.AddLight(light)
+	}
+	for i := range at.Objects {
+		if err = at.Objects[i].replace(a.objs[i], at.Materials); err != nil {
+			return s, fmt.Errorf("object %v: %w", i+1, err)
+		}
+	}
+	return s, nil
+}
+
+func (c CameraSpec) at(frame float64) (CameraSpec, error) {
+	var err error
+	if c.From, err = valueAt(c.Animate.From, c.From, frame); err != nil {
+		return c, fmt.Errorf("from: %w", err)
+	}
+	if c.To, err = valueAt(c.Animate.To, c.To, frame); err != nil {
+		return c, fmt.Errorf("to: %w", err)
+	}
+	fov, err := valueAt(c.Animate.FieldOfView, []float64{c.FieldOfView}, frame)
+	if err != nil {
+		return c, fmt.Errorf("fov: %w", err)
+	}
+	if len(fov) != 1 {
+		return c, fmt.Errorf("fov takes 1 number, got %v", len(fov))
+	}
+	c.FieldOfView = fov[0]
+	return c, nil
+}
+
+func (l LightSpec) at(frame float64) (LightSpec, error) {
+	var err error
+	if l.Position, err = valueAt(l.Animate.Position, l.Position, frame); err != nil {
+		return l, fmt.Errorf("position: %w", err)
+	}
+	if l.Intensity, err = valueAt(l.Animate.Intensity, l.Intensity, frame); err != nil {
+		return l, fmt.Errorf("intensity: %w", err)
+	}
+	return l, nil
+}
+
+func (m MaterialSpec) at(frame float64) (MaterialSpec, error) {
+	if m.Animate == nil {
+		return m, nil
+	}
+	var err error
+	if m.Color, err = valueAt(m.Animate.Color, m.Color, frame); err != nil {
+		return m, fmt.Errorf("color: %w", err)
+	}
+	return m, nil
+}
+
+func objectsAt(objects []ObjectSpec, frame float64) ([]ObjectSpec, error) {
+	if objects == nil {
+		return nil, nil
+	}
+	at := make([]ObjectSpec, len(objects))
+	for i, o := range objects {
+		if o.Material.Spec != nil {
+			spec, err := o.Material.Spec.at(frame)
+			if err != nil {
+				return nil, fmt.Errorf("object %v material: %w", i+1, err)
+			}
+			o.Material.Spec = &spec
+		}
+		if len(o.Animate) > 0 {
+			t, err := transformAt(o.Animate, frame)
+			if err != nil {
+				return nil, fmt.Errorf("object %v transform: %w", i+1, err)
+			}
+			o.Transform = t
+		}
+		children, err := objectsAt(o.Children, frame)
+		if err != nil {
+			return nil, fmt.Errorf("object %v %w", i+1, err)
+		}
+		o.Children = children
+		at[i] = o
+	}
+	return at, nil
+}
+
+// valueAt is where the keys are at frame, or value when there aren't any.
+func valueAt(keys []Key, value []float64, frame float64) ([]float64, error) {
+	if len(keys) == 0 {
+		return value, nil
+	}
+	track := make([]animation.Key, len(keys))
+	for i := range keys {
+		ease, err := animation.ParseEasing(keys[i].Ease)
+		if err != nil {
+			return nil, err
+		}
+		track[i] = animation.Key{Frame: keys[i].Frame, Values: keys[i].Value, Ease: ease}
+	}
+	t, err := animation.NewTrack(track...)
+	if err != nil {
+		return nil, err
+	}
+	return t.At(frame), nil
+}
+
+// transformAt moves the numbers in each step of the transforms between the
+// keys, keeping the steps' names.
+func transformAt(keys []TransformKey, frame float64) ([]Transform, error) {
+	first := keys[0].Transform
+	values := make([]Key, len(keys))
+	for i := range keys {
+		if len(keys[i].Transform) != len(first) {
+			return nil, fmt.Errorf("key at frame %v has %v steps, expected %v",
+				keys[i].Frame, len(keys[i].Transform), len(first))
+		}
+		values[i] = Key{Frame: keys[i].Frame, Ease: keys[i].Ease}
+		for j, step := range keys[i].Transform {
+			if len(step) == 0 || len(step) != len(first[j]) || step[0] != first[j][0] {
+				return nil, fmt.Errorf("key at frame %v step %v is %v, expected %v",
+					keys[i].Frame, j+1, step, first[j])
+			}
+			for _, v := range step[1:] {
+				n, err := number(step[0], v)
+				if err != nil {
+					return nil, err
+				}
+				values[i].Value = append(values[i].Value, n)
+			}
+		}
+	}
+
+	numbers, err := valueAt(values, nil, frame)
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]Transform, len(first))
+	for j := range first {
+		step := Transform{first[j][0]}
+		for range first[j][1:] {
+			step = append(step, numbers[0])
+			numbers = numbers[1:]
+		}
+		steps[j] = step
+	}
+	return steps, nil
+}
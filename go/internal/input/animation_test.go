@@ -0,0 +1,171 @@
+package input_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/input"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray/raytest"
+)
+
+const animatedYAML = `
+frames:
+  start: 1
+  end: 21
+camera:
+  from: [0, 0, -5]
+  animate:
+    to:
+      - {frame: 1, value: [0, 0, 0]}
+      - {frame: 21, value: [0, 10, 0], ease: ease-in}
+    fov:
+      - {frame: 1, value: [60]}
+      - {frame: 21, value: [90]}
+light:
+  position: [0, 10, 0]
+  animate:
+    position:
+      - {frame: 1, value: [0, 10, 0]}
+      - {frame: 11, value: [10, 10, 0]}
+materials:
+  fading:
+    color: [1, 1, 1]
+    animate:
+      color:
+        - {frame: 1, value: [1, 1, 1]}
+        - {frame: 21, value: [0, 0, 0]}
+objects:
+  - type: group
+    material: fading
+    children:
+      - type: sphere
+        animate:
+          - frame: 1
+            transform:
+              - [rotate-y, 0]
+              - [translate, 0, 1, 0]
+          - frame: 21
+            transform:
+              - [rotate-y, 360]
+              - [translate, 0, 3, 0]
+            ease: ease-in-out
+`
+
+func TestSceneFile_At(t *testing.T) {
+	sf, err := input.ParseSceneFile(strings.NewReader(animatedYAML))
+	require.NoError(t, err)
+	require.NotNil(t, sf.Frames)
+	assert.Equal(t, input.FrameRange{Start: 1, End: 21}, *sf.Frames)
+
+	t.Run("half way", func(t *testing.T) {
+		at, err := sf.At(11)
+		require.NoError(t, err)
+		assert.Equal(t, []float64{0, 0, -5}, at.Camera.From, "not animated")
+		assert.InDeltaSlice(t, []float64{0, 2.5, 0}, at.Camera.To, 1e-9)
+		assert.InDelta(t, 75, at.Camera.FieldOfView, 1e-9)
+		assert.InDeltaSlice(t, []float64{10, 10, 0}, at.Light.Position, 1e-9)
+		assert.InDeltaSlice(t, []float64{0.5, 0.5, 0.5}, at.Materials["fading"].Color, 1e-9)
+		assert.Equal(t, input.Transform{"rotate-y", 180.0}, at.Objects[0].Children[0].Transform[0])
+		assert.Equal(t, input.Transform{"translate", 0.0, 2.0, 0.0}, at.Objects[0].Children[0].Transform[1])
+
+		s, err := at.Build(".")
+		require.NoError(t, err)
+		assert.Equal(t, object.NewColor(0.5, 0.5, 0.5), s.World.Objects()[0].Material().Color)
+		raytest.AssertMatrixEqual(t, ray.Translation(0, 2, 0).Multiply(ray.Rotation(ray.Y, math.Pi)),
+			s.World.Objects()[0].(*object.Group).Children[0].Transform())
+	})
+
+	t.Run("leaves the scene alone", func(t *testing.T) {
+		_, err := sf.At(21)
+		require.NoError(t, err)
+		assert.Equal(t, []float64{1, 1, 1}, sf.Materials["fading"].Color)
+		assert.Nil(t, sf.Objects[0].Children[0].Transform)
+		assert.Nil(t, sf.Camera.To)
+	})
+
+	t.Run("held after the last key", func(t *testing.T) {
+		at, err := sf.At(30)
+		require.NoError(t, err)
+		assert.InDeltaSlice(t, []float64{0, 10, 0}, at.Camera.To, 1e-9)
+		assert.InDeltaSlice(t, []float64{0, 0, 0}, at.Materials["fading"].Color, 1e-9)
+	})
+}
+
+func TestSceneFile_At_errors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "unknown easing",
+			content: "camera:\n  animate:\n    to:\n      - {frame: 1, value: [0, 0, 0], ease: bounce}\n",
+			err:     `unknown easing "bounce"`,
+		},
+		{
+			name:    "values don't match",
+			content: "light:\n  animate:\n    position:\n      - {frame: 1, value: [0, 0, 0]}\n      - {frame: 2, value: [0, 0]}\n",
+			err:     "key at frame 2 has 2 values, expected 3",
+		},
+		{
+			name: "steps don't match",
+			content: `objects:
+  - type: sphere
+    animate:
+      - frame: 1
+        transform: [[rotate-y, 0]]
+      - frame: 2
+        transform: [[rotate-x, 90]]
+`,
+			err: "key at frame 2 step 1 is [rotate-x 90], expected [rotate-y 0]",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			sf, err := input.ParseSceneFile(strings.NewReader(tt.content))
+			require.NoError(t, err)
+			_, err = sf.At(1)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestAnimation_Frame(t *testing.T) {
+	sf, err := input.ParseSceneFile(strings.NewReader(animatedYAML + `
+  - type: model
+    file: ../../../test/models/utah-teapot-low.obj
+    animate:
+      - frame: 1
+        transform: [[translate, 0, 0, 0]]
+      - frame: 21
+        transform: [[translate, 20, 0, 0]]
+`))
+	require.NoError(t, err)
+	a, err := input.NewAnimation(sf, ".")
+	require.NoError(t, err)
+
+	first, err := a.Frame(1)
+	require.NoError(t, err)
+	objs := first.World.Objects()
+	require.Len(t, objs, 2)
+	model := objs[1]
+	raytest.AssertMatrixEqual(t, ray.DefaultIdentityMatrix(), model.Transform())
+
+	s, err := a.Frame(11)
+	require.NoError(t, err)
+	require.Len(t, s.World.Objects(), 2)
+	assert.Same(t, model, s.World.Objects()[1], "the model is only loaded once")
+	raytest.AssertMatrixEqual(t, ray.Translation(10, 0, 0), model.Transform())
+	assert.Equal(t, object.NewColor(0.5, 0.5, 0.5), s.World.Objects()[0].Material().Color)
+	raytest.AssertMatrixEqual(t, ray.Translation(0, 2, 0).Multiply(ray.Rotation(ray.Y, math.Pi)),
+		s.World.Objects()[0].(*object.Group).Children[0].Transform())
+	assert.Equal(t, ray.NewPoint(10, 10, 0), s.World.Light().Position)
+	assert.InDelta(t, 75*math.Pi/180, s.Camera.FieldOfView(), 1e-9)
+}
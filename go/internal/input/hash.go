@@ -0,0 +1,93 @@
+package input
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrOutsideDir is returned for a scene that loads a file from outside the
+// directory it's built from.
+var ErrOutsideDir = errors.New("file is outside the scene's directory")
+
+// Hash identifies the scene and how it's rendered, so a checkpoint is only
+// resumed with the same. The files it loads are part of it, but not the
+// number of samples, so a render can be resumed to take more.
+func (sf SceneFile) Hash(dir string) (string, error) {
+	sf.Render.Samples = 0
+	sf.Frames = nil
+	b, err := yaml.Marshal(sf)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	_, _ = h.Write(b)
+
+	files := sf.Files(dir)
+	for i := range files {
+		if err = hashFile(h, files[i]); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Files are the files the scene loads, relative to dir like Build has them,
+// in the order it loads them.
+func (sf SceneFile) Files(dir string) (files []string) {
+	names := sf.fileNames()
+	for i := range names {
+		files = append(files, resolve(dir, names[i]))
+	}
+	return files
+}
+
+// Within checks the files the scene loads are all in the directory it's
+// built from, for scenes from somewhere that shouldn't read any others.
+func (sf SceneFile) Within() error {
+	for _, name := range sf.fileNames() {
+		clean := filepath.Clean(name)
+		if filepath.IsAbs(clean) || filepath.VolumeName(clean) != "" ||
+			clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%w: %s", ErrOutsideDir, name)
+		}
+	}
+	return nil
+}
+
+// fileNames are the files the scene loads as it names them.
+func (sf SceneFile) fileNames() (names []string) {
+	if sf.Environment != "" {
+		names = append(names, sf.Environment)
+	}
+	var walk func(objects []ObjectSpec)
+	walk = func(objects []ObjectSpec) {
+		for i := range objects {
+			if objects[i].File != "" {
+				names = append(names, objects[i].File)
+			}
+			walk(objects[i].Children)
+		}
+	}
+	walk(sf.Objects)
+	return names
+}
+
+func hashFile(w io.Writer, fname string) error {
+	f, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	_, err = io.Copy(w, f)
+	return err
+}
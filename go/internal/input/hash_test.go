@@ -0,0 +1,153 @@
+package input_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/input"
+)
+
+func TestSceneFile_Hash(t *testing.T) {
+	dir := t.TempDir()
+	model := filepath.Join(dir, "model.obj")
+	require.NoError(t, os.WriteFile(model, []byte("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n"), 0o644))
+
+	parse := func(t *testing.T) input.SceneFile {
+		sf, err := input.ParseSceneFile(strings.NewReader(`
+width: 80
+height: 40
+camera:
+  from: [0, 1, -5]
+  to: [0, 1, 0]
+render:
+  samples: 4
+  integrator: path
+objects:
+  - type: sphere
+    material:
+      color: [1, 0, 0]
+  - type: group
+    children:
+      - type: model
+        file: model.obj
+`))
+		require.NoError(t, err)
+		return sf
+	}
+	want, err := parse(t).Hash(dir)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		change func(sf *input.SceneFile)
+		same   bool
+	}{
+		{
+			name:   "unchanged",
+			change: func(sf *input.SceneFile) {},
+			same:   true,
+		},
+		{
+			name:   "more samples",
+			change: func(sf *input.SceneFile) { sf.Render.Samples = 64 },
+			same:   true,
+		},
+		{
+			name:   "width",
+			change: func(sf *input.SceneFile) { sf.Width = 160 },
+		},
+		{
+			name:   "integrator",
+			change: func(sf *input.SceneFile) { sf.Render.Integrator = "whitted" },
+		},
+		{
+			name:   "camera",
+			change: func(sf *input.SceneFile) { sf.Camera.From = []float64{0, 2, -5} },
+		},
+		{
+			name: "model file",
+			change: func(sf *input.SceneFile) {
+				require.NoError(t, os.WriteFile(model, []byte("v 0 0 0\nv 2 0 0\nv 0 1 0\nf 1 2 3\n"), 0o644))
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sf := parse(t)
+			tc.change(&sf)
+			got, err := sf.Hash(dir)
+			require.NoError(t, err)
+			if tc.same {
+				assert.Equal(t, want, got)
+			} else {
+				assert.NotEqual(t, want, got)
+			}
+		})
+	}
+}
+
+func TestSceneFile_Hash_missingFile(t *testing.T) {
+	sf, err := input.ParseSceneFile(strings.NewReader(`
+objects:
+  - type: model
+    file: missing.obj
+`))
+	require.NoError(t, err)
+	_, err = sf.Hash(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestSceneFile_Files(t *testing.T) {
+	sf, err := input.ParseSceneFile(strings.NewReader(`
+environment: sky.hdr
+objects:
+  - type: model
+    file: teapot.obj
+  - type: group
+    children:
+      - type: model
+        file: /models/bunny.obj
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join("scenes", "sky.hdr"),
+		filepath.Join("scenes", "teapot.obj"),
+		"/models/bunny.obj",
+	}, sf.Files("scenes"))
+}
+
+func TestSceneFile_Within(t *testing.T) {
+	testCases := []struct {
+		name    string
+		file    string
+		wantErr bool
+	}{
+		{name: "in the directory", file: "teapot.obj"},
+		{name: "in a subdirectory", file: "models/teapot.obj"},
+		{name: "back down into it", file: "models/../teapot.obj"},
+		{name: "absolute", file: "/etc/passwd", wantErr: true},
+		{name: "the parent", file: "..", wantErr: true},
+		{name: "out of it", file: "../teapot.obj", wantErr: true},
+		{name: "out of it part way", file: "models/../../teapot.obj", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, sf := range []input.SceneFile{
+				{Environment: tc.file},
+				{Objects: []input.ObjectSpec{{Type: "group", Children: []input.ObjectSpec{{Type: "model", File: tc.file}}}}},
+			} {
+				err := sf.Within()
+				if tc.wantErr {
+					assert.ErrorIs(t, err, input.ErrOutsideDir)
+				} else {
+					assert.NoError(t, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package input
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+const (
+	// hdrMaxSide and hdrMaxPixels keep a broken or hostile file from making
+	// a canvas too big to allocate, they allow 8k panoramas
+	hdrMaxSide   = 1 << 15
+	hdrMaxPixels = 1 << 25
+)
+
+var (
+	ErrNotHDR            = errors.New("not a radiance hdr file")
+	ErrUnsupportedFormat = errors.New("unsupported hdr format")
+	ErrImageSize         = errors.New("hdr image size out of range")
+)
+
+// NewHDRInput decodes a Radiance RGBE (.hdr) image, keeping the values above
+// one that an 8 bit image would lose.
+func NewHDRInput(r io.Reader) (c scene.Canvas, err error) {
+	br := bufio.NewReader(r)
+	magic, err := br.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(magic, "#?") {
+		return nil, ErrNotHDR
+	}
+
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "FORMAT=") && line != "FORMAT=32-bit_rle_rgbe" {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, line)
+		}
+	}
+
+	resolution, err := br.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	var width, height int
+	if _, err = fmt.Sscanf(resolution, "-Y %d +X %d", &height, &width); err != nil {
+		return nil, fmt.Errorf("%w: resolution %q", ErrUnsupportedFormat, strings.TrimSpace(resolution))
+	}
+	if width < 1 || height < 1 || width > hdrMaxSide || height > hdrMaxSide || width*height > hdrMaxPixels {
+		return nil, fmt.Errorf("%w: %vx%v", ErrImageSize, width, height)
+	}
+
+	c = scene.NewCanvas(width, height)
+	scanline := make([]byte, width*4)
+	for y := 0; y < height; y++ {
+		if err = readScanline(br, scanline, width); err != nil {
+			return nil, err
+		}
+		for x := 0; x < width; x++ {
+			c[x][y] = rgbe(scanline[x*4 : x*4+4])
+		}
+	}
+	return c, nil
+}
+
+func readScanline(br *bufio.Reader, scanline []byte, width int) error {
+	header, err := br.Peek(4)
+	if err != nil {
+		return err
+	}
+	if width < 8 || width > 0x7fff || header[0] != 2 || header[1] != 2 || header[2]&0x80 != 0 {
+		// flat, uncompressed pixels
+		_, err = io.ReadFull(br, scanline)
+		return err
+	}
+	if _, err = br.Discard(4); err != nil {
+		return err
+	}
+	if int(header[2])<<8|int(header[3]) != width {
+		return fmt.Errorf("%w: scanline width mismatch", ErrUnsupportedFormat)
+	}
+
+	// each channel is run length encoded separately
+	for channel := 0; channel < 4; channel++ {
+		for x := 0; x < width; {
+			count, err := br.ReadByte()
+			if err != nil {
+				return err
+			}
+			if count > 128 {
+				run := int(count) - 128
+				value, err := br.ReadByte()
+				if err != nil {
+					return err
+				}
+				if x+run > width {
+					return fmt.Errorf("%w: run past end of scanline", ErrUnsupportedFormat)
+				}
+				for ; run > 0; run-- {
+					scanline[x*4+channel] = value
+					x++
+				}
+				continue
+			}
+			if count == 0 || x+int(count) > width {
+				return fmt.Errorf("%w: bad scanline count", ErrUnsupportedFormat)
+			}
+			for ; count > 0; count-- {
+				value, err := br.ReadByte()
+				if err != nil {
+					return err
+				}
+				scanline[x*4+channel] = value
+				x++
+			}
+		}
+	}
+	return nil
+}
+
+func rgbe(pixel []byte) object.RGB {
+	if pixel[3] == 0 {
+		return object.Black
+	}
+	f := math.Ldexp(1, int(pixel[3])-(128+8))
+	return object.NewColor(
+		float64(pixel[0])*f,
+		float64(pixel[1])*f,
+		float64(pixel[2])*f,
+	)
+}
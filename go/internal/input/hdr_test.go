@@ -0,0 +1,90 @@
+package input_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/input"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+)
+
+const hdrHeader = "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n"
+
+func TestNewHDRInput(t *testing.T) {
+	t.Run("Flat pixels", func(t *testing.T) {
+		var b bytes.Buffer
+		b.WriteString(hdrHeader)
+		b.WriteString("-Y 2 +X 2\n")
+		b.Write([]byte{
+			128, 0, 0, 129, // 1, 0, 0
+			0, 128, 0, 131, // 0, 4, 0
+			0, 0, 128, 128, // 0, 0, 0.5
+			0, 0, 0, 0, // black
+		})
+		c, err := input.NewHDRInput(&b)
+		require.NoError(t, err)
+		require.Len(t, c, 2)
+		require.Len(t, c[0], 2)
+		assert.Equal(t, object.NewColor(1, 0, 0), c[0][0])
+		assert.Equal(t, object.NewColor(0, 4, 0), c[1][0])
+		assert.Equal(t, object.NewColor(0, 0, 0.5), c[0][1])
+		assert.Equal(t, object.Black, c[1][1])
+	})
+
+	t.Run("Run length encoded scanlines", func(t *testing.T) {
+		var b bytes.Buffer
+		b.WriteString(hdrHeader)
+		b.WriteString("-Y 1 +X 8\n")
+		b.Write([]byte{2, 2, 0, 8})
+		b.Write([]byte{128 + 8, 128})                      // red is a run of 128
+		b.Write([]byte{8, 0, 0, 0, 0, 128, 128, 128, 128}) // green is literal
+		b.Write([]byte{128 + 4, 0, 128 + 4, 64})           // blue is two runs
+		b.Write([]byte{128 + 8, 129})                      // exponent for 1
+		c, err := input.NewHDRInput(&b)
+		require.NoError(t, err)
+		require.Len(t, c, 8)
+		assert.Equal(t, object.NewColor(1, 0, 0), c[0][0])
+		assert.Equal(t, object.NewColor(1, 0, 0), c[3][0])
+		assert.Equal(t, object.NewColor(1, 1, 0.5), c[4][0])
+		assert.Equal(t, object.NewColor(1, 1, 0.5), c[7][0])
+	})
+
+	t.Run("Not an HDR file", func(t *testing.T) {
+		_, err := input.NewHDRInput(strings.NewReader("P3\n1 1\n255\n"))
+		assert.ErrorIs(t, err, input.ErrNotHDR)
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		_, err := input.NewHDRInput(strings.NewReader("#?RADIANCE\nFORMAT=32-bit_rle_xyze\n\n-Y 1 +X 1\n"))
+		assert.ErrorIs(t, err, input.ErrUnsupportedFormat)
+	})
+
+	t.Run("Malformed resolution", func(t *testing.T) {
+		testCases := []struct {
+			name       string
+			resolution string
+			want       error
+		}{
+			{name: "zero width", resolution: "-Y 2 +X 0", want: input.ErrImageSize},
+			{name: "negative height", resolution: "-Y -2 +X 2", want: input.ErrImageSize},
+			{name: "huge side", resolution: "-Y 1 +X 100000000", want: input.ErrImageSize},
+			{name: "too many pixels", resolution: "-Y 30000 +X 30000", want: input.ErrImageSize},
+			{name: "not numbers", resolution: "-Y a +X b", want: input.ErrUnsupportedFormat},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := input.NewHDRInput(strings.NewReader(hdrHeader + tc.resolution + "\n"))
+				assert.ErrorIs(t, err, tc.want)
+			})
+		}
+	})
+
+	t.Run("Truncated pixels", func(t *testing.T) {
+		_, err := input.NewHDRInput(strings.NewReader(hdrHeader + "-Y 2 +X 2\n\x80\x00"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,33 @@
+package input
+
+import (
+	"bufio"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+// LoadImage reads a Radiance .hdr, png or jpeg file into a canvas.
+func LoadImage(fname string) (c scene.Canvas, err error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if strings.EqualFold(filepath.Ext(fname), ".hdr") {
+		return NewHDRInput(bufio.NewReader(f))
+	}
+	img, _, err := image.Decode(bufio.NewReader(f))
+	if err != nil {
+		return nil, err
+	}
+	return scene.CanvasFromImage(img), nil
+}
@@ -0,0 +1,693 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+const (
+	defaultSceneWidth     = 640
+	defaultSceneHeight    = 320
+	defaultSceneFOV       = 60
+	defaultSceneViewWidth = 10
+)
+
+// SceneFile is a scene described in YAML, or JSON as it's valid YAML too.
+// Angles are in degrees and colours are [r, g, b] lists.
+type SceneFile struct {
+	Width              int                     `yaml:"width"`
+	Height             int                     `yaml:"height"`
+	Camera             CameraSpec              `yaml:"camera"`
+	Render             RenderSpec              `yaml:"render"`
+	Environment        string                  `yaml:"environment"`
+	EnvironmentSamples int                     `yaml:"environment-samples"`
+	Background         []float64               `yaml:"background"`
+	Light              *LightSpec              `yaml:"light"`
+	Materials          map[string]MaterialSpec `yaml:"materials"`
+	Objects            []ObjectSpec            `yaml:"objects"`
+	// TransparentShadows lets light through transparent objects, tinted by
+	// their colour
+	TransparentShadows bool `yaml:"transparent-shadows"`
+	// Frames are the frames to render when animated
+	Frames *FrameRange `yaml:"frames"`
+}
+
+type CameraSpec struct {
+	// Projection is perspective, orthographic, fisheye or equirectangular
+	Projection string `yaml:"projection"`
+	// FieldOfView is in degrees across the longer side of the image,
+	// HorizontalFOV and VerticalFOV across its width or height instead
+	FieldOfView   float64 `yaml:"fov"`
+	HorizontalFOV float64 `yaml:"horizontal-fov"`
+	VerticalFOV   float64 `yaml:"vertical-fov"`
+	// FocalLength in mm sets the field of view for a Sensor's width and
+	// height in mm, full frame when there isn't one
+	FocalLength float64   `yaml:"focal-length"`
+	Sensor      []float64 `yaml:"sensor"`
+	// ViewWidth is how many world units an orthographic camera sees across
+	ViewWidth float64   `yaml:"view-width"`
+	From      []float64 `yaml:"from"`
+	To        []float64 `yaml:"to"`
+	Up        []float64 `yaml:"up"`
+	// Roll is in degrees, anticlockwise about the view as seen from behind
+	Roll float64 `yaml:"roll"`
+	// Shift moves the image right and up by fractions of its size
+	Shift []float64 `yaml:"shift"`
+	// Shutter is when the shutter opens and closes, for objects in motion
+	Shutter       []float64   `yaml:"shutter"`
+	Aperture      float64     `yaml:"aperture"`
+	FocalDistance float64     `yaml:"focal-distance"`
+	BokehBlades   int         `yaml:"bokeh-blades"`
+	Animate       *CameraKeys `yaml:"animate"`
+}
+
+type RenderSpec struct {
+	Samples    int    `yaml:"samples"`
+	Integrator string `yaml:"integrator"`
+}
+
+type LightSpec struct {
+	Position  []float64  `yaml:"position"`
+	Intensity []float64  `yaml:"intensity"`
+	Animate   *LightKeys `yaml:"animate"`
+}
+
+// MaterialSpec changes the default material, fields left out keep their
+// default.
+type MaterialSpec struct {
+	Color            []float64     `yaml:"color"`
+	Pattern          *PatternSpec  `yaml:"pattern"`
+	Ambient          *float64      `yaml:"ambient"`
+	Diffuse          *float64      `yaml:"diffuse"`
+	Specular         *float64      `yaml:"specular"`
+	Shininess        *float64      `yaml:"shininess"`
+	Reflective       *float64      `yaml:"reflective"`
+	Transparency     *float64      `yaml:"transparency"`
+	RefractiveIndex  *float64      `yaml:"refractive-index"`
+	Roughness        *float64      `yaml:"roughness"`
+	Emission         []float64     `yaml:"emission"`
+	EmissionStrength *float64      `yaml:"emission-strength"`
+	Metallic         *float64      `yaml:"metallic"`
+	Animate          *MaterialKeys `yaml:"animate"`
+}
+
+type PatternSpec struct {
+	// Type is stripe, gradient, ring, checker or noise
+	Type      string      `yaml:"type"`
+	A         []float64   `yaml:"a"`
+	B         []float64   `yaml:"b"`
+	Transform []Transform `yaml:"transform"`
+}
+
+// MaterialRef is either the name of one of the scene's materials or a
+// material written out in place.
+type MaterialRef struct {
+	Name string
+	Spec *MaterialSpec
+}
+
+func (m *MaterialRef) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&m.Name)
+	}
+	m.Spec = &MaterialSpec{}
+	return value.Decode(m.Spec)
+}
+
+// Transform is one step like [translate, x, y, z], [scale, x, y, z] or
+// [rotate-y, degrees]. A list of them is applied in order.
+type Transform []interface{}
+
+type ObjectSpec struct {
+	// Type is sphere, plane, cube, cylinder, cone, quad, hexagon, model or
+	// group
+	Type      string      `yaml:"type"`
+	Material  MaterialRef `yaml:"material"`
+	Transform []Transform `yaml:"transform"`
+	// File is the Wavefront OBJ file of a model
+	File string `yaml:"file"`
+	// Minimum, Maximum and Closed bound cylinders and cones
+	Minimum  *float64     `yaml:"minimum"`
+	Maximum  *float64     `yaml:"maximum"`
+	Closed   bool         `yaml:"closed"`
+	Children []ObjectSpec `yaml:"children"`
+	// Motion moves the object through keyframes while the shutter's open
+	Motion []KeyframeSpec `yaml:"motion"`
+	// Animate moves the object from frame to frame, in place of its
+	// transform
+	Animate []TransformKey `yaml:"animate"`
+}
+
+// KeyframeSpec is where an object is at a time, its transform applied
+// after the object's own.
+type KeyframeSpec struct {
+	Time      float64     `yaml:"time"`
+	Transform []Transform `yaml:"transform"`
+}
+
+// Scene is everything needed to render a scene file.
+type Scene struct {
+	World    scene.World
+	Camera   scene.Camera
+	Settings scene.RenderSettings
+}
+
+// LoadScene reads a scene file, files it refers to are relative to it.
+func LoadScene(fname string) (s Scene, err error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return s, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return NewScene(f, filepath.Dir(fname))
+}
+
+// NewScene reads a scene, files it refers to are relative to dir.
+func NewScene(r io.Reader, dir string) (s Scene, err error) {
+	sf, err := ParseSceneFile(r)
+	if err != nil {
+		return s, err
+	}
+	return sf.Build(dir)
+}
+
+func ParseSceneFile(r io.Reader) (sf SceneFile, err error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err = decoder.Decode(&sf); err != nil && !errors.Is(err, io.EOF) {
+		return sf, fmt.Errorf("reading scene: %w", err)
+	}
+	return sf, nil
+}
+
+// Size is how big the image is, the default for what's left out.
+func (sf SceneFile) Size() (width, height int) {
+	width, height = sf.Width, sf.Height
+	if width <= 0 {
+		width = defaultSceneWidth
+	}
+	if height <= 0 {
+		height = defaultSceneHeight
+	}
+	return width, height
+}
+
+// Build makes the world, camera and render settings the file describes.
+func (sf SceneFile) Build(dir string) (s Scene, err error) {
+	s, _, err = sf.build(dir)
+	return s, err
+}
+
+func (sf SceneFile) build(dir string) (s Scene, objs []builtObject, err error) {
+	width, height := sf.Size()
+	if s.Camera, err = sf.Camera.Build(width, height); err != nil {
+		return s, nil, err
+	}
+
+	s.Settings = scene.DefaultRenderSettings()
+	if sf.Render.Samples > 0 {
+		s.Settings.Samples = sf.Render.Samples
+	}
+	if s.Settings.Integrator, err = scene.ParseIntegrator(sf.Render.Integrator); err != nil {
+		return s, nil, err
+	}
+
+	s.World = scene.NewWorld()
+	s.World.SetTransparentShadows(sf.TransparentShadows)
+	if sf.Light != nil {
+		light, err := sf.Light.Build()
+		if err != nil {
+			return s, nil, err
+		}
+		s.World.AddLight(light)
+	}
+	if sf.Background != nil {
+		c, err := color(sf.Background)
+		if err != nil {
+			return s, nil, fmt.Errorf("background: %w", err)
+		}
+		s.World.SetBackground(scene.NewSolidBackground(c))
+	}
+	if sf.Environment != "" {
+		c, err := LoadImage(resolve(dir, sf.Environment))
+		if err != nil {
+			return s, nil, err
+		}
+		light := scene.NewEnvironmentLight(c)
+		if sf.EnvironmentSamples > 0 {
+			light.Samples = sf.EnvironmentSamples
+		}
+		s.World.SetEnvironmentLight(light)
+		s.World.SetBackground(light)
+	}
+
+	for i := range sf.Objects {
+		built, err := sf.Objects[i].build(sf.Materials, dir)
+		if err != nil {
+			return s, nil, fmt.Errorf("object %v: %w", i+1, err)
+		}
+		s.World.AddObject(built.obj)
+		objs = append(objs, built)
+	}
+	return s, objs, nil
+}
+
+func (c CameraSpec) Build(width, height int) (camera scene.Camera, err error) {
+	projection, err := scene.ParseProjection(c.Projection)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, up := ray.ZeroPoint, ray.NewPoint(0, 0, -1), ray.NewVec(0, 1, 0)
+	if c.From != nil {
+		if from, err = point(c.From); err != nil {
+			return nil, fmt.Errorf("camera from: %w", err)
+		}
+	}
+	if c.To != nil {
+		if to, err = point(c.To); err != nil {
+			return nil, fmt.Errorf("camera to: %w", err)
+		}
+	}
+	if c.Up != nil {
+		if up, err = vector(c.Up); err != nil {
+			return nil, fmt.Errorf("camera up: %w", err)
+		}
+	}
+
+	opts := []scene.CameraOption{scene.WithProjection(projection)}
+	fieldOfView, err := c.fieldOfView(projection)
+	if err != nil {
+		return nil, err
+	}
+	if fieldOfView != nil {
+		opts = append(opts, fieldOfView)
+	}
+	if c.Roll != 0 {
+		opts = append(opts, scene.WithRoll(c.Roll*math.Pi/180))
+	}
+	if c.Shift != nil {
+		if len(c.Shift) != 2 {
+			return nil, fmt.Errorf("camera shift takes 2 numbers, got %v", len(c.Shift))
+		}
+		opts = append(opts, scene.WithLensShift(c.Shift[0], c.Shift[1]))
+	}
+	focus := c.FocalDistance
+	if focus <= 0 {
+		focus = to.Subtract(from).Magnitude()
+	}
+	opts = append(opts, scene.WithLens(c.Aperture, focus))
+	if c.Shutter != nil {
+		if len(c.Shutter) != 2 {
+			return nil, fmt.Errorf("camera shutter takes 2 numbers, got %v", len(c.Shutter))
+		}
+		opts = append(opts, scene.WithShutter(c.Shutter[0], c.Shutter[1]))
+	}
+
+	if camera, err = scene.NewLookAtCamera(width, height, from, to, up, opts...); err != nil {
+		return nil, err
+	}
+	camera.SetBokeh(c.BokehBlades, 0)
+	return camera, nil
+}
+
+// fieldOfView picks how wide the camera sees, a focal length first then
+// the horizontal, vertical and plain field of view.
+func (c CameraSpec) fieldOfView(projection scene.Projection) (scene.CameraOption, error) {
+	switch {
+	case projection == scene.Equirectangular:
+		// always sees all the way around
+		return nil, nil
+	case projection == scene.Orthographic:
+		if c.ViewWidth > 0 {
+			return scene.WithFieldOfView(c.ViewWidth), nil
+		}
+		return scene.WithFieldOfView(defaultSceneViewWidth), nil
+	case c.FocalLength > 0:
+		sensor := []float64{36, 24}
+		if c.Sensor != nil {
+			if len(c.Sensor) != 2 {
+				return nil, fmt.Errorf("camera sensor takes 2 numbers, got %v", len(c.Sensor))
+			}
+			sensor = c.Sensor
+		}
+		return scene.WithFocalLength(c.FocalLength, sensor[0], sensor[1]), nil
+	case c.HorizontalFOV > 0:
+		return scene.WithHorizontalFieldOfView(c.HorizontalFOV * math.Pi / 180), nil
+	case c.VerticalFOV > 0:
+		return scene.WithVerticalFieldOfView(c.VerticalFOV * math.Pi / 180), nil
+	case c.FieldOfView > 0:
+		return scene.WithFieldOfView(c.FieldOfView * math.Pi / 180), nil
+	default:
+		return scene.WithFieldOfView(defaultSceneFOV * math.Pi / 180), nil
+	}
+}
+
+func (l LightSpec) Build() (light object.PointLight, err error) {
+	position, err := point(l.Position)
+	if err != nil {
+		return light, fmt.Errorf("light position: %w", err)
+	}
+	intensity := object.White
+	if l.Intensity != nil {
+		if intensity, err = color(l.Intensity); err != nil {
+			return light, fmt.Errorf("light intensity: %w", err)
+		}
+	}
+	return object.NewPointLight(position, intensity), nil
+}
+
+func (m MaterialSpec) Build() (material object.Material, err error) {
+	material = object.DefaultMaterial()
+	if m.Color != nil {
+		if material.Color, err = color(m.Color); err != nil {
+			return material, fmt.Errorf("color: %w", err)
+		}
+	}
+	if m.Pattern != nil {
+		if material.Pattern, err = m.Pattern.Build(); err != nil {
+			return material, err
+		}
+	}
+	for _, f := range []struct {
+		value *float64
+		field *float64
+	}{
+		{m.Ambient, &material.Ambient},
+		{m.Diffuse, &material.Diffuse},
+		{m.Specular, &material.Specular},
+		{m.Shininess, &material.Shininess},
+		{m.Reflective, &material.Reflective},
+		{m.Transparency, &material.Transparency},
+		{m.RefractiveIndex, &material.RefractiveIndex},
+		{m.Roughness, &material.Roughness},
+		{m.EmissionStrength, &material.EmissionStrength},
+	} {
+		if f.value != nil {
+			*f.field = *f.value
+		}
+	}
+	if m.Emission != nil {
+		if material.Emission, err = color(m.Emission); err != nil {
+			return material, fmt.Errorf("emission: %w", err)
+		}
+	}
+	if m.Metallic != nil {
+		roughness := 0.5
+		if m.Roughness != nil {
+			roughness = *m.Roughness
+		}
+		material.PBR = object.NewPBR(*m.Metallic, roughness)
+	}
+	return material, nil
+}
+
+func (p PatternSpec) Build() (pattern object.Pattern, err error) {
+	a, err := color(p.A)
+	if err != nil {
+		return pattern, fmt.Errorf("pattern a: %w", err)
+	}
+	b, err := color(p.B)
+	if err != nil {
+		return pattern, fmt.Errorf("pattern b: %w", err)
+	}
+	switch strings.ToLower(p.Type) {
+	case "stripe":
+		pattern = object.NewStripePattern(a, b)
+	case "gradient":
+		pattern = object.NewGradientPattern(a, b)
+	case "ring":
+		pattern = object.NewRingPattern(a, b)
+	case "checker":
+		pattern = object.NewCheckerPattern(a, b)
+	case "noise":
+		pattern = object.NewNoisePattern(a, b)
+	default:
+		return pattern, fmt.Errorf("unknown pattern %q", p.Type)
+	}
+	transform, err := transforms(p.Transform)
+	if err != nil {
+		return pattern, err
+	}
+	return pattern, pattern.SetTransform(transform)
+}
+
+func (o ObjectSpec) Build(materials map[string]MaterialSpec, dir string) (obj object.Object, err error) {
+	built, err := o.build(materials, dir)
+	return built.obj, err
+}
+
+// builtObject is an object built from a spec along with its children, so
+// they can be placed again for another frame.
+type builtObject struct {
+	obj      object.Object
+	children []builtObject
+}
+
+func (o ObjectSpec) build(materials map[string]MaterialSpec, dir string) (built builtObject, err error) {
+	var obj object.Object
+	switch strings.ToLower(o.Type) {
+	case "sphere":
+		obj = object.DefaultSphere()
+	case "plane":
+		obj = object.NewPlane()
+	case "cube":
+		obj = object.NewCube()
+	case "cylinder":
+		minimum, maximum := bounds(o.Minimum, o.Maximum)
+		obj = object.NewCylinder(minimum, maximum, o.Closed)
+	case "cone":
+		minimum, maximum := bounds(o.Minimum, o.Maximum)
+		obj = object.NewCone(minimum, maximum, o.Closed)
+	case "quad":
+		obj = object.NewQuad()
+	case "hexagon":
+		obj = object.NewHexagon()
+	case "model":
+		if obj, err = loadModel(resolve(dir, o.File)); err != nil {
+			return built, err
+		}
+	case "group":
+		g := object.NewGroup()
+		obj = &g
+	default:
+		return built, fmt.Errorf("unknown object type %q", o.Type)
+	}
+	built.obj = obj
+
+	if err = o.place(obj, materials); err != nil {
+		return built, err
+	}
+
+	if len(o.Children) > 0 {
+		g, ok := obj.(*object.Group)
+		if !ok {
+			return built, fmt.Errorf("only groups can have children, not %q", o.Type)
+		}
+		for i := range o.Children {
+			child, err := o.Children[i].build(materials, dir)
+			if err != nil {
+				return built, fmt.Errorf("child %v: %w", i+1, err)
+			}
+			g.AddChild(child.obj)
+			built.children = append(built.children, child)
+		}
+	}
+	return built, nil
+}
+
+// place sets the object's transform, motion and material from the spec.
+func (o ObjectSpec) place(obj object.Object, materials map[string]MaterialSpec) error {
+	transform, err := transforms(o.Transform)
+	if err != nil {
+		return err
+	}
+	if err = obj.SetTransform(transform); err != nil {
+		return err
+	}
+	if len(o.Motion) > 0 {
+		keys := make([]object.Keyframe, len(o.Motion))
+		for i := range o.Motion {
+			key, err := transforms(o.Motion[i].Transform)
+			if err != nil {
+				return fmt.Errorf("motion at %v: %w", o.Motion[i].Time, err)
+			}
+			keys[i] = object.Keyframe{Time: o.Motion[i].Time, Transform: key.Multiply(transform)}
+		}
+		if err = obj.SetKeyframes(keys...); err != nil {
+			return err
+		}
+	}
+
+	material, ok, err := o.Material.resolve(materials)
+	if err != nil {
+		return err
+	}
+	if ok {
+		obj.SetMaterial(material)
+	}
+	return nil
+}
+
+// replace moves an object built from the spec to where the spec now puts
+// it, and its children too.
+func (o ObjectSpec) replace(built builtObject, materials map[string]MaterialSpec) error {
+	if err := o.place(built.obj, materials); err != nil {
+		return err
+	}
+	for i := range built.children {
+		if err := o.Children[i].replace(built.children[i], materials); err != nil {
+			return fmt.Errorf("child %v: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// resolve is the material referred to, ok is false when there isn't one.
+func (m MaterialRef) resolve(materials map[string]MaterialSpec) (material object.Material, ok bool, err error) {
+	switch {
+	case m.Spec != nil:
+		material, err = m.Spec.Build()
+	case m.Name != "":
+		spec, found := materials[m.Name]
+		if !found {
+			return material, false, fmt.Errorf("unknown material %q", m.Name)
+		}
+		material, err = spec.Build()
+	default:
+		return material, false, nil
+	}
+	return material, err == nil, err
+}
+
+func loadModel(fname string) (object.Object, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	wavObj, err := object.NewWavefrontObj(f)
+	if err != nil {
+		return nil, err
+	}
+	return wavObj.Object(), nil
+}
+
+// transforms combines the steps so the first one listed happens first.
+func transforms(steps []Transform) (m ray.Matrix, err error) {
+	m = ray.DefaultIdentityMatrix()
+	for _, step := range steps {
+		t, err := step.Matrix()
+		if err != nil {
+			return nil, err
+		}
+		m = t.Multiply(m)
+	}
+	return m, nil
+}
+
+func (t Transform) Matrix() (ray.Matrix, error) {
+	if len(t) == 0 {
+		return nil, errors.New("empty transform")
+	}
+	op, ok := t[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("transform should start with its name, not %v", t[0])
+	}
+	args := make([]float64, len(t)-1)
+	for i, v := range t[1:] {
+		n, err := number(op, v)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = n
+	}
+
+	want := 3
+	if strings.HasPrefix(op, "rotate-") {
+		want = 1
+	}
+	if len(args) != want {
+		return nil, fmt.Errorf("%s takes %v numbers, got %v", op, want, len(args))
+	}
+	switch op {
+	case "translate":
+		return ray.Translation(args[0], args[1], args[2]), nil
+	case "scale":
+		return ray.Scaling(args[0], args[1], args[2]), nil
+	case "rotate-x":
+		return ray.Rotation(ray.X, args[0]*math.Pi/180), nil
+	case "rotate-y":
+		return ray.Rotation(ray.Y, args[0]*math.Pi/180), nil
+	case "rotate-z":
+		return ray.Rotation(ray.Z, args[0]*math.Pi/180), nil
+	default:
+		return nil, fmt.Errorf("unknown transform %q", op)
+	}
+}
+
+func number(op, v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	default:
+		return 0, fmt.Errorf("%s: %v isn't a number", op, v)
+	}
+}
+
+func bounds(minimum, maximum *float64) (float64, float64) {
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if minimum != nil {
+		lo = *minimum
+	}
+	if maximum != nil {
+		hi = *maximum
+	}
+	return lo, hi
+}
+
+func resolve(dir, fname string) string {
+	if filepath.IsAbs(fname) {
+		return fname
+	}
+	return filepath.Join(dir, fname)
+}
+
+func triple(values []float64) (x, y, z float64, err error) {
+	if len(values) != 3 {
+		return 0, 0, 0, fmt.Errorf("expected 3 numbers, got %v", len(values))
+	}
+	return values[0], values[1], values[2], nil
+}
+
+func point(values []float64) (ray.Vector, error) {
+	x, y, z, err := triple(values)
+	return ray.NewPoint(x, y, z), err
+}
+
+func vector(values []float64) (ray.Vector, error) {
+	x, y, z, err := triple(values)
+	return ray.NewVec(x, y, z), err
+}
+
+func color(values []float64) (object.RGB, error) {
+	r, g, b, err := triple(values)
+	return object.NewColor(r, g, b), err
+}
@@ -0,0 +1,258 @@
+package input_test
+
+import (
+	"math"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/input"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+const sceneYAML = `
+width: 80
+height: 40
+camera:
+  projection: fisheye
+  fov: 180
+  from: [0, 1, -5]
+  to: [0, 1, 0]
+render:
+  samples: 4
+  integrator: path
+transparent-shadows: true
+light:
+  position: [-10, 10, -10]
+materials:
+  red:
+    color: [1, 0, 0]
+    specular: 0
+objects:
+  - type: plane
+    material:
+      pattern:
+        type: checker
+        a: [1, 1, 1]
+        b: [0, 0, 0]
+  - type: sphere
+    material: red
+    transform:
+      - [scale, 0.5, 0.5, 0.5]
+      - [translate, 0, 1, 0]
+  - type: group
+    children:
+      - type: cylinder
+        minimum: 0
+        maximum: 1
+        closed: true
+      - type: model
+        file: ../../../test/models/utah-teapot-low.obj
+`
+
+func TestNewScene(t *testing.T) {
+	s, err := input.NewScene(strings.NewReader(sceneYAML), ".")
+	require.NoError(t, err)
+
+	assert.Equal(t, 80, s.Camera.HSize())
+	assert.Equal(t, 40, s.Camera.VSize())
+	assert.Equal(t, math.Pi, s.Camera.FieldOfView())
+	r := s.Camera.RayForPixel(39.5, 19.5)
+	assert.InDelta(t, 1, r.Direction().GetZ(), 1e-9, "looks toward to")
+
+	assert.Equal(t, 4, s.Settings.Samples)
+	assert.IsType(t, scene.PathTracer{}, s.Settings.Integrator)
+	assert.Equal(t, ray.NewPoint(-10, 10, -10), s.World.Light().Position)
+	assert.Equal(t, object.White, s.World.Light().Intensity)
+	assert.True(t, s.World.TransparentShadows())
+
+	objs := s.World.Objects()
+	require.Len(t, objs, 3)
+	assert.True(t, objs[0].Material().Pattern.IsNotEmpty)
+	assert.Equal(t, object.NewColor(1, 0, 0), objs[1].Material().Color)
+	assert.Equal(t, 0.0, objs[1].Material().Specular)
+	assert.Equal(t, object.DefaultMaterial().Diffuse, objs[1].Material().Diffuse)
+	assert.Equal(t, ray.Translation(0, 1, 0).Multiply(ray.Scaling(0.5, 0.5, 0.5)), objs[1].Transform(),
+		"transforms happen in the order they're listed")
+	g, ok := objs[2].(*object.Group)
+	require.True(t, ok)
+	require.Len(t, g.Children, 2)
+	assert.NotEmpty(t, g.Children[1].(*object.Group).Children, "model loaded")
+}
+
+func TestNewScene_json(t *testing.T) {
+	s, err := input.NewScene(strings.NewReader(`{
+  "camera": {"projection": "orthographic", "view-width": 4},
+  "objects": [{"type": "cube", "material": {"color": [0, 1, 0]}}]
+}`), ".")
+	require.NoError(t, err)
+	assert.Equal(t, 640, s.Camera.HSize())
+	assert.Equal(t, 320, s.Camera.VSize())
+	r := s.Camera.RayForPixel(0, 0)
+	assert.InDelta(t, 1.996875, r.Origin().GetX(), 1e-9)
+	require.Len(t, s.World.Objects(), 1)
+	assert.Equal(t, object.NewColor(0, 1, 0), s.World.Objects()[0].Material().Color)
+}
+
+func TestNewScene_camera(t *testing.T) {
+	testCases := []struct {
+		name        string
+		camera      string
+		fieldOfView float64
+	}{
+		{
+			name:        "horizontal field of view",
+			camera:      "horizontal-fov: 60",
+			fieldOfView: math.Pi / 3,
+		},
+		{
+			name:        "vertical field of view",
+			camera:      "vertical-fov: 60",
+			fieldOfView: 2 * math.Atan(2*math.Tan(math.Pi/6)),
+		},
+		{
+			name:        "full frame focal length",
+			camera:      "focal-length: 50",
+			fieldOfView: 2 * math.Atan(18.0/50),
+		},
+		{
+			name:        "focal length on a smaller sensor",
+			camera:      "focal-length: 50\n  sensor: [24, 16]",
+			fieldOfView: 2 * math.Atan(12.0/50),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := input.NewScene(strings.NewReader("width: 200\nheight: 100\ncamera:\n  "+tt.camera+"\n"), ".")
+			require.NoError(t, err)
+			assert.InDelta(t, tt.fieldOfView, s.Camera.FieldOfView(), 1e-9)
+		})
+	}
+
+	t.Run("roll and shift", func(t *testing.T) {
+		s, err := input.NewScene(strings.NewReader(`
+width: 100
+height: 100
+camera:
+  from: [0, 1, 5]
+  to: [0, 1, 0]
+  fov: 90
+  roll: 90
+  shift: [0, 0.25]
+`), ".")
+		require.NoError(t, err)
+		r := s.Camera.RayForPixel(49.5, 49.5)
+		assert.Equal(t, ray.NewPoint(0, 1, 5), s.Camera.Position())
+		assert.InDelta(t, 0.5/math.Sqrt(1.25), r.Direction().GetX(), 1e-9, "shifted up, rolled to the left")
+		assert.InDelta(t, 0, r.Direction().GetY(), 1e-9)
+	})
+}
+
+func TestNewScene_motion(t *testing.T) {
+	s, err := input.NewScene(strings.NewReader(`
+camera:
+  shutter: [0, 0.5]
+objects:
+  - type: sphere
+    transform:
+      - [translate, 0, 1, 0]
+    motion:
+      - time: 0
+        transform: []
+      - time: 1
+        transform:
+          - [translate, 2, 0, 0]
+`), ".")
+	require.NoError(t, err)
+	open, closed := s.Camera.Shutter()
+	assert.Equal(t, 0.0, open)
+	assert.Equal(t, 0.5, closed)
+
+	sphere := s.World.Objects()[0]
+	require.Len(t, sphere.Keyframes(), 2)
+	assert.Equal(t, ray.Translation(0, 1, 0), sphere.Transform())
+	assert.Equal(t, ray.Translation(2, 0, 0).Multiply(ray.Translation(0, 1, 0)), sphere.Keyframes()[1].Transform,
+		"keyframes move the object from where its transform put it")
+}
+
+func TestNewScene_errors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "unknown field",
+			content: "objects:\n  - type: sphere\n    colour: [1, 0, 0]\n",
+			err:     "field colour not found",
+		},
+		{
+			name:    "unknown material",
+			content: "objects:\n  - type: sphere\n    material: gold\n",
+			err:     `unknown material "gold"`,
+		},
+		{
+			name:    "unknown object",
+			content: "objects:\n  - type: torus\n",
+			err:     `unknown object type "torus"`,
+		},
+		{
+			name:    "bad transform",
+			content: "objects:\n  - type: sphere\n    transform:\n      - [translate, 1, 2]\n",
+			err:     "translate takes 3 numbers, got 2",
+		},
+		{
+			name:    "children outside a group",
+			content: "objects:\n  - type: sphere\n    children:\n      - type: cube\n",
+			err:     "only groups can have children",
+		},
+		{
+			name:    "unknown camera",
+			content: "camera:\n  projection: pinhole\n",
+			err:     `unknown camera "pinhole"`,
+		},
+		{
+			name:    "bad lens shift",
+			content: "camera:\n  shift: [0.1]\n",
+			err:     "camera shift takes 2 numbers, got 1",
+		},
+		{
+			name:    "bad sensor",
+			content: "camera:\n  focal-length: 35\n  sensor: [36]\n",
+			err:     "camera sensor takes 2 numbers, got 1",
+		},
+		{
+			name:    "bad shutter",
+			content: "camera:\n  shutter: [1]\n",
+			err:     "camera shutter takes 2 numbers, got 1",
+		},
+		{
+			name:    "bad motion",
+			content: "objects:\n  - type: sphere\n    motion:\n      - time: 1\n        transform:\n          - [scale, 2]\n",
+			err:     "motion at 1",
+		},
+		{
+			name:    "missing model",
+			content: "objects:\n  - type: model\n    file: missing.obj\n",
+			err:     "missing.obj",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := input.NewScene(strings.NewReader(tt.content), ".")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestLoadScene(t *testing.T) {
+	s, err := input.LoadScene(path.Join("..", "..", "..", "test", "scenes", "teapot.yaml"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, s.World.Objects())
+}
@@ -0,0 +1,240 @@
+package object
+
+import (
+	"math"
+	"sort"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+const (
+	defaultBlobSamples       = 16
+	defaultBlobRootTolerance = 1e-9
+	defaultBlobRootMaxSteps  = 64
+)
+
+// BlobComponent is one weighted field that makes up a blob. The field must
+// be zero outside of the component's bounding sphere.
+type BlobComponent interface {
+	Field(point ray.Vector) float64
+	Gradient(point ray.Vector) ray.Vector
+	Bounds() (centre ray.Vector, radius float64)
+}
+
+type blob struct {
+	obj
+	threshold  float64
+	components []BlobComponent
+	samples    int
+}
+
+// NewBlob creates an implicit surface where the sum of the component fields
+// equals the threshold.
+func NewBlob(threshold float64, components []BlobComponent, opts ...Option) Object {
+	b := &blob{
+		threshold:  threshold,
+		components: components,
+		samples:    defaultBlobSamples,
+	}
+	_ = b.SetTransform(ray.DefaultIdentityMatrix())
+	b.SetMaterial(DefaultMaterial())
+
+	for i := range opts {
+		opts[i].Apply(b)
+	}
+	return b
+}
+
+// WithBlobSamples sets how many times each bounded interval is sampled while
+// looking for a change of sign to refine.
+func WithBlobSamples(samples int) Option {
+	return OptionFunc(func(o Object) {
+		if b, ok := o.(*blob); ok {
+			b.samples = samples
+		}
+	})
+}
+
+type blobSpan struct {
+	t0, t1    float64
+	component BlobComponent
+}
+
+func (b *blob) LocalIntersect(r ray.Ray) (xs Intersections) {
+	spans := make([]blobSpan, 0, len(b.components))
+	bounds := make([]float64, 0, len(b.components)*2)
+	for i := range b.components {
+		centre, radius := b.components[i].Bounds()
+		t0, t1, ok := intersectBounds(r, centre, radius)
+		if !ok {
+			continue
+		}
+		spans = append(spans, blobSpan{t0: t0, t1: t1, component: b.components[i]})
+		bounds = append(bounds, t0, t1)
+	}
+	sort.Float64s(bounds)
+
+	// only the components whose bounds overlap a segment contribute to it
+	var active []BlobComponent
+	for i := 0; i < len(bounds)-1; i++ {
+		t0, t1 := bounds[i], bounds[i+1]
+		if t1-t0 <= 0 {
+			continue
+		}
+		mid := (t0 + t1) / 2
+		active = active[:0]
+		for j := range spans {
+			if spans[j].t0 <= mid && mid <= spans[j].t1 {
+				active = append(active, spans[j].component)
+			}
+		}
+		if len(active) == 0 {
+			continue
+		}
+		xs = b.findRoots(r, active, t0, t1, xs)
+	}
+	return xs
+}
+
+func (b *blob) findRoots(r ray.Ray, components []BlobComponent, t0, t1 float64, xs Intersections) Intersections {
+	f := func(t float64) float64 {
+		point := r.PointAt(t)
+		sum := -b.threshold
+		for i := range components {
+			sum += components[i].Field(point)
+		}
+		return sum
+	}
+
+	step := (t1 - t0) / float64(b.samples)
+	prevT, prev := t0, f(t0)
+	for i := 1; i <= b.samples; i++ {
+		t := t0 + step*float64(i)
+		current := f(t)
+		if (prev < 0) != (current < 0) {
+			xs = append(xs, Intersection{
+				T:   bisect(f, prevT, t, prev),
+				Obj: b,
+			})
+		}
+		prevT, prev = t, current
+	}
+	return xs
+}
+
+// bisect narrows an interval known to contain a change of sign.
+func bisect(f func(float64) float64, lo, hi, fLo float64) float64 {
+	for i := 0; i < defaultBlobRootMaxSteps && hi-lo > defaultBlobRootTolerance; i++ {
+		mid := (lo + hi) / 2
+		fMid := f(mid)
+		if (fLo < 0) == (fMid < 0) {
+			lo, fLo = mid, fMid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+func (b *blob) LocalNormalAt(point ray.Vector, _ Intersection) ray.Vector {
+	// the field falls away from the surface so the normal points down the gradient
+	normal := ray.NewVec(0, 0, 0)
+	for i := range b.components {
+		normal = normal.Subtract(b.components[i].Gradient(point))
+	}
+	return normal
+}
+
+func intersectBounds(r ray.Ray, centre ray.Vector, radius float64) (t0, t1 float64, ok bool) {
+	toRay := r.Origin().Subtract(centre)
+	a := ray.Dot(r.Direction(), r.Direction())
+	bb := 2 * ray.Dot(r.Direction(), toRay)
+	c := ray.Dot(toRay, toRay) - radius*radius
+	discriminant := bb*bb - 4*a*c
+	if discriminant < 0 {
+		return 0, 0, false
+	}
+	sqrtDisc := math.Sqrt(discriminant)
+	return (-bb - sqrtDisc) / (2 * a), (-bb + sqrtDisc) / (2 * a), true
+}
+
+type blobSphere struct {
+	centre           ray.Vector
+	radius, strength float64
+}
+
+// NewBlobSphere is a component whose field falls off with the distance from
+// its centre, reaching zero at radius.
+func NewBlobSphere(centre ray.Vector, radius, strength float64) BlobComponent {
+	return blobSphere{
+		centre:   centre,
+		radius:   radius,
+		strength: strength,
+	}
+}
+
+func (s blobSphere) Field(point ray.Vector) float64 {
+	return falloff(point.Subtract(s.centre), s.radius, s.strength)
+}
+
+func (s blobSphere) Gradient(point ray.Vector) ray.Vector {
+	return falloffGradient(point.Subtract(s.centre), s.radius, s.strength)
+}
+
+func (s blobSphere) Bounds() (centre ray.Vector, radius float64) {
+	return s.centre, s.radius
+}
+
+type blobCylinder struct {
+	a, b             ray.Vector
+	radius, strength float64
+}
+
+// NewBlobCylinder is a component whose field falls off with the distance from
+// the segment a-b, reaching zero at radius.
+func NewBlobCylinder(a, b ray.Vector, radius, strength float64) BlobComponent {
+	return blobCylinder{
+		a:        a,
+		b:        b,
+		radius:   radius,
+		strength: strength,
+	}
+}
+
+func (c blobCylinder) offset(point ray.Vector) ray.Vector {
+	ba := c.b.Subtract(c.a)
+	h := clamp(ray.Dot(point.Subtract(c.a), ba)/ray.Dot(ba, ba), 0, 1)
+	return point.Subtract(c.a.Add(ba.Multiply(h))).SetW(0)
+}
+
+func (c blobCylinder) Field(point ray.Vector) float64 {
+	return falloff(c.offset(point), c.radius, c.strength)
+}
+
+func (c blobCylinder) Gradient(point ray.Vector) ray.Vector {
+	return falloffGradient(c.offset(point), c.radius, c.strength)
+}
+
+func (c blobCylinder) Bounds() (centre ray.Vector, radius float64) {
+	half := c.b.Subtract(c.a).Divide(2)
+	return c.a.Add(half), half.SetW(0).Magnitude() + c.radius
+}
+
+// falloff is strength * (1 - d²/r²)², smooth and zero from r onwards
+func falloff(offset ray.Vector, radius, strength float64) float64 {
+	d2 := ray.Dot(offset.SetW(0), offset.SetW(0)) / (radius * radius)
+	if d2 >= 1 {
+		return 0
+	}
+	return strength * (1 - d2) * (1 - d2)
+}
+
+func falloffGradient(offset ray.Vector, radius, strength float64) ray.Vector {
+	offset = offset.SetW(0)
+	r2 := radius * radius
+	d2 := ray.Dot(offset, offset) / r2
+	if d2 >= 1 {
+		return ray.NewVec(0, 0, 0)
+	}
+	return offset.Multiply(-4 * strength * (1 - d2) / r2)
+}
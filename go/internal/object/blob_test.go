@@ -0,0 +1,141 @@
+package object_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+func TestBlob_LocalIntersect(t *testing.T) {
+	// a single unit component with a threshold of 0.5 has a surface where
+	// (1 - d²)² = 0.5
+	surface := math.Sqrt(1 - math.Sqrt(0.5))
+	testCases := []struct {
+		name       string
+		components []object.BlobComponent
+		origin     ray.Vector
+		direction  ray.Vector
+		expected   []float64
+	}{
+		{
+			name:       "A ray intersects a single sphere component",
+			components: []object.BlobComponent{object.NewBlobSphere(ray.ZeroPoint, 1, 1)},
+			origin:     ray.NewPoint(0, 0, -5),
+			direction:  ray.NewVec(0, 0, 1),
+			expected:   []float64{5 - surface, 5 + surface},
+		},
+		{
+			name:       "A ray misses the bounds of a component",
+			components: []object.BlobComponent{object.NewBlobSphere(ray.ZeroPoint, 1, 1)},
+			origin:     ray.NewPoint(0, 2, -5),
+			direction:  ray.NewVec(0, 0, 1),
+		},
+		{
+			name:       "A ray inside the bounds misses the surface",
+			components: []object.BlobComponent{object.NewBlobSphere(ray.ZeroPoint, 1, 1)},
+			origin:     ray.NewPoint(0, 0.8, -5),
+			direction:  ray.NewVec(0, 0, 1),
+		},
+		{
+			name: "Two components merge into one surface",
+			components: []object.BlobComponent{
+				object.NewBlobSphere(ray.NewPoint(-0.5, 0, 0), 1, 1),
+				object.NewBlobSphere(ray.NewPoint(0.5, 0, 0), 1, 1),
+			},
+			origin:    ray.NewPoint(-5, 0, 0),
+			direction: ray.NewVec(1, 0, 0),
+			expected:  []float64{5 - 0.5 - surface, 5 + 0.5 + surface},
+		},
+		{
+			name: "Two components far apart stay separate",
+			components: []object.BlobComponent{
+				object.NewBlobSphere(ray.NewPoint(-2, 0, 0), 1, 1),
+				object.NewBlobSphere(ray.NewPoint(2, 0, 0), 1, 1),
+			},
+			origin:    ray.NewPoint(-5, 0, 0),
+			direction: ray.NewVec(1, 0, 0),
+			expected:  []float64{3 - surface, 3 + surface, 7 - surface, 7 + surface},
+		},
+		{
+			name: "A cylinder component",
+			components: []object.BlobComponent{
+				object.NewBlobCylinder(ray.NewPoint(0, -2, 0), ray.NewPoint(0, 2, 0), 1, 1),
+			},
+			origin:    ray.NewPoint(0, 1, -5),
+			direction: ray.NewVec(0, 0, 1),
+			expected:  []float64{5 - surface, 5 + surface},
+		},
+		{
+			name: "A negative component carves out a hole",
+			components: []object.BlobComponent{
+				object.NewBlobSphere(ray.ZeroPoint, 2, 1),
+				object.NewBlobSphere(ray.ZeroPoint, 0.5, -2),
+			},
+			origin:    ray.NewPoint(0, 0, -5),
+			direction: ray.NewVec(0, 0, 1),
+			expected:  []float64{5 - 2*surface, 5 - 0.3654, 5 + 0.3654, 5 + 2*surface},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			b := object.NewBlob(0.5, tt.components)
+			xs := b.LocalIntersect(ray.NewRayAt(tt.origin, tt.direction))
+			require.Len(t, xs, len(tt.expected))
+			for i := range tt.expected {
+				assert.InDelta(t, tt.expected[i], xs[i].T, 0.05)
+				assert.Same(t, b, xs[i].Obj)
+			}
+		})
+	}
+}
+
+func TestBlob_LocalIntersect_exact(t *testing.T) {
+	b := object.NewBlob(0.5,
+		[]object.BlobComponent{object.NewBlobSphere(ray.ZeroPoint, 1, 1)},
+		object.WithBlobSamples(4),
+	)
+	xs := b.LocalIntersect(ray.NewRayAt(ray.NewPoint(0, 0, -5), ray.NewVec(0, 0, 1)))
+	require.Len(t, xs, 2)
+	surface := math.Sqrt(1 - math.Sqrt(0.5))
+	assert.InDelta(t, 5-surface, xs[0].T, 0.00001)
+	assert.InDelta(t, 5+surface, xs[1].T, 0.00001)
+}
+
+func TestBlob_LocalNormalAt(t *testing.T) {
+	b := object.NewBlob(0.5, []object.BlobComponent{
+		object.NewBlobSphere(ray.NewPoint(-0.5, 0, 0), 1, 1),
+		object.NewBlobSphere(ray.NewPoint(0.5, 0, 0), 1, 1),
+	})
+	testCases := []struct {
+		name     string
+		point    ray.Vector
+		expected ray.Vector
+	}{
+		{
+			name:     "On the x axis",
+			point:    ray.NewPoint(1.2, 0, 0),
+			expected: ray.NewVec(1, 0, 0),
+		},
+		{
+			name:     "At the seam between the components",
+			point:    ray.NewPoint(0, 0.5, 0),
+			expected: ray.NewVec(0, 1, 0),
+		},
+		{
+			name:     "Behind the components",
+			point:    ray.NewPoint(0, 0, -0.5),
+			expected: ray.NewVec(0, 0, -1),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			n := object.NormalAt(object.Intersection{Obj: b}, tt.point)
+			assertVec(t, tt.expected, n)
+		})
+	}
+}
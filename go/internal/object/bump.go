@@ -0,0 +1,69 @@
+package object
+
+import (
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+// bumpDelta is the world space step the bump map's slope is measured over.
+const bumpDelta = 1e-3
+
+// PerturbNormal tilts the world space normal of a hit by the material's
+// normal map and bump map, leaving it alone if it has neither.
+func (m Material) PerturbNormal(hit Intersection, worldPoint, normalv ray.Vector) ray.Vector {
+	if m.NormalMap != nil {
+		normalv = m.normalMapped(hit, worldPoint, normalv)
+	}
+	if m.BumpMap.IsNotEmpty && m.BumpScale != 0 {
+		normalv = m.bumped(hit.Obj, worldPoint, normalv)
+	}
+	return normalv
+}
+
+// normalMapped reads the normal out of the texture in the frame of the
+// surface's tangent, bitangent and normal.
+func (m Material) normalMapped(hit Intersection, worldPoint, normalv ray.Vector) ray.Vector {
+	uv, tangent, bitangent, ok := SurfaceUV(hit, worldPoint)
+	if !ok {
+		return normalv
+	}
+	// Gram-Schmidt so the frame stays square once the normal is interpolated
+	tangent = tangent.Subtract(normalv.Multiply(ray.Dot(normalv, tangent)))
+	if tangent.Magnitude() < epsilon {
+		return normalv
+	}
+	tangent = tangent.Normalize()
+	if ray.Dot(ray.Cross(normalv, tangent), bitangent) < 0 {
+		bitangent = ray.Cross(tangent, normalv)
+	} else {
+		bitangent = ray.Cross(normalv, tangent)
+	}
+
+	// images have v going down from the top row
+	c := m.NormalMap.Sample(uv.U, 1-uv.V)
+	n := tangent.Multiply(2*c.R - 1).
+		Add(bitangent.Multiply(2*c.G - 1)).
+		Add(normalv.Multiply(2*c.B - 1))
+	if n.Magnitude() < epsilon {
+		return normalv
+	}
+	return n.Normalize()
+}
+
+// bumped tilts the normal against the slope of the bump map's height.
+func (m Material) bumped(obj Object, worldPoint, normalv ray.Vector) ray.Vector {
+	height := func(dx, dy, dz float64) float64 {
+		return m.BumpMap.AtObj(obj, worldPoint.Add(ray.NewVec(dx, dy, dz))).Luminance()
+	}
+	gradient := ray.NewVec(
+		height(bumpDelta, 0, 0)-height(-bumpDelta, 0, 0),
+		height(0, bumpDelta, 0)-height(0, -bumpDelta, 0),
+		height(0, 0, bumpDelta)-height(0, 0, -bumpDelta),
+	).Divide(2 * bumpDelta)
+	// only the slope along the surface tilts it
+	surface := gradient.Subtract(normalv.Multiply(ray.Dot(normalv, gradient)))
+	n := normalv.Subtract(surface.Multiply(m.BumpScale))
+	if n.Magnitude() < epsilon {
+		return normalv
+	}
+	return n.Normalize()
+}
@@ -60,3 +60,8 @@ func (r RGB) Plus(c RGB) RGB {
 		r.B+c.B,
 	)
 }
+
+// Luminance is the perceived brightness of the color (Rec. 709 weights)
+func (r RGB) Luminance() float64 {
+	return 0.2126*r.R + 0.7152*r.G + 0.0722*r.B
+}
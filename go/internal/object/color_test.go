@@ -3,6 +3,8 @@ package object_test
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
 )
 
@@ -51,3 +53,10 @@ func TestBlack(t *testing.T) {
 func TestWhite(t *testing.T) {
 	assertColorEqual(t, object.NewColor(1, 1, 1), object.White)
 }
+
+func TestRGB_Luminance(t *testing.T) {
+	assert.InDelta(t, 1, object.White.Luminance(), 0.00001)
+	assert.InDelta(t, 0, object.Black.Luminance(), 0.00001)
+	assert.Greater(t, object.Green.Luminance(), object.Red.Luminance())
+	assert.Greater(t, object.Red.Luminance(), object.Blue.Luminance())
+}
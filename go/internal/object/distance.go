@@ -0,0 +1,203 @@
+package object
+
+import (
+	"math"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+func SDFSphere(radius float64) DistanceFunc {
+	return func(p ray.Vector) float64 {
+		return length(p.GetX(), p.GetY(), p.GetZ()) - radius
+	}
+}
+
+// SDFBox is a box centred on the origin with the given half extents.
+func SDFBox(x, y, z float64) DistanceFunc {
+	return SDFRoundBox(x, y, z, 0)
+}
+
+func SDFRoundBox(x, y, z, radius float64) DistanceFunc {
+	return func(p ray.Vector) float64 {
+		qx := math.Abs(p.GetX()) - x + radius
+		qy := math.Abs(p.GetY()) - y + radius
+		qz := math.Abs(p.GetZ()) - z + radius
+		outside := length(math.Max(qx, 0), math.Max(qy, 0), math.Max(qz, 0))
+		inside := math.Min(math.Max(qx, math.Max(qy, qz)), 0)
+		return outside + inside - radius
+	}
+}
+
+// SDFTorus lies in the xz plane.
+func SDFTorus(major, minor float64) DistanceFunc {
+	return func(p ray.Vector) float64 {
+		qx := math.Hypot(p.GetX(), p.GetZ()) - major
+		return math.Hypot(qx, p.GetY()) - minor
+	}
+}
+
+// SDFCylinder is capped and runs along the y axis from -halfHeight to halfHeight.
+func SDFCylinder(radius, halfHeight float64) DistanceFunc {
+	return func(p ray.Vector) float64 {
+		dx := math.Hypot(p.GetX(), p.GetZ()) - radius
+		dy := math.Abs(p.GetY()) - halfHeight
+		outside := math.Hypot(math.Max(dx, 0), math.Max(dy, 0))
+		return outside + math.Min(math.Max(dx, dy), 0)
+	}
+}
+
+func SDFCapsule(a, b ray.Vector, radius float64) DistanceFunc {
+	ba := b.Subtract(a)
+	baDot := ray.Dot(ba, ba)
+	return func(p ray.Vector) float64 {
+		pa := p.Subtract(a)
+		h := clamp(ray.Dot(pa, ba)/baDot, 0, 1)
+		return pa.Subtract(ba.Multiply(h)).SetW(0).Magnitude() - radius
+	}
+}
+
+// SDFPlane is the plane with the given normal, offset from the origin.
+func SDFPlane(normal ray.Vector, offset float64) DistanceFunc {
+	n := normal.SetW(0).Normalize()
+	return func(p ray.Vector) float64 {
+		return p.GetX()*n.GetX() + p.GetY()*n.GetY() + p.GetZ()*n.GetZ() + offset
+	}
+}
+
+// SDFMandelbulb is the distance estimate for the power N Mandelbulb. It is
+// a bound rather than an exact distance, so pair it with WithStepScale.
+func SDFMandelbulb(power float64, iterations int) DistanceFunc {
+	const bailout = 2
+	return func(p ray.Vector) float64 {
+		x, y, z := p.GetX(), p.GetY(), p.GetZ()
+		dr := 1.0
+		r := 0.0
+		for i := 0; i < iterations; i++ {
+			r = length(x, y, z)
+			if r > bailout || r == 0 {
+				break
+			}
+
+			theta := math.Acos(z/r) * power
+			phi := math.Atan2(y, x) * power
+			dr = math.Pow(r, power-1)*power*dr + 1
+
+			zr := math.Pow(r, power)
+			x = zr*math.Sin(theta)*math.Cos(phi) + p.GetX()
+			y = zr*math.Sin(theta)*math.Sin(phi) + p.GetY()
+			z = zr*math.Cos(theta) + p.GetZ()
+		}
+		if r == 0 {
+			return 0
+		}
+		return 0.5 * math.Log(r) * r / dr
+	}
+}
+
+func SDFUnion(a DistanceFunc, others ...DistanceFunc) DistanceFunc {
+	return func(p ray.Vector) float64 {
+		d := a(p)
+		for i := range others {
+			d = math.Min(d, others[i](p))
+		}
+		return d
+	}
+}
+
+// SDFSubtract carves b out of a.
+func SDFSubtract(a, b DistanceFunc) DistanceFunc {
+	return func(p ray.Vector) float64 {
+		return math.Max(a(p), -b(p))
+	}
+}
+
+func SDFIntersect(a, b DistanceFunc) DistanceFunc {
+	return func(p ray.Vector) float64 {
+		return math.Max(a(p), b(p))
+	}
+}
+
+// SDFSmoothUnion blends the two shapes together over a distance of k.
+func SDFSmoothUnion(k float64, a, b DistanceFunc) DistanceFunc {
+	return func(p ray.Vector) float64 {
+		da, db := a(p), b(p)
+		h := clamp(0.5+0.5*(db-da)/k, 0, 1)
+		return mix(db, da, h) - k*h*(1-h)
+	}
+}
+
+func SDFSmoothSubtract(k float64, a, b DistanceFunc) DistanceFunc {
+	return func(p ray.Vector) float64 {
+		da, db := a(p), b(p)
+		h := clamp(0.5-0.5*(da+db)/k, 0, 1)
+		return mix(da, -db, h) + k*h*(1-h)
+	}
+}
+
+func SDFSmoothIntersect(k float64, a, b DistanceFunc) DistanceFunc {
+	return func(p ray.Vector) float64 {
+		da, db := a(p), b(p)
+		h := clamp(0.5-0.5*(db-da)/k, 0, 1)
+		return mix(db, da, h) + k*h*(1-h)
+	}
+}
+
+// SDFRound inflates a shape by radius, rounding off its edges.
+func SDFRound(radius float64, d DistanceFunc) DistanceFunc {
+	return func(p ray.Vector) float64 {
+		return d(p) - radius
+	}
+}
+
+func SDFTranslate(x, y, z float64, d DistanceFunc) DistanceFunc {
+	offset := ray.NewVec(x, y, z)
+	return func(p ray.Vector) float64 {
+		return d(p.Subtract(offset))
+	}
+}
+
+// SDFRepeat tiles a shape infinitely with the given period on each axis.
+// A period of zero leaves that axis alone.
+func SDFRepeat(period ray.Vector, d DistanceFunc) DistanceFunc {
+	return func(p ray.Vector) float64 {
+		return d(ray.NewPoint(
+			repeat(p.GetX(), period.GetX()),
+			repeat(p.GetY(), period.GetY()),
+			repeat(p.GetZ(), period.GetZ()),
+		))
+	}
+}
+
+// SDFTwist rotates the xz plane by k radians per unit along y. The result
+// is no longer an exact distance, so pair it with WithStepScale.
+func SDFTwist(k float64, d DistanceFunc) DistanceFunc {
+	return func(p ray.Vector) float64 {
+		c := math.Cos(k * p.GetY())
+		s := math.Sin(k * p.GetY())
+		return d(ray.NewPoint(
+			c*p.GetX()-s*p.GetZ(),
+			p.GetY(),
+			s*p.GetX()+c*p.GetZ(),
+		))
+	}
+}
+
+func repeat(v, period float64) float64 {
+	if period == 0 {
+		return v
+	}
+	half := period / 2
+	return v - period*math.Floor((v+half)/period)
+}
+
+func length(x, y, z float64) float64 {
+	return math.Sqrt(x*x + y*y + z*z)
+}
+
+func mix(x, y, a float64) float64 {
+	return x*(1-a) + y*a
+}
+
+func clamp(v, min, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
@@ -0,0 +1,53 @@
+package object_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+func TestDistanceFunc(t *testing.T) {
+	sphere := object.SDFSphere(1)
+	box := object.SDFBox(1, 1, 1)
+	testCases := []struct {
+		name     string
+		distance object.DistanceFunc
+		point    ray.Vector
+		expected float64
+	}{
+		{name: "Sphere outside", distance: sphere, point: ray.NewPoint(0, 3, 0), expected: 2},
+		{name: "Sphere inside", distance: sphere, point: ray.NewPoint(0, 0, 0), expected: -1},
+		{name: "Box face", distance: box, point: ray.NewPoint(3, 0, 0), expected: 2},
+		{name: "Box corner", distance: box, point: ray.NewPoint(2, 2, 1), expected: math.Sqrt(2)},
+		{name: "Box inside", distance: box, point: ray.NewPoint(0.5, 0, 0), expected: -0.5},
+		{name: "Round box face", distance: object.SDFRoundBox(1, 1, 1, 0.25), point: ray.NewPoint(3, 0, 0), expected: 2},
+		{name: "Round box corner", distance: object.SDFRoundBox(1, 1, 1, 0.25), point: ray.NewPoint(2, 2, 0), expected: math.Sqrt(2*1.25*1.25) - 0.25},
+		{name: "Torus ring", distance: object.SDFTorus(2, 0.5), point: ray.NewPoint(2, 0, 0), expected: -0.5},
+		{name: "Torus centre", distance: object.SDFTorus(2, 0.5), point: ray.NewPoint(0, 0, 0), expected: 1.5},
+		{name: "Cylinder side", distance: object.SDFCylinder(1, 1), point: ray.NewPoint(0, 0, 3), expected: 2},
+		{name: "Cylinder cap", distance: object.SDFCylinder(1, 1), point: ray.NewPoint(0, 4, 0), expected: 3},
+		{name: "Capsule", distance: object.SDFCapsule(ray.NewPoint(0, -1, 0), ray.NewPoint(0, 1, 0), 0.5), point: ray.NewPoint(0, 3, 0), expected: 1.5},
+		{name: "Plane", distance: object.SDFPlane(ray.NewVec(0, 1, 0), 1), point: ray.NewPoint(5, 2, 5), expected: 3},
+		{name: "Union", distance: object.SDFUnion(sphere, object.SDFTranslate(3, 0, 0, sphere)), point: ray.NewPoint(2.5, 0, 0), expected: -0.5},
+		{name: "Subtract", distance: object.SDFSubtract(box, sphere), point: ray.NewPoint(0, 0, 0), expected: 1},
+		{name: "Intersect", distance: object.SDFIntersect(box, object.SDFSphere(1.2)), point: ray.NewPoint(0, 0, 0), expected: -1},
+		{name: "Smooth union far from the seam", distance: object.SDFSmoothUnion(0.1, sphere, object.SDFTranslate(5, 0, 0, sphere)), point: ray.NewPoint(0, 0, 0), expected: -1},
+		{name: "Smooth union at the seam", distance: object.SDFSmoothUnion(1, sphere, sphere), point: ray.NewPoint(0, 0, 0), expected: -1.25},
+		{name: "Smooth subtract far from the seam", distance: object.SDFSmoothSubtract(0.1, box, object.SDFTranslate(5, 0, 0, sphere)), point: ray.NewPoint(0, 0, 0), expected: -1},
+		{name: "Smooth intersect at the seam", distance: object.SDFSmoothIntersect(1, sphere, sphere), point: ray.NewPoint(0, 0, 0), expected: -0.75},
+		{name: "Round", distance: object.SDFRound(0.5, box), point: ray.NewPoint(3, 0, 0), expected: 1.5},
+		{name: "Repeat", distance: object.SDFRepeat(ray.NewVec(4, 0, 0), sphere), point: ray.NewPoint(8, 0, 0), expected: -1},
+		{name: "Repeat halfway", distance: object.SDFRepeat(ray.NewVec(4, 0, 0), sphere), point: ray.NewPoint(6, 0, 0), expected: 1},
+		{name: "Twist", distance: object.SDFTwist(math.Pi/2, object.SDFBox(2, 2, 0.5)), point: ray.NewPoint(0, 1, 1.5), expected: -0.5},
+		{name: "Mandelbulb outside", distance: object.SDFMandelbulb(8, 10), point: ray.NewPoint(0, 0, -3), expected: 1.648},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, tt.distance(tt.point), 0.01)
+		})
+	}
+}
@@ -9,12 +9,35 @@ import (
 type Group struct {
 	obj
 	Children []Object
+	// running total of the children's areas, for sampling
+	areas []float64
 }
 
 func (g *Group) AddChild(children ...Object) {
+	before := g.LocalArea()
 	g.Children = append(g.Children, children...)
 	for i := range children {
 		children[i].SetParent(g)
+		g.areas = append(g.areas, g.LocalArea()+childArea(children[i]))
+	}
+	if p, ok := g.Parent().(*Group); ok {
+		p.childGrew(g, (g.LocalArea()-before)*areaScale(g.Transform(), ray.NewVec(0, 1, 0)))
+	}
+}
+
+// childGrew keeps the areas up to date when children are added to a group
+// that is already in this one.
+func (g *Group) childGrew(child Object, delta float64) {
+	for i := range g.Children {
+		if g.Children[i] == child {
+			for j := i; j < len(g.areas); j++ {
+				g.areas[j] += delta
+			}
+			break
+		}
+	}
+	if p, ok := g.Parent().(*Group); ok {
+		p.childGrew(g, delta*areaScale(g.Transform(), ray.NewVec(0, 1, 0)))
 	}
 }
 
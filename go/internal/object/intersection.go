@@ -4,6 +4,7 @@ type Intersection struct {
 	T    float64 // Intersection value
 	Obj  Object  // Intersected object
 	U, V float64 // UV value between 0 - 1
+	Time float64 // When the ray hit, for moving objects
 }
 
 type Intersections []Intersection
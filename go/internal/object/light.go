@@ -25,10 +25,24 @@ func Lighting(
 	position, eyev, normalv ray.Vector,
 	inShadows bool) RGB {
 
-	color := material.Color
-	if material.Pattern.IsNotEmpty {
-		color = material.Pattern.AtObj(obj, position)
+	transmittance := White
+	if inShadows {
+		transmittance = Black
 	}
+	return LightingThrough(material, obj, light, position, eyev, normalv, transmittance)
+}
+
+// LightingThrough is Lighting for a light that reaches the point dimmed and
+// tinted by transmittance, say through coloured glass. Ambient light is
+// left alone.
+func LightingThrough(
+	material Material,
+	obj Object,
+	light PointLight,
+	position, eyev, normalv ray.Vector,
+	transmittance RGB) RGB {
+
+	color := material.ColorAt(obj, position)
 	// combine the surface color with the light's color/intensity
 	// effective color
 	ec := color.Multiply(light.Intensity)
@@ -39,12 +53,25 @@ func Lighting(
 	// compute the ambient contribution
 	ambient := ec.MultiplyBy(material.Ambient)
 
-	if inShadows {
+	if transmittance == Black {
 		return ambient
 	}
+	// the light that makes it to the point
+	intensity := light.Intensity.Multiply(transmittance)
+	ec = color.Multiply(intensity)
 
 	ldn := ray.Dot(lightv, normalv)
 
+	if material.PBR != nil {
+		if ldn <= 0 {
+			return ambient
+		}
+		// point lights have no fall off, π keeps a white lambertian surface
+		// facing the light as bright as Phong's diffuse
+		brdf := material.BRDF(obj, position, normalv, eyev, lightv)
+		return ambient.Plus(brdf.Multiply(intensity).MultiplyBy(math.Pi * ldn))
+	}
+
 	diffuse := RGB{R: 0, G: 0, B: 0}
 	specular := RGB{R: 0, G: 0, B: 0}
 	if ldn >= 0 {
@@ -57,7 +84,7 @@ func Lighting(
 
 		if rde > 0 {
 			factor := math.Pow(rde, material.Shininess)
-			specular = light.Intensity.MultiplyBy(material.Specular).MultiplyBy(factor)
+			specular = intensity.MultiplyBy(material.Specular).MultiplyBy(factor)
 		}
 	}
 	return ambient.Plus(diffuse).Plus(specular)
@@ -112,3 +112,27 @@ func assertColorEqual(t *testing.T, expected object.RGB, actual object.RGB) {
 	assert.InDelta(t, expected.G, actual.G, 0.00001, "G")
 	assert.InDelta(t, expected.B, actual.B, 0.00001, "B")
 }
+
+func TestLightingThrough(t *testing.T) {
+	m := object.DefaultMaterial()
+	obj := object.NewSphere(ray.ZeroPoint, 1)
+	eyev := ray.NewVec(0, 0, -1)
+	normalv := ray.NewVec(0, 0, -1)
+	light := object.NewPointLight(ray.NewPoint(0, 0, -10), object.NewColor(1, 1, 1))
+
+	testCases := []struct {
+		name          string
+		transmittance object.RGB
+		expected      object.RGB
+	}{
+		{name: "A clear path lights as normal", transmittance: object.White, expected: object.NewColor(1.9, 1.9, 1.9)},
+		{name: "A blocked path leaves only ambient", transmittance: object.Black, expected: object.NewColor(0.1, 0.1, 0.1)},
+		{name: "Coloured glass tints the light", transmittance: object.NewColor(1, 0.5, 0), expected: object.NewColor(1.9, 1, 0.1)},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := object.LightingThrough(m, obj, light, ray.ZeroPoint, eyev, normalv, tt.transmittance)
+			assertColorEqual(t, tt.expected, actual)
+		})
+	}
+}
@@ -1,5 +1,11 @@
 package object
 
+import (
+	"math"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
 const (
 	defaultRGB                     = float64(1)
 	defaultMaterialAmbient         = 0.1
@@ -9,26 +15,59 @@ const (
 	defaultMaterialReflective      = 0.0
 	defaultMaterialTransparency    = 0.0
 	defaultMaterialRefractiveIndex = 1.0
+	defaultMaterialGlossySamples   = 8
+	defaultMaterialEmission        = 1.0
+
+	// Fraunhofer lines, in nanometres, that the Abbe number is defined with
+	WavelengthC = 656.3
+	WavelengthD = 587.6
+	WavelengthF = 486.1
 )
 
 type Material struct {
 	Color                                                                            RGB
 	Pattern                                                                          Pattern
 	Ambient, Diffuse, Specular, Shininess, Reflective, Transparency, RefractiveIndex float64
+	// Roughness blurs reflection and refraction by spreading GlossySamples
+	// rays over a cone, 0 is a perfect mirror and 1 the whole hemisphere.
+	Roughness     float64
+	GlossySamples int
+	// light travelling AbsorptionDistance through the material is tinted
+	// Absorption, zero distance means it doesn't absorb
+	Absorption         RGB
+	AbsorptionDistance float64
+	// Abbe number for dispersion, the lower it is the more colours split
+	// apart, zero disables dispersion
+	Abbe float64
+	// Emission is the light the surface gives off, from both sides, scaled
+	// by EmissionStrength. Zero strength counts as 1 so a material with just
+	// Emission set glows, turn it off with a black Emission instead.
+	Emission         RGB
+	EmissionStrength float64
+	// PBR switches shading from Phong to a metallic-roughness model when set
+	PBR *PBR
+	// NormalMap is a tangent space normal map looked up by the shape's UVs
+	NormalMap Texture
+	// BumpMap raises the surface by the luminance of the pattern times
+	// BumpScale, tilting the normal without changing the geometry
+	BumpMap   Pattern
+	BumpScale float64
 }
 
 func NewMaterial(color RGB,
 	ambient, diffuse, specular, shininess, reflective, transparency, refractiveIndex float64) Material {
 	return Material{
-		Pattern:         Pattern{},
-		Color:           color,
-		Ambient:         ambient,
-		Diffuse:         diffuse,
-		Specular:        specular,
-		Shininess:       shininess,
-		Reflective:      reflective,
-		Transparency:    transparency,
-		RefractiveIndex: refractiveIndex,
+		Pattern:          Pattern{},
+		Color:            color,
+		Ambient:          ambient,
+		Diffuse:          diffuse,
+		Specular:         specular,
+		Shininess:        shininess,
+		Reflective:       reflective,
+		Transparency:     transparency,
+		RefractiveIndex:  refractiveIndex,
+		GlossySamples:    defaultMaterialGlossySamples,
+		EmissionStrength: defaultMaterialEmission,
 	}
 }
 
@@ -44,3 +83,59 @@ func DefaultMaterial() Material {
 		defaultMaterialRefractiveIndex,
 	)
 }
+
+// ColorAt is the surface color at a point, taking the pattern into account.
+func (m Material) ColorAt(obj Object, worldPoint ray.Vector) RGB {
+	if m.Pattern.IsNotEmpty {
+		return m.Pattern.AtObj(obj, worldPoint)
+	}
+	return m.Color
+}
+
+// Emitted is the radiance the surface gives off.
+func (m Material) Emitted() RGB {
+	strength := m.EmissionStrength
+	if strength == 0 {
+		strength = defaultMaterialEmission
+	}
+	return m.Emission.MultiplyBy(strength)
+}
+
+func (m Material) IsEmissive() bool {
+	e := m.Emitted()
+	return e.R > 0 || e.G > 0 || e.B > 0
+}
+
+// Transmittance is the fraction of light per channel surviving distance
+// through the material (Beer-Lambert).
+func (m Material) Transmittance(distance float64) RGB {
+	if m.AbsorptionDistance <= 0 {
+		return White
+	}
+	scale := distance / m.AbsorptionDistance
+	return NewColor(
+		math.Pow(m.Absorption.R, scale),
+		math.Pow(m.Absorption.G, scale),
+		math.Pow(m.Absorption.B, scale),
+	)
+}
+
+func (m Material) Disperses() bool {
+	return m.Abbe > 0 && m.Transparency > 0
+}
+
+// IndexAt is the refractive index for a wavelength in nanometres, from
+// Cauchy's equation fitted to RefractiveIndex and Abbe. A zero wavelength
+// gets RefractiveIndex.
+func (m Material) IndexAt(wavelength float64) float64 {
+	if m.Abbe <= 0 || wavelength <= 0 {
+		return m.RefractiveIndex
+	}
+	inverseSquare := func(nm float64) float64 {
+		um := nm / 1000
+		return 1 / (um * um)
+	}
+	b := (m.RefractiveIndex - 1) / (m.Abbe * (inverseSquare(WavelengthF) - inverseSquare(WavelengthC)))
+	a := m.RefractiveIndex - b*inverseSquare(WavelengthD)
+	return a + b*inverseSquare(wavelength)
+}
@@ -1,11 +1,13 @@
 package object_test
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
 )
 
 func TestDefaultMaterial(t *testing.T) {
@@ -18,5 +20,119 @@ func TestDefaultMaterial(t *testing.T) {
 	assert.Equal(t, 0.0, material.Reflective)
 	assert.Equal(t, 0.0, material.Transparency)
 	assert.Equal(t, 1.0, material.RefractiveIndex)
+	assert.Equal(t, 0.0, material.Roughness)
+	assert.Equal(t, 8, material.GlossySamples)
 	assert.Equal(t, object.RGB{R: 1, G: 1, B: 1}, material.Color)
 }
+
+func TestMaterial_Transmittance(t *testing.T) {
+	m := object.DefaultMaterial()
+	assert.Equal(t, object.White, m.Transmittance(10), "no absorption by default")
+
+	m.Absorption = object.NewColor(0.5, 1, 0.25)
+	m.AbsorptionDistance = 2
+	testCases := []struct {
+		distance float64
+		expected object.RGB
+	}{
+		{distance: 0, expected: object.White},
+		{distance: 2, expected: object.NewColor(0.5, 1, 0.25)},
+		{distance: 4, expected: object.NewColor(0.25, 1, 0.0625)},
+		{distance: 1, expected: object.NewColor(math.Sqrt(0.5), 1, 0.5)},
+	}
+	for _, tt := range testCases {
+		assertColorEqual(t, tt.expected, m.Transmittance(tt.distance))
+	}
+}
+
+func TestMaterial_IndexAt(t *testing.T) {
+	m := object.DefaultMaterial()
+	m.RefractiveIndex = 1.5168
+	assert.Equal(t, 1.5168, m.IndexAt(object.WavelengthF), "no dispersion without an Abbe number")
+	assert.False(t, m.Disperses())
+
+	m.Abbe = 64.17
+	m.Transparency = 1
+	assert.True(t, m.Disperses())
+	assert.Equal(t, 1.5168, m.IndexAt(0))
+	assert.InDelta(t, 1.5168, m.IndexAt(object.WavelengthD), 1e-9)
+	assert.InDelta(t, (1.5168-1)/64.17, m.IndexAt(object.WavelengthF)-m.IndexAt(object.WavelengthC), 1e-9)
+	assert.Greater(t, m.IndexAt(450), m.IndexAt(650), "blue bends more than red")
+}
+
+func TestMaterial_Emitted(t *testing.T) {
+	m := object.DefaultMaterial()
+	assert.False(t, m.IsEmissive())
+	assert.Equal(t, object.Black, m.Emitted())
+
+	m.Emission = object.NewColor(1, 0.5, 0)
+	m.EmissionStrength = 4
+	assert.True(t, m.IsEmissive())
+	assert.Equal(t, object.NewColor(4, 2, 0), m.Emitted())
+
+	plain := object.Material{Emission: object.NewColor(1, 0.5, 0)}
+	assert.True(t, plain.IsEmissive(), "no strength is the same as 1")
+	assert.Equal(t, object.NewColor(1, 0.5, 0), plain.Emitted())
+}
+
+type flatTexture object.RGB
+
+func (f flatTexture) Sample(_, _ float64) object.RGB {
+	return object.RGB(f)
+}
+
+func TestMaterial_PerturbNormal(t *testing.T) {
+	up := ray.NewVec(0, 1, 0)
+	point := ray.NewPoint(0.5, 0, 0.5)
+
+	testCases := []struct {
+		name     string
+		material func(m *object.Material)
+		expected ray.Vector
+	}{
+		{
+			name:     "default leaves the normal alone",
+			material: func(m *object.Material) {},
+			expected: up,
+		},
+		{
+			name: "flat bump map",
+			material: func(m *object.Material) {
+				m.BumpMap = object.NewStripePattern(object.White, object.White)
+				m.BumpScale = 1
+			},
+			expected: up,
+		},
+		{
+			name: "bump map tilts away from the slope",
+			material: func(m *object.Material) {
+				m.BumpMap = object.NewGradientPattern(object.Black, object.White)
+				m.BumpScale = 1
+			},
+			expected: ray.NewVec(-1, 1, 0).Normalize(),
+		},
+		{
+			name: "straight up normal map",
+			material: func(m *object.Material) {
+				m.NormalMap = flatTexture(object.NewColor(0.5, 0.5, 1))
+			},
+			expected: up,
+		},
+		{
+			name: "normal map tilts toward the tangent",
+			material: func(m *object.Material) {
+				m.NormalMap = flatTexture(object.NewColor(0.75, 0.5, 0.75))
+			},
+			expected: ray.NewVec(1, 1, 0).Normalize(),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			m := object.DefaultMaterial()
+			tt.material(&m)
+			p := object.NewPlane(object.WithMaterial(m))
+			actual := m.PerturbNormal(object.Intersection{Obj: p}, point, up)
+			assertVec(t, tt.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package object
+
+import (
+	"sort"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+// Keyframe is where an object is at a moment while the shutter is open.
+type Keyframe struct {
+	Time      float64
+	Transform ray.Matrix
+}
+
+// WithMotion moves an object from one transform at time 0 to another at
+// time 1, blurred across the camera's shutter.
+func WithMotion(from, to ray.Matrix) Option {
+	return WithKeyframes(Keyframe{Time: 0, Transform: from}, Keyframe{Time: 1, Transform: to})
+}
+
+// WithKeyframes moves an object through the transforms in time order.
+func WithKeyframes(keys ...Keyframe) Option {
+	return OptionFunc(func(o Object) {
+		_ = o.SetKeyframes(keys...)
+	})
+}
+
+func (o obj) Keyframes() []Keyframe {
+	return o.keys
+}
+
+// SetKeyframes moves the object between the transforms over time, staying
+// at the first before it and the last after. The object's Transform is the
+// first, for anything that doesn't know the time.
+func (o *obj) SetKeyframes(keys ...Keyframe) error {
+	o.keys = nil
+	if len(keys) == 0 {
+		return nil
+	}
+	sorted := make([]Keyframe, len(keys))
+	copy(sorted, keys)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time < sorted[j].Time
+	})
+	for i := range sorted {
+		if _, err := sorted[i].Transform.Inverse(); err != nil {
+			return err
+		}
+	}
+	if err := o.SetTransform(sorted[0].Transform); err != nil {
+		return err
+	}
+	if len(sorted) > 1 {
+		o.keys = sorted
+	}
+	return nil
+}
+
+func (o obj) TransformAt(time float64) ray.Matrix {
+	if o.keys == nil {
+		return o.t
+	}
+	i := sort.Search(len(o.keys), func(i int) bool {
+		return o.keys[i].Time > time
+	})
+	switch i {
+	case 0:
+		return o.keys[0].Transform
+	case len(o.keys):
+		return o.keys[i-1].Transform
+	}
+	from, to := o.keys[i-1], o.keys[i]
+	return ray.Interpolate(from.Transform, to.Transform, (time-from.Time)/(to.Time-from.Time))
+}
+
+func (o obj) TransformInverseAt(time float64) ray.Matrix {
+	if o.keys == nil {
+		return o.tInv
+	}
+	// the keyframes have inverses so the blends between them do too
+	inverse, _ := o.TransformAt(time).Inverse()
+	return inverse
+}
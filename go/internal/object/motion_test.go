@@ -0,0 +1,91 @@
+package object_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray/raytest"
+)
+
+func TestObj_TransformAt(t *testing.T) {
+	s := object.DefaultSphere()
+	require.NoError(t, s.SetKeyframes(
+		object.Keyframe{Time: 1, Transform: ray.Translation(2, 0, 0)},
+		object.Keyframe{Time: 0, Transform: ray.Translation(0, 0, 0)},
+		object.Keyframe{Time: 2, Transform: ray.Translation(2, 4, 0)},
+	))
+	assert.Equal(t, ray.Translation(0, 0, 0), s.Transform(), "starts at the first keyframe")
+	require.Len(t, s.Keyframes(), 3)
+
+	testCases := []struct {
+		name     string
+		time     float64
+		expected ray.Matrix
+	}{
+		{name: "before the first keyframe", time: -1, expected: ray.Translation(0, 0, 0)},
+		{name: "on a keyframe", time: 1, expected: ray.Translation(2, 0, 0)},
+		{name: "between keyframes", time: 0.25, expected: ray.Translation(0.5, 0, 0)},
+		{name: "between later keyframes", time: 1.5, expected: ray.Translation(2, 2, 0)},
+		{name: "after the last keyframe", time: 3, expected: ray.Translation(2, 4, 0)},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			raytest.AssertMatrixEqual(t, tt.expected, s.TransformAt(tt.time))
+			inverse, err := tt.expected.Inverse()
+			require.NoError(t, err)
+			raytest.AssertMatrixEqual(t, inverse, s.TransformInverseAt(tt.time))
+		})
+	}
+
+	t.Run("still objects are always where they are", func(t *testing.T) {
+		s := object.DefaultSphere(object.WithTransform(ray.Translation(1, 2, 3)))
+		assert.Equal(t, ray.Translation(1, 2, 3), s.TransformAt(0.5))
+		assert.Nil(t, s.Keyframes())
+	})
+
+	t.Run("keyframes must be invertible", func(t *testing.T) {
+		s := object.DefaultSphere()
+		assert.Error(t, s.SetKeyframes(
+			object.Keyframe{Time: 0, Transform: ray.DefaultIdentityMatrix()},
+			object.Keyframe{Time: 1, Transform: ray.Scaling(0, 1, 1)},
+		))
+	})
+}
+
+func TestIntersect_moving(t *testing.T) {
+	s := object.DefaultSphere(object.WithMotion(ray.Translation(0, 0, 0), ray.Translation(4, 0, 0)))
+	r := ray.NewRayAt(ray.NewPoint(2, 0, -5), ray.NewVec(0, 0, 1))
+
+	t.Run("misses where the sphere was", func(t *testing.T) {
+		assert.Empty(t, object.Intersect(s, r))
+	})
+
+	t.Run("hits where the sphere is at the ray's time", func(t *testing.T) {
+		r.SetTime(0.5)
+		xs := object.Intersect(s, r)
+		require.Len(t, xs, 2)
+		assert.InDelta(t, 4, xs[0].T, 1e-9)
+		assert.Equal(t, 0.5, xs[0].Time)
+		assertVec(t, ray.NewVec(0, 0, -1), object.NormalAt(xs[0], r.PointAt(xs[0].T)))
+	})
+
+	t.Run("children move with their group", func(t *testing.T) {
+		g := object.NewGroup(
+			object.WithKeyframes(
+				object.Keyframe{Time: 0, Transform: ray.DefaultIdentityMatrix()},
+				object.Keyframe{Time: 1, Transform: ray.Rotation(ray.Y, math.Pi/2)}),
+			object.WithChildren(object.DefaultSphere(object.WithTransform(ray.Translation(0, 0, -3)))))
+		r := ray.NewRayAt(ray.NewPoint(-10, 0, 0), ray.NewVec(1, 0, 0))
+		assert.Empty(t, object.Intersect(&g, r))
+		r.SetTime(1)
+		xs := object.Intersect(&g, r)
+		require.Len(t, xs, 2)
+		assert.InDelta(t, 6, xs[0].T, 1e-9)
+		assertVec(t, ray.NewVec(-1, 0, 0), object.NormalAt(xs[0], r.PointAt(xs[0].T)))
+	})
+}
@@ -0,0 +1,46 @@
+package object_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+func TestNoise(t *testing.T) {
+	t.Run("zero on the lattice", func(t *testing.T) {
+		assert.Equal(t, 0.0, object.Noise(0, 0, 0))
+		assert.Equal(t, 0.0, object.Noise(3, -7, 12))
+	})
+
+	t.Run("repeatable and in range", func(t *testing.T) {
+		varies := false
+		for i := 0; i < 1000; i++ {
+			x, y, z := float64(i)*0.137, float64(i)*-0.291, float64(i)*0.053
+			n := object.Noise(x, y, z)
+			assert.Equal(t, n, object.Noise(x, y, z))
+			assert.GreaterOrEqual(t, n, -1.0)
+			assert.LessOrEqual(t, n, 1.0)
+			varies = varies || n != 0
+		}
+		assert.True(t, varies)
+	})
+
+	t.Run("smooth", func(t *testing.T) {
+		a := object.Noise(0.5, 0.25, 0.75)
+		b := object.Noise(0.5+1e-6, 0.25, 0.75)
+		assert.InDelta(t, a, b, 1e-4)
+	})
+}
+
+func TestNewNoisePattern(t *testing.T) {
+	p := object.NewNoisePattern(object.Black, object.White)
+	assert.True(t, p.IsNotEmpty)
+	assert.Equal(t, object.NewColor(0.5, 0.5, 0.5), p.At(ray.ZeroPoint))
+	c := p.At(ray.NewPoint(0.3, 1.7, -2.2))
+	assert.Equal(t, c.R, c.G)
+	assert.GreaterOrEqual(t, c.R, 0.0)
+	assert.LessOrEqual(t, c.R, 1.0)
+}
@@ -15,6 +15,12 @@ type Object interface {
 	Transform() ray.Matrix
 	TransformInverse() ray.Matrix
 	SetTransform(t ray.Matrix) error
+	// TransformAt is the transform at a time during the shutter, for
+	// objects that move.
+	TransformAt(time float64) ray.Matrix
+	TransformInverseAt(time float64) ray.Matrix
+	Keyframes() []Keyframe
+	SetKeyframes(keys ...Keyframe) error
 
 	Material() (m Material)
 	SetMaterial(m Material)
@@ -24,18 +30,30 @@ type Object interface {
 
 	WorldToObject(worldPoint ray.Vector) (point ray.Vector)
 	NormalToWorld(normalVector ray.Vector) (vector ray.Vector)
+	WorldToObjectAt(worldPoint ray.Vector, time float64) (point ray.Vector)
+	NormalToWorldAt(normalVector ray.Vector, time float64) (vector ray.Vector)
+
+	CastsShadow() bool
+	ReceivesShadow() bool
+	SetShadows(casts, receives bool)
 }
 
 func NormalAt(xs Intersection, worldPoint ray.Vector) ray.Vector {
-	localPoint := xs.Obj.WorldToObject(worldPoint)
+	localPoint := xs.Obj.WorldToObjectAt(worldPoint, xs.Time)
 	localNormal := xs.Obj.LocalNormalAt(localPoint, xs)
-	return xs.Obj.NormalToWorld(localNormal)
+	return xs.Obj.NormalToWorldAt(localNormal, xs.Time)
 }
 
 func Intersect(obj Object, rr ray.Ray) Intersections {
-	return obj.
+	xs := obj.
 		LocalIntersect(
-			rr.Transform(obj.TransformInverse()))
+			rr.Transform(obj.TransformInverseAt(rr.Time())))
+	if t := rr.Time(); t != 0 {
+		for i := range xs {
+			xs[i].Time = t
+		}
+	}
+	return xs
 }
 
 type BasicObject struct {
@@ -48,6 +66,10 @@ type obj struct {
 	tInv ray.Matrix
 	m    Material
 	p    Object
+	// transforms over time for moving objects, nil when still
+	keys []Keyframe
+	// flipped so a zero obj casts and receives shadows
+	noCast, noReceive bool
 }
 
 func (o obj) LocalNormalAt(worldPoint ray.Vector, _ Intersection) ray.Vector {
@@ -104,19 +126,48 @@ func (o *obj) SetMaterial(m Material) {
 	o.m = m
 }
 
+// CastsShadow is false for helper objects that shouldn't block light, an
+// object in a group that doesn't cast shadows doesn't either.
+func (o obj) CastsShadow() bool {
+	if o.p != nil && !o.p.CastsShadow() {
+		return false
+	}
+	return !o.noCast
+}
+
+func (o obj) ReceivesShadow() bool {
+	if o.p != nil && !o.p.ReceivesShadow() {
+		return false
+	}
+	return !o.noReceive
+}
+
+func (o *obj) SetShadows(casts, receives bool) {
+	o.noCast = !casts
+	o.noReceive = !receives
+}
+
 func (o obj) WorldToObject(worldPoint ray.Vector) (point ray.Vector) {
+	return o.WorldToObjectAt(worldPoint, 0)
+}
+
+func (o obj) NormalToWorld(normalVector ray.Vector) (resultVector ray.Vector) {
+	return o.NormalToWorldAt(normalVector, 0)
+}
+
+func (o obj) WorldToObjectAt(worldPoint ray.Vector, time float64) (point ray.Vector) {
 	if o.p != nil {
-		worldPoint = o.p.WorldToObject(worldPoint)
+		worldPoint = o.p.WorldToObjectAt(worldPoint, time)
 	}
 
 	return o.
-		tInv.
+		TransformInverseAt(time).
 		MultiplyByVector(worldPoint)
 }
 
-func (o obj) NormalToWorld(normaVector ray.Vector) (resultVector ray.Vector) {
+func (o obj) NormalToWorldAt(normaVector ray.Vector, time float64) (resultVector ray.Vector) {
 	resultVector = o.
-		tInv.
+		TransformInverseAt(time).
 		Transpose().
 		MultiplyByVector(normaVector).
 		SetW(0).
@@ -124,7 +175,7 @@ func (o obj) NormalToWorld(normaVector ray.Vector) (resultVector ray.Vector) {
 
 	if o.p != nil {
 		resultVector = o.p.
-			NormalToWorld(resultVector)
+			NormalToWorldAt(resultVector, time)
 	}
 	return resultVector
 }
@@ -114,3 +114,19 @@ func TestObj_NormalToWorld(t *testing.T) {
 	p := s.NormalToWorld(ray.NewVec(math.Sqrt(3)/3, math.Sqrt(3)/3, math.Sqrt(3)/3))
 	assertVec(t, ray.NewVec(0.28571, 0.42857, -0.85714), p)
 }
+
+func TestObject_Shadows(t *testing.T) {
+	s := object.DefaultSphere()
+	assert.True(t, s.CastsShadow())
+	assert.True(t, s.ReceivesShadow())
+
+	backdrop := object.NewPlane(object.WithShadows(false, true))
+	assert.False(t, backdrop.CastsShadow())
+	assert.True(t, backdrop.ReceivesShadow())
+
+	child := object.DefaultSphere()
+	g := object.NewGroup(object.WithShadows(true, false))
+	g.AddChild(child)
+	assert.True(t, child.CastsShadow())
+	assert.False(t, child.ReceivesShadow(), "children follow their group")
+}
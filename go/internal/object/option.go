@@ -18,6 +18,14 @@ func WithTransform(t ray.Matrix) Option {
 	})
 }
 
+// WithShadows sets whether the object casts shadows on others and has
+// shadows cast on it.
+func WithShadows(casts, receives bool) Option {
+	return OptionFunc(func(o Object) {
+		o.SetShadows(casts, receives)
+	})
+}
+
 func WithMaterial(m Material) Option {
 	return OptionFunc(func(o Object) {
 		o.SetMaterial(m)
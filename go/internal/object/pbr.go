@@ -0,0 +1,116 @@
+package object
+
+import (
+	"math"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+const (
+	defaultPBRSpecular = 0.04
+	// perfectly smooth GGX is a delta, keep a little roughness so it can be lit by point lights
+	minRoughness = 0.03
+)
+
+// PBR turns a material into a metallic-roughness material shaded with a
+// Cook-Torrance GGX microfacet BRDF. The base colour comes from the
+// material's Color or Pattern. Specular is the reflectance at normal
+// incidence (F0) of the non metallic parts.
+type PBR struct {
+	Metallic, Roughness, Specular float64
+	// patterns override Metallic and Roughness using the luminance of their color
+	MetallicPattern, RoughnessPattern Pattern
+}
+
+func NewPBR(metallic, roughness float64) *PBR {
+	return &PBR{
+		Metallic:  metallic,
+		Roughness: roughness,
+		Specular:  defaultPBRSpecular,
+	}
+}
+
+// NewPBRMaterial is the default material using a metallic-roughness model.
+func NewPBRMaterial(baseColor RGB, metallic, roughness float64) Material {
+	m := DefaultMaterial()
+	m.Color = baseColor
+	m.PBR = NewPBR(metallic, roughness)
+	return m
+}
+
+func (p *PBR) MetallicAt(obj Object, worldPoint ray.Vector) float64 {
+	if p.MetallicPattern.IsNotEmpty {
+		return clamp(p.MetallicPattern.AtObj(obj, worldPoint).Luminance(), 0, 1)
+	}
+	return clamp(p.Metallic, 0, 1)
+}
+
+func (p *PBR) RoughnessAt(obj Object, worldPoint ray.Vector) float64 {
+	if p.RoughnessPattern.IsNotEmpty {
+		return clamp(p.RoughnessPattern.AtObj(obj, worldPoint).Luminance(), minRoughness, 1)
+	}
+	return clamp(p.Roughness, minRoughness, 1)
+}
+
+// Alpha is the GGX width for a perceptual roughness.
+func Alpha(roughness float64) float64 {
+	return roughness * roughness
+}
+
+// GGX is the Trowbridge-Reitz distribution of microfacet normals, ndh is the
+// cosine between the half vector and the normal.
+func GGX(ndh, alpha float64) float64 {
+	if ndh <= 0 {
+		return 0
+	}
+	a2 := alpha * alpha
+	d := ndh*ndh*(a2-1) + 1
+	return a2 / (math.Pi * d * d)
+}
+
+// smithG1 is the fraction of microfacets seen from a direction at cos to the normal.
+func smithG1(cos, alpha float64) float64 {
+	if cos <= 0 {
+		return 0
+	}
+	a2 := alpha * alpha
+	return 2 * cos / (cos + math.Sqrt(a2+(1-a2)*cos*cos))
+}
+
+func fresnelSchlick(cos float64, f0 RGB) RGB {
+	f := math.Pow(1-clamp(cos, 0, 1), 5)
+	return f0.Add(White.Subtract(f0).MultiplyBy(f))
+}
+
+// CookTorrance is the BRDF of a metallic-roughness surface for light
+// arriving along lightv and leaving along eyev.
+func CookTorrance(base RGB, metallic, roughness, specular float64, normalv, eyev, lightv ray.Vector) RGB {
+	ndl := ray.Dot(normalv, lightv)
+	ndv := ray.Dot(normalv, eyev)
+	if ndl <= 0 || ndv <= 0 {
+		return Black
+	}
+	h := lightv.Add(eyev).Normalize()
+	ndh := ray.Dot(normalv, h)
+	vdh := ray.Dot(eyev, h)
+
+	// metals tint their reflection and have no diffuse
+	f0 := NewColor(specular, specular, specular).MultiplyBy(1 - metallic).Add(base.MultiplyBy(metallic))
+	alpha := Alpha(roughness)
+	f := fresnelSchlick(vdh, f0)
+	g := smithG1(ndl, alpha) * smithG1(ndv, alpha)
+	spec := f.MultiplyBy(GGX(ndh, alpha) * g / (4 * ndl * ndv))
+
+	diffuse := White.Subtract(f).Multiply(base).MultiplyBy((1 - metallic) / math.Pi)
+	return diffuse.Add(spec)
+}
+
+// BRDF is the reflectance of a material with a PBR model at a point.
+func (m Material) BRDF(obj Object, worldPoint, normalv, eyev, lightv ray.Vector) RGB {
+	return CookTorrance(
+		m.ColorAt(obj, worldPoint),
+		m.PBR.MetallicAt(obj, worldPoint),
+		m.PBR.RoughnessAt(obj, worldPoint),
+		m.PBR.Specular,
+		normalv, eyev, lightv)
+}
@@ -0,0 +1,139 @@
+package object_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+func TestNewPBRMaterial(t *testing.T) {
+	m := object.NewPBRMaterial(object.Red, 1, 0.5)
+	assert.Equal(t, object.Red, m.Color)
+	assert.Equal(t, 1.0, m.PBR.Metallic)
+	assert.Equal(t, 0.5, m.PBR.Roughness)
+	assert.Equal(t, 0.04, m.PBR.Specular)
+	assert.Nil(t, object.DefaultMaterial().PBR)
+}
+
+func TestPBR_PatternsDriveParameters(t *testing.T) {
+	p := object.NewPBR(0.3, 0.6)
+	p.MetallicPattern = object.NewStripePattern(object.White, object.Black)
+	p.RoughnessPattern = object.NewStripePattern(object.Black, object.White)
+	obj := object.NewSphere(ray.ZeroPoint, 1)
+
+	assert.Equal(t, 1.0, p.MetallicAt(obj, ray.NewPoint(0.5, 0, 0)))
+	assert.Equal(t, 0.0, p.MetallicAt(obj, ray.NewPoint(1.5, 0, 0)))
+	assert.Equal(t, 0.03, p.RoughnessAt(obj, ray.NewPoint(0.5, 0, 0)))
+	assert.Equal(t, 1.0, p.RoughnessAt(obj, ray.NewPoint(1.5, 0, 0)))
+
+	plain := object.NewPBR(0.3, 0)
+	assert.Equal(t, 0.3, plain.MetallicAt(obj, ray.ZeroPoint))
+	assert.Equal(t, 0.03, plain.RoughnessAt(obj, ray.ZeroPoint), "roughness never reaches zero")
+}
+
+// integrate over the hemisphere around +z with a midpoint rule
+func integrateHemisphere(f func(direction ray.Vector) float64) (sum float64) {
+	const steps = 400
+	dTheta := math.Pi / 2 / steps
+	dPhi := 2 * math.Pi / steps
+	for i := 0; i < steps; i++ {
+		theta := (float64(i) + 0.5) * dTheta
+		for j := 0; j < steps; j++ {
+			phi := (float64(j) + 0.5) * dPhi
+			direction := ray.NewVec(math.Sin(theta)*math.Cos(phi), math.Sin(theta)*math.Sin(phi), math.Cos(theta))
+			sum += f(direction) * math.Sin(theta) * dTheta * dPhi
+		}
+	}
+	return sum
+}
+
+func TestGGX(t *testing.T) {
+	normal := ray.NewVec(0, 0, 1)
+	for _, roughness := range []float64{0.3, 0.5, 1} {
+		alpha := object.Alpha(roughness)
+		projected := integrateHemisphere(func(h ray.Vector) float64 {
+			return object.GGX(ray.Dot(normal, h), alpha) * ray.Dot(normal, h)
+		})
+		assert.InDelta(t, 1, projected, 0.01, "roughness %v", roughness)
+	}
+	assert.Equal(t, 0.0, object.GGX(-0.5, 0.25))
+}
+
+func TestCookTorrance(t *testing.T) {
+	normal := ray.NewVec(0, 0, 1)
+	eye := ray.NewVec(0, 0, 1)
+
+	t.Run("Light from behind the surface is black", func(t *testing.T) {
+		actual := object.CookTorrance(object.White, 0, 0.5, 0.04, normal, eye, ray.NewVec(0, 0, -1))
+		assert.Equal(t, object.Black, actual)
+	})
+
+	t.Run("Without specular a dielectric is lambertian", func(t *testing.T) {
+		actual := object.CookTorrance(object.Red, 0, 0.5, 0, normal, eye, ray.NewVec(0, 0.6, 0.8))
+		assertColorEqual(t, object.NewColor(1/math.Pi, 0, 0), actual)
+	})
+
+	t.Run("A metal has no diffuse and tints its reflection", func(t *testing.T) {
+		actual := object.CookTorrance(object.Red, 1, 0.2, 0.04, normal, eye, ray.NewVec(0, 0.8, 0.6))
+		assert.Less(t, actual.R, 0.01)
+		assert.InDelta(t, 0, actual.G, 0.00001)
+		peak := object.CookTorrance(object.Red, 1, 0.2, 0.04, normal, eye, eye)
+		assert.Greater(t, peak.R, 1.0)
+		assert.InDelta(t, 0, peak.G, 0.00001)
+	})
+
+	t.Run("A rough white metal reflects no more light than arrives", func(t *testing.T) {
+		albedo := integrateHemisphere(func(l ray.Vector) float64 {
+			return object.CookTorrance(object.White, 1, 0.5, 0.04, normal, eye, l).R * ray.Dot(normal, l)
+		})
+		assert.LessOrEqual(t, albedo, 1.0)
+		assert.Greater(t, albedo, 0.9)
+	})
+}
+
+func TestLighting_PBR(t *testing.T) {
+	eyev := ray.NewVec(0, 0, -1)
+	normalv := ray.NewVec(0, 0, -1)
+	obj := object.NewSphere(ray.ZeroPoint, 1)
+
+	testCases := []struct {
+		name     string
+		light    object.PointLight
+		shadowed bool
+		expected object.RGB
+	}{
+		{
+			name:     "A light facing the surface",
+			light:    object.NewPointLight(ray.NewPoint(0, 0, -10), object.White),
+			expected: object.NewColor(1.1, 1.1, 1.1),
+		},
+		{
+			name:     "A light at 45°",
+			light:    object.NewPointLight(ray.NewPoint(0, 10, -10), object.White),
+			expected: object.NewColor(0.1+math.Sqrt2/2, 0.1+math.Sqrt2/2, 0.1+math.Sqrt2/2),
+		},
+		{
+			name:     "A light behind the surface",
+			light:    object.NewPointLight(ray.NewPoint(0, 0, 10), object.White),
+			expected: object.NewColor(0.1, 0.1, 0.1),
+		},
+		{
+			name:     "A surface in shadow",
+			light:    object.NewPointLight(ray.NewPoint(0, 0, -10), object.White),
+			shadowed: true,
+			expected: object.NewColor(0.1, 0.1, 0.1),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			m := object.NewPBRMaterial(object.White, 0, 0.5)
+			m.PBR.Specular = 0
+			actual := object.Lighting(m, obj, tt.light, ray.ZeroPoint, eyev, normalv, tt.shadowed)
+			assertColorEqual(t, tt.expected, actual)
+		})
+	}
+}
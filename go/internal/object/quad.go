@@ -0,0 +1,40 @@
+package object
+
+import (
+	"math"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+// quad is the square of the xz plane between -1 and 1, handy for light
+// panels and walls that shouldn't go on forever.
+type quad struct {
+	obj
+}
+
+func (q *quad) LocalIntersect(r ray.Ray) Intersections {
+	if math.Abs(r.Direction().GetY()) < epsilon {
+		return nil
+	}
+
+	t := -r.Origin().GetY() / r.Direction().GetY()
+	p := r.PointAt(t)
+	if math.Abs(p.GetX()) > 1 || math.Abs(p.GetZ()) > 1 {
+		return nil
+	}
+	return []Intersection{{T: t, Obj: q}}
+}
+
+func (q quad) LocalNormalAt(_ ray.Vector, _ Intersection) ray.Vector {
+	return defaultPlaneLocalNormal
+}
+
+func NewQuad(opts ...Option) Object {
+	q := &quad{}
+	q.SetMaterial(DefaultMaterial())
+	_ = q.SetTransform(ray.DefaultIdentityMatrix())
+	for i := range opts {
+		opts[i].Apply(q)
+	}
+	return q
+}
@@ -0,0 +1,53 @@
+package object_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+func TestQuad_LocalIntersect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		r        ray.Ray
+		expected []float64
+	}{
+		{
+			name:     "A ray hitting the middle",
+			r:        ray.NewRayAt(ray.NewPoint(0, 1, 0), ray.NewVec(0, -1, 0)),
+			expected: []float64{1},
+		},
+		{
+			name:     "A ray hitting near a corner",
+			r:        ray.NewRayAt(ray.NewPoint(0.9, -2, -0.9), ray.NewVec(0, 1, 0)),
+			expected: []float64{2},
+		},
+		{
+			name: "A ray missing the edge",
+			r:    ray.NewRayAt(ray.NewPoint(1.1, 1, 0), ray.NewVec(0, -1, 0)),
+		},
+		{
+			name: "A ray parallel to the quad",
+			r:    ray.NewRayAt(ray.NewPoint(0, 1, 0), ray.NewVec(0, 0, 1)),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			q := object.NewQuad()
+			xs := q.LocalIntersect(tt.r)
+			assert.Len(t, xs, len(tt.expected))
+			for i := range tt.expected {
+				assert.Equal(t, tt.expected[i], xs[i].T)
+				assert.Same(t, q, xs[i].Obj)
+			}
+		})
+	}
+}
+
+func TestQuad_LocalNormalAt(t *testing.T) {
+	q := object.NewQuad()
+	assertVec(t, ray.NewVec(0, 1, 0), q.LocalNormalAt(ray.NewPoint(0.5, 0, 0.5), object.NoHit))
+}
@@ -0,0 +1,161 @@
+package object
+
+import (
+	"math"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+const (
+	defaultSDFMaxSteps      = 256
+	defaultSDFMaxDistance   = 100.0
+	defaultSDFHitEpsilon    = 1e-4
+	defaultSDFNormalEpsilon = 1e-4
+	defaultSDFStepScale     = 1.0
+	// sdfMinHitScale times the hit epsilon is how close to a ray's origin
+	// the surface it starts on is ignored
+	sdfMinHitScale = 10
+	sdfBisections  = 40
+)
+
+// DistanceFunc returns the signed distance from a point in object space to
+// the surface, negative when the point is inside the shape.
+type DistanceFunc func(point ray.Vector) float64
+
+type sdf struct {
+	obj
+	distance    DistanceFunc
+	maxSteps    int
+	maxDistance float64
+	hitEpsilon  float64
+	stepScale   float64
+}
+
+// NewSDF creates a shape from a signed distance function. The shape is
+// intersected by sphere tracing, so the function should never overestimate
+// the distance to the surface (use WithStepScale for ones that do).
+func NewSDF(distance DistanceFunc, opts ...Option) Object {
+	s := &sdf{
+		distance:    distance,
+		maxSteps:    defaultSDFMaxSteps,
+		maxDistance: defaultSDFMaxDistance,
+		hitEpsilon:  defaultSDFHitEpsilon,
+		stepScale:   defaultSDFStepScale,
+	}
+	_ = s.SetTransform(ray.DefaultIdentityMatrix())
+	s.SetMaterial(DefaultMaterial())
+
+	for i := range opts {
+		opts[i].Apply(s)
+	}
+	return s
+}
+
+func WithMaxSteps(steps int) Option {
+	return OptionFunc(func(o Object) {
+		if s, ok := o.(*sdf); ok {
+			s.maxSteps = steps
+		}
+	})
+}
+
+func WithMaxDistance(distance float64) Option {
+	return OptionFunc(func(o Object) {
+		if s, ok := o.(*sdf); ok {
+			s.maxDistance = distance
+		}
+	})
+}
+
+func WithSurfaceEpsilon(e float64) Option {
+	return OptionFunc(func(o Object) {
+		if s, ok := o.(*sdf); ok {
+			s.hitEpsilon = e
+		}
+	})
+}
+
+// WithStepScale shrinks each marching step, needed for distance functions
+// that are only bounds (twists, fractals).
+func WithStepScale(scale float64) Option {
+	return OptionFunc(func(o Object) {
+		if s, ok := o.(*sdf); ok {
+			s.stepScale = scale
+		}
+	})
+}
+
+func (s *sdf) LocalIntersect(r ray.Ray) (xs Intersections) {
+	// the ray might be scaled by the object transform, so march along the
+	// unit direction and convert back to the ray's t at the end
+	length := r.Direction().Magnitude()
+	if length == 0 {
+		return nil
+	}
+	direction := r.Direction().Divide(length)
+	origin := r.Origin()
+
+	// hits behind the origin count too, like the other shapes, so
+	// refraction knows which objects a ray starting inside one is in
+	behind := s.march(origin, direction.Negate())
+	ts := make([]float64, 0, len(behind)+2)
+	for i := len(behind) - 1; i >= 0; i-- {
+		ts = append(ts, -behind[i])
+	}
+	ts = append(ts, s.march(origin, direction)...)
+
+	// a ray that starts on the surface, like a shadow or reflection ray,
+	// is leaving it, so that crossing's behind it rather than a hit
+	minT := sdfMinHitScale * s.hitEpsilon
+	for _, t := range ts {
+		if math.Abs(t) < minT {
+			t = -minT
+		}
+		xs = append(xs, Intersection{
+			T:   t / length,
+			Obj: s,
+		})
+	}
+	return xs
+}
+
+// march sphere traces along the unit direction, returning how far along it
+// the ray crosses the surface, in order. Steps are never shorter than the
+// hit epsilon, so grazing the surface can't use them all up.
+func (s *sdf) march(origin, direction ray.Vector) (ts []float64) {
+	t := 0.0
+	d := s.distance(origin)
+	for step := 0; step < s.maxSteps && t < s.maxDistance; step++ {
+		next := t + math.Max(math.Abs(d)*s.stepScale, s.hitEpsilon)
+		nd := s.distance(origin.Add(direction.Multiply(next)))
+		if (d < 0) != (nd < 0) {
+			ts = append(ts, s.crossing(origin, direction, t, next, d < 0))
+		}
+		t, d = next, nd
+	}
+	return ts
+}
+
+// crossing narrows down where the surface is between a and b by bisection,
+// inside is whether a is inside the shape.
+func (s *sdf) crossing(origin, direction ray.Vector, a, b float64, inside bool) float64 {
+	for i := 0; i < sdfBisections; i++ {
+		mid := (a + b) / 2
+		if (s.distance(origin.Add(direction.Multiply(mid))) < 0) == inside {
+			a = mid
+		} else {
+			b = mid
+		}
+	}
+	return (a + b) / 2
+}
+
+func (s sdf) LocalNormalAt(point ray.Vector, _ Intersection) ray.Vector {
+	const h = defaultSDFNormalEpsilon
+	x, y, z := point.GetX(), point.GetY(), point.GetZ()
+	return ray.NewVec(
+		s.distance(ray.NewPoint(x+h, y, z))-s.distance(ray.NewPoint(x-h, y, z)),
+		s.distance(ray.NewPoint(x, y+h, z))-s.distance(ray.NewPoint(x, y-h, z)),
+		s.distance(ray.NewPoint(x, y, z+h))-s.distance(ray.NewPoint(x, y, z-h)),
+	)
+}
@@ -0,0 +1,141 @@
+package object_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+func TestSDF_LocalIntersect(t *testing.T) {
+	testCases := []struct {
+		name      string
+		opts      []object.Option
+		origin    ray.Vector
+		direction ray.Vector
+		expected  []float64
+	}{
+		{
+			name:      "A ray intersects a sphere at two points",
+			origin:    ray.NewPoint(0, 0, -5),
+			direction: ray.NewVec(0, 0, 1),
+			expected:  []float64{4, 6},
+		},
+		{
+			name:      "A ray misses a sphere",
+			origin:    ray.NewPoint(0, 2, -5),
+			direction: ray.NewVec(0, 0, 1),
+		},
+		{
+			name:      "A ray originates inside a sphere",
+			origin:    ray.NewPoint(0, 0, 0),
+			direction: ray.NewVec(0, 0, 1),
+			expected:  []float64{-1, 1},
+		},
+		{
+			name:      "A sphere is behind a ray",
+			origin:    ray.NewPoint(0, 0, 5),
+			direction: ray.NewVec(0, 0, 1),
+			expected:  []float64{-6, -4},
+		},
+		{
+			name:      "A ray leaving the surface it starts on",
+			origin:    ray.NewPoint(0, 0, -1-1e-8),
+			direction: ray.NewVec(0, 0, -1),
+			expected:  []float64{-2, -0.001},
+		},
+		{
+			name:      "A ray into the surface it starts on",
+			origin:    ray.NewPoint(0, 0, -1+1e-8),
+			direction: ray.NewVec(0, 0, 1),
+			expected:  []float64{-0.001, 2},
+		},
+		{
+			name:      "An unnormalised ray direction",
+			origin:    ray.NewPoint(0, 0, -5),
+			direction: ray.NewVec(0, 0, 2),
+			expected:  []float64{2, 3},
+		},
+		{
+			name:      "Running out of steps misses",
+			opts:      []object.Option{object.WithMaxSteps(1)},
+			origin:    ray.NewPoint(0, 0, -5),
+			direction: ray.NewVec(0, 0, 1),
+		},
+		{
+			name:      "Running out of distance misses",
+			opts:      []object.Option{object.WithMaxDistance(3)},
+			origin:    ray.NewPoint(0, 0, -5),
+			direction: ray.NewVec(0, 0, 1),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			s := object.NewSDF(object.SDFSphere(1), tt.opts...)
+			r := ray.NewRayAt(tt.origin, tt.direction)
+			xs := s.LocalIntersect(r)
+			require.Len(t, xs, len(tt.expected))
+			for i := range tt.expected {
+				assert.InDelta(t, tt.expected[i], xs[i].T, 0.001)
+				assert.Same(t, s, xs[i].Obj)
+			}
+		})
+	}
+}
+
+func TestSDF_Intersect_transformed(t *testing.T) {
+	s := object.NewSDF(object.SDFBox(1, 1, 1),
+		object.WithTransform(ray.Scaling(2, 2, 2)))
+	r := ray.NewRayAt(ray.NewPoint(0, 0, -5), ray.NewVec(0, 0, 1))
+	xs := object.Intersect(s, r)
+	require.Len(t, xs, 2)
+	assert.InDelta(t, 3, xs[0].T, 0.001)
+	assert.InDelta(t, 7, xs[1].T, 0.001)
+}
+
+func TestSDF_LocalNormalAt(t *testing.T) {
+	testCases := []struct {
+		name     string
+		point    ray.Vector
+		expected ray.Vector
+	}{
+		{
+			name:     "The normal on a sphere at a point on the x axis",
+			point:    ray.NewPoint(1, 0, 0),
+			expected: ray.NewVec(1, 0, 0),
+		},
+		{
+			name:     "The normal on a sphere at a point on the y axis",
+			point:    ray.NewPoint(0, 1, 0),
+			expected: ray.NewVec(0, 1, 0),
+		},
+		{
+			name:     "The normal on a sphere at a nonaxial point",
+			point:    ray.NewPoint(math.Sqrt(3)/3, math.Sqrt(3)/3, math.Sqrt(3)/3),
+			expected: ray.NewVec(math.Sqrt(3)/3, math.Sqrt(3)/3, math.Sqrt(3)/3),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			s := object.NewSDF(object.SDFSphere(1))
+			n := object.NormalAt(object.Intersection{Obj: s}, tt.point)
+			assertVec(t, tt.expected, n)
+		})
+	}
+}
+
+func TestSDF_Mandelbulb(t *testing.T) {
+	s := object.NewSDF(object.SDFMandelbulb(8, 10),
+		object.WithStepScale(0.5),
+		object.WithMaxSteps(1000),
+	)
+	r := ray.NewRayAt(ray.NewPoint(0, 0, -3), ray.NewVec(0, 0, 1))
+	xs := s.LocalIntersect(r)
+	require.NotEmpty(t, xs)
+	assert.Greater(t, xs[0].T, 1.5)
+	assert.Less(t, xs[0].T, 3.0)
+}
@@ -0,0 +1,157 @@
+package object
+
+import (
+	"math"
+	"sort"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+// SurfaceSampler is implemented by shapes that can pick points on their
+// surface, so emissive shapes can be sampled as lights.
+type SurfaceSampler interface {
+	// LocalSample picks a point on the surface in object space, returning
+	// its normal and the density per unit of object space area.
+	LocalSample(u1, u2 float64) (point, normal ray.Vector, pdf float64)
+	LocalArea() float64
+}
+
+// SampleSurface picks a point on obj in world space, returning its normal
+// and the density per unit of world space area. ok is false for shapes that
+// can't be sampled.
+func SampleSurface(obj Object, u1, u2 float64) (point, normal ray.Vector, pdf float64, ok bool) {
+	s, ok := obj.(SurfaceSampler)
+	if !ok {
+		return nil, nil, 0, false
+	}
+	point, normal, pdf = s.LocalSample(u1, u2)
+	for o := obj; o != nil; o = o.Parent() {
+		point, normal, pdf = toParent(o, point, normal, pdf)
+	}
+	return point, normal, pdf, pdf > 0
+}
+
+// toParent moves a surface sample out of obj's space, the density
+// shrinking as the transform stretches the surface.
+func toParent(obj Object, point, normal ray.Vector, pdf float64) (ray.Vector, ray.Vector, float64) {
+	t := obj.Transform()
+	if scale := areaScale(t, normal); scale > 0 {
+		pdf /= scale
+	}
+	return t.MultiplyByVector(point),
+		obj.TransformInverse().Transpose().MultiplyByVector(normal).SetW(0).Normalize(),
+		pdf
+}
+
+// areaScale is how much a transform grows a patch of surface facing normal.
+func areaScale(t ray.Matrix, normal ray.Vector) float64 {
+	n := normal.Normalize()
+	tangent := ray.Cross(ray.NewVec(1, 0, 0), n)
+	if tangent.Magnitude() < 0.1 {
+		tangent = ray.Cross(ray.NewVec(0, 1, 0), n)
+	}
+	tangent = tangent.Normalize()
+	bitangent := ray.Cross(n, tangent)
+	return ray.Cross(
+		t.MultiplyByVector(tangent),
+		t.MultiplyByVector(bitangent)).Magnitude()
+}
+
+func (s *sphere) LocalSample(u1, u2 float64) (point, normal ray.Vector, pdf float64) {
+	z := 1 - 2*u1
+	r := math.Sqrt(math.Max(0, 1-z*z))
+	phi := 2 * math.Pi * u2
+	normal = ray.NewVec(r*math.Cos(phi), r*math.Sin(phi), z)
+	return s.c.Add(normal.Multiply(s.r)), normal, 1 / s.LocalArea()
+}
+
+func (s *sphere) LocalArea() float64 {
+	return 4 * math.Pi * s.r * s.r
+}
+
+func (t *triangle) LocalSample(u1, u2 float64) (point, normal ray.Vector, pdf float64) {
+	su := math.Sqrt(u1)
+	b1, b2 := u2*su, 1-su
+	point = t.p1.Add(t.e1.Multiply(b1)).Add(t.e2.Multiply(b2))
+	return point, ray.Cross(t.e2, t.e1).Normalize(), 1 / t.LocalArea()
+}
+
+func (t *triangle) LocalArea() float64 {
+	return ray.Cross(t.e1, t.e2).Magnitude() / 2
+}
+
+func (c *cube) LocalSample(u1, u2 float64) (point, normal ray.Vector, pdf float64) {
+	face := math.Min(math.Floor(u1*6), 5)
+	a, b := 2*(u1*6-face)-1, 2*u2-1
+	switch face {
+	case 0:
+		point, normal = ray.NewPoint(1, a, b), ray.NewVec(1, 0, 0)
+	case 1:
+		point, normal = ray.NewPoint(-1, a, b), ray.NewVec(-1, 0, 0)
+	case 2:
+		point, normal = ray.NewPoint(a, 1, b), ray.NewVec(0, 1, 0)
+	case 3:
+		point, normal = ray.NewPoint(a, -1, b), ray.NewVec(0, -1, 0)
+	case 4:
+		point, normal = ray.NewPoint(a, b, 1), ray.NewVec(0, 0, 1)
+	default:
+		point, normal = ray.NewPoint(a, b, -1), ray.NewVec(0, 0, -1)
+	}
+	return point, normal, 1 / c.LocalArea()
+}
+
+func (c *cube) LocalArea() float64 {
+	return 24
+}
+
+func (q *quad) LocalSample(u1, u2 float64) (point, normal ray.Vector, pdf float64) {
+	return ray.NewPoint(2*u1-1, 0, 2*u2-1), defaultPlaneLocalNormal, 1 / q.LocalArea()
+}
+
+func (q *quad) LocalArea() float64 {
+	return 4
+}
+
+// LocalSample picks a child in proportion to its area, so a mesh is
+// sampled evenly over its triangles.
+func (g *Group) LocalSample(u1, u2 float64) (point, normal ray.Vector, pdf float64) {
+	if len(g.areas) == 0 || g.areas[len(g.areas)-1] <= 0 {
+		return nil, nil, 0
+	}
+	total := g.areas[len(g.areas)-1]
+	idx := sort.SearchFloat64s(g.areas, u1*total)
+	if idx >= len(g.areas) {
+		idx = len(g.areas) - 1
+	}
+	start := 0.0
+	if idx > 0 {
+		start = g.areas[idx-1]
+	}
+	area := g.areas[idx] - start
+	if area <= 0 {
+		return nil, nil, 0
+	}
+
+	child := g.Children[idx]
+	point, normal, pdf = child.(SurfaceSampler).LocalSample((u1*total-start)/area, u2)
+	point, normal, pdf = toParent(child, point, normal, pdf)
+	return point, normal, pdf * area / total
+}
+
+// LocalArea is roughly the area of the children, it only weighs which
+// child gets sampled.
+func (g *Group) LocalArea() float64 {
+	if len(g.areas) == 0 {
+		return 0
+	}
+	return g.areas[len(g.areas)-1]
+}
+
+// childArea is roughly the area of a child in the group's space.
+func childArea(child Object) float64 {
+	s, ok := child.(SurfaceSampler)
+	if !ok {
+		return 0
+	}
+	return s.LocalArea() * areaScale(child.Transform(), ray.NewVec(0, 1, 0))
+}
@@ -0,0 +1,110 @@
+package object_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+func TestSampleSurface(t *testing.T) {
+	twoTriangles := func() object.Object {
+		g := object.NewGroup()
+		g.AddChild(
+			object.NewTriangle(ray.NewPoint(0, 0, 0), ray.NewPoint(1, 0, 0), ray.NewPoint(0, 0, 1)),
+			object.NewTriangle(ray.NewPoint(0, 0, 0), ray.NewPoint(2, 0, 0), ray.NewPoint(0, 0, 2)),
+		)
+		return &g
+	}
+
+	testCases := []struct {
+		name     string
+		obj      object.Object
+		pdf      float64
+		onObject func(point, normal ray.Vector) bool
+	}{
+		{
+			name: "A sphere",
+			obj:  object.NewSphere(ray.NewPoint(1, 0, 0), 2),
+			pdf:  1 / (16 * math.Pi),
+			onObject: func(point, normal ray.Vector) bool {
+				d := point.Subtract(ray.NewPoint(1, 0, 0))
+				return math.Abs(d.Magnitude()-2) < 1e-9 && ray.Dot(d.Normalize(), normal) > 0.999999
+			},
+		},
+		{
+			name: "A scaled sphere",
+			obj:  object.DefaultSphere(object.WithTransform(ray.Scaling(3, 3, 3))),
+			pdf:  1 / (36 * math.Pi),
+			onObject: func(point, _ ray.Vector) bool {
+				return math.Abs(point.Subtract(ray.ZeroPoint).Magnitude()-3) < 1e-9
+			},
+		},
+		{
+			name: "A cube",
+			obj:  object.NewCube(),
+			pdf:  1.0 / 24,
+			onObject: func(point, normal ray.Vector) bool {
+				m := math.Max(math.Abs(point.GetX()), math.Max(math.Abs(point.GetY()), math.Abs(point.GetZ())))
+				return math.Abs(m-1) < 1e-9 && math.Abs(ray.Dot(point, normal)-1) < 1e-9
+			},
+		},
+		{
+			name: "A quad stretched and moved into place",
+			obj: object.NewQuad(object.WithTransform(
+				ray.Translation(0, 5, 0).Multiply(ray.Scaling(2, 1, 0.5)))),
+			pdf: 1.0 / 4,
+			onObject: func(point, normal ray.Vector) bool {
+				return point.GetY() == 5 && math.Abs(point.GetX()) <= 2 && math.Abs(point.GetZ()) <= 0.5 &&
+					normal.GetY() == 1
+			},
+		},
+		{
+			name: "A triangle",
+			obj:  object.NewTriangle(ray.NewPoint(0, 1, 0), ray.NewPoint(-1, 0, 0), ray.NewPoint(1, 0, 0)),
+			pdf:  1,
+			onObject: func(point, normal ray.Vector) bool {
+				return point.GetZ() == 0 && point.GetY() >= 0 && math.Abs(point.GetX()) <= 1-point.GetY()+1e-9 &&
+					math.Abs(normal.GetZ()) == 1
+			},
+		},
+		{
+			name: "A mesh is sampled evenly over its triangles",
+			obj:  twoTriangles(),
+			pdf:  1.0 / 2.5,
+			onObject: func(point, normal ray.Vector) bool {
+				return point.GetY() == 0 && point.GetX()+point.GetZ() <= 2+1e-9 && math.Abs(normal.GetY()) == 1
+			},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, u := range [][2]float64{{0, 0}, {0.25, 0.75}, {0.5, 0.5}, {0.99, 0.1}} {
+				point, normal, pdf, ok := object.SampleSurface(tt.obj, u[0], u[1])
+				require.True(t, ok)
+				assert.InDelta(t, tt.pdf, pdf, 1e-9)
+				assert.True(t, tt.onObject(point, normal), "%v is not on the object, normal %v", point, normal)
+			}
+		})
+	}
+
+	t.Run("A plane goes on forever so can't be sampled", func(t *testing.T) {
+		_, _, _, ok := object.SampleSurface(object.NewPlane(), 0.5, 0.5)
+		assert.False(t, ok)
+	})
+}
+
+func TestGroup_LocalArea(t *testing.T) {
+	inner := object.NewGroup(object.WithTransform(ray.Scaling(2, 2, 2)))
+	outer := object.NewGroup()
+	outer.AddChild(&inner, object.NewQuad())
+	require.Equal(t, 4.0, outer.LocalArea())
+
+	inner.AddChild(object.NewQuad())
+	assert.Equal(t, 4.0, inner.LocalArea())
+	assert.Equal(t, 20.0, outer.LocalArea(), "filling a child group grows its parent")
+}
@@ -12,6 +12,8 @@ type triangle struct {
 	p1, p2, p3 ray.Vector
 	n1, n2, n3 ray.Vector
 	e1, e2     ray.Vector
+	// texture coordinates of the corners
+	uv1, uv2, uv3 UV
 }
 
 func WithNormals(n1, n2, n3 ray.Vector) Option {
@@ -41,6 +43,9 @@ func NewTriangle(p1, p2, p3 ray.Vector, opts ...Option) Object {
 	t.n1 = normal
 	t.n2 = normal
 	t.n3 = normal
+	// the corners' barycentric coordinates until told otherwise
+	t.uv2 = UV{U: 1}
+	t.uv3 = UV{V: 1}
 
 	for i := range opts {
 		opts[i].Apply(&t)
@@ -0,0 +1,131 @@
+package object
+
+import (
+	"math"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+// UV is a texture coordinate, u across and v up the texture.
+type UV struct {
+	U, V float64
+}
+
+// Texture is anything that can be looked up by texture coordinate, like an
+// image loaded into a canvas.
+type Texture interface {
+	Sample(u, v float64) RGB
+}
+
+// UVMapper is implemented by shapes that can map their surface onto a
+// texture.
+type UVMapper interface {
+	// LocalUV is the texture coordinate of an object space point on the
+	// surface, and the object space directions u and v increase in.
+	LocalUV(point ray.Vector, hit Intersection) (uv UV, tangent, bitangent ray.Vector)
+}
+
+// SurfaceUV is the texture coordinate of a hit, with the tangent and
+// bitangent in world space. ok is false for shapes without a mapping.
+func SurfaceUV(hit Intersection, worldPoint ray.Vector) (uv UV, tangent, bitangent ray.Vector, ok bool) {
+	m, ok := hit.Obj.(UVMapper)
+	if !ok {
+		return uv, nil, nil, false
+	}
+	uv, tangent, bitangent = m.LocalUV(hit.Obj.WorldToObjectAt(worldPoint, hit.Time), hit)
+	for o := hit.Obj; o != nil; o = o.Parent() {
+		t := o.TransformAt(hit.Time)
+		tangent = t.MultiplyByVector(tangent.SetW(0))
+		bitangent = t.MultiplyByVector(bitangent.SetW(0))
+	}
+	return uv, tangent.Normalize(), bitangent.Normalize(), true
+}
+
+// spherical wraps u around the y axis, starting behind at -z.
+func spherical(x, z float64) (u float64, tangent ray.Vector) {
+	u = 0.5 + math.Atan2(x, z)/(2*math.Pi)
+	tangent = ray.NewVec(z, 0, -x)
+	if tangent.Magnitude() < epsilon {
+		// the poles have no direction around, any will do
+		tangent = ray.NewVec(1, 0, 0)
+	}
+	return u, tangent
+}
+
+func (s sphere) LocalUV(point ray.Vector, _ Intersection) (uv UV, tangent, bitangent ray.Vector) {
+	p := point.Subtract(s.c).Divide(s.r)
+	uv.U, tangent = spherical(p.GetX(), p.GetZ())
+	uv.V = 1 - math.Acos(clamp(p.GetY(), -1, 1))/math.Pi
+	return uv, tangent, ray.Cross(tangent, p).Negate()
+}
+
+func (p plane) LocalUV(point ray.Vector, _ Intersection) (uv UV, tangent, bitangent ray.Vector) {
+	x, z := point.GetX(), point.GetZ()
+	return UV{U: x - math.Floor(x), V: z - math.Floor(z)}, ray.NewVec(1, 0, 0), ray.NewVec(0, 0, 1)
+}
+
+func (q quad) LocalUV(point ray.Vector, _ Intersection) (uv UV, tangent, bitangent ray.Vector) {
+	return UV{U: (point.GetX() + 1) / 2, V: (point.GetZ() + 1) / 2}, ray.NewVec(1, 0, 0), ray.NewVec(0, 0, 1)
+}
+
+// LocalUV maps each face of the cube onto the whole texture.
+func (c cube) LocalUV(point ray.Vector, _ Intersection) (uv UV, tangent, bitangent ray.Vector) {
+	x, y, z := point.GetX(), point.GetY(), point.GetZ()
+	up := ray.NewVec(0, 1, 0)
+	switch n := c.LocalNormalAt(point, NoHit); {
+	case n.GetX() > 0:
+		return UV{U: (1 - z) / 2, V: (y + 1) / 2}, ray.NewVec(0, 0, -1), up
+	case n.GetX() < 0:
+		return UV{U: (z + 1) / 2, V: (y + 1) / 2}, ray.NewVec(0, 0, 1), up
+	case n.GetY() > 0:
+		return UV{U: (x + 1) / 2, V: (1 - z) / 2}, ray.NewVec(1, 0, 0), ray.NewVec(0, 0, -1)
+	case n.GetY() < 0:
+		return UV{U: (x + 1) / 2, V: (z + 1) / 2}, ray.NewVec(1, 0, 0), ray.NewVec(0, 0, 1)
+	case n.GetZ() > 0:
+		return UV{U: (x + 1) / 2, V: (y + 1) / 2}, ray.NewVec(1, 0, 0), up
+	default:
+		return UV{U: (1 - x) / 2, V: (y + 1) / 2}, ray.NewVec(-1, 0, 0), up
+	}
+}
+
+// LocalUV wraps u around the cylinder and repeats v every unit up it, caps
+// are mapped flat.
+func (c cylinder) LocalUV(point ray.Vector, hit Intersection) (uv UV, tangent, bitangent ray.Vector) {
+	x, y, z := point.GetX(), point.GetY(), point.GetZ()
+	if n := c.LocalNormalAt(point, hit); n.GetY() != 0 {
+		return UV{U: (x + 1) / 2, V: (z + 1) / 2}, ray.NewVec(1, 0, 0), ray.NewVec(0, 0, 1)
+	}
+	uv.U, tangent = spherical(x, z)
+	uv.V = y - math.Floor(y)
+	return uv, tangent, ray.NewVec(0, 1, 0)
+}
+
+// LocalUV interpolates the corners' texture coordinates.
+func (t triangle) LocalUV(_ ray.Vector, hit Intersection) (uv UV, tangent, bitangent ray.Vector) {
+	uv = UV{
+		U: t.uv1.U*(1-hit.U-hit.V) + t.uv2.U*hit.U + t.uv3.U*hit.V,
+		V: t.uv1.V*(1-hit.U-hit.V) + t.uv2.V*hit.U + t.uv3.V*hit.V,
+	}
+
+	du1, dv1 := t.uv2.U-t.uv1.U, t.uv2.V-t.uv1.V
+	du2, dv2 := t.uv3.U-t.uv1.U, t.uv3.V-t.uv1.V
+	det := du1*dv2 - du2*dv1
+	if math.Abs(det) < epsilon {
+		// the corners share a coordinate, fall back to the edges
+		return uv, t.e1, t.e2
+	}
+	tangent = t.e1.Multiply(dv2).Subtract(t.e2.Multiply(dv1)).Divide(det)
+	bitangent = t.e2.Multiply(du1).Subtract(t.e1.Multiply(du2)).Divide(det)
+	return uv, tangent, bitangent
+}
+
+// WithUVs sets the texture coordinates of a triangle's corners.
+func WithUVs(uv1, uv2, uv3 UV) Option {
+	return OptionFunc(func(o Object) {
+		if t, ok := o.(*triangle); ok {
+			t.uv1 = uv1
+			t.uv2 = uv2
+			t.uv3 = uv3
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package object_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+func TestSurfaceUV(t *testing.T) {
+	triangle := object.NewTriangle(ray.NewPoint(0, 1, 0), ray.NewPoint(-1, 0, 0), ray.NewPoint(1, 0, 0))
+	mapped := object.NewTriangle(ray.NewPoint(0, 0, 0), ray.NewPoint(2, 0, 0), ray.NewPoint(0, 2, 0),
+		object.WithUVs(object.UV{U: 0, V: 0}, object.UV{U: 1, V: 0}, object.UV{U: 0, V: 1}))
+
+	testCases := []struct {
+		name      string
+		hit       object.Intersection
+		point     ray.Vector
+		uv        object.UV
+		tangent   ray.Vector
+		bitangent ray.Vector
+	}{
+		{
+			name:      "sphere equator",
+			hit:       object.Intersection{Obj: object.NewSphere(ray.ZeroPoint, 1)},
+			point:     ray.NewPoint(1, 0, 0),
+			uv:        object.UV{U: 0.75, V: 0.5},
+			tangent:   ray.NewVec(0, 0, -1),
+			bitangent: ray.NewVec(0, 1, 0),
+		},
+		{
+			name:      "plane",
+			hit:       object.Intersection{Obj: object.NewPlane()},
+			point:     ray.NewPoint(0.25, 0, 1.5),
+			uv:        object.UV{U: 0.25, V: 0.5},
+			tangent:   ray.NewVec(1, 0, 0),
+			bitangent: ray.NewVec(0, 0, 1),
+		},
+		{
+			name:      "rotated plane",
+			hit:       object.Intersection{Obj: object.NewPlane(object.WithTransform(ray.Rotation(ray.Y, math.Pi/2)))},
+			point:     ray.NewPoint(0.5, 0, -0.25),
+			uv:        object.UV{U: 0.25, V: 0.5},
+			tangent:   ray.NewVec(0, 0, -1),
+			bitangent: ray.NewVec(1, 0, 0),
+		},
+		{
+			name:      "triangle defaults to barycentric coordinates",
+			hit:       object.Intersection{Obj: triangle, U: 0.25, V: 0.5},
+			point:     ray.NewPoint(0.25, 0.25, 0),
+			uv:        object.UV{U: 0.25, V: 0.5},
+			tangent:   ray.NewVec(-1, -1, 0).Normalize(),
+			bitangent: ray.NewVec(1, -1, 0).Normalize(),
+		},
+		{
+			name:      "triangle with texture coordinates",
+			hit:       object.Intersection{Obj: mapped, U: 0.5, V: 0.25},
+			point:     ray.NewPoint(1, 0.5, 0),
+			uv:        object.UV{U: 0.5, V: 0.25},
+			tangent:   ray.NewVec(1, 0, 0),
+			bitangent: ray.NewVec(0, 1, 0),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			uv, tangent, bitangent, ok := object.SurfaceUV(tt.hit, tt.point)
+			require.True(t, ok)
+			assert.InDelta(t, tt.uv.U, uv.U, 1e-9, "u")
+			assert.InDelta(t, tt.uv.V, uv.V, 1e-9, "v")
+			assertVec(t, tt.tangent, tangent)
+			assertVec(t, tt.bitangent, bitangent)
+		})
+	}
+
+	t.Run("shapes without a mapping", func(t *testing.T) {
+		_, _, _, ok := object.SurfaceUV(object.Intersection{Obj: object.NewTestShape()}, ray.ZeroPoint)
+		assert.False(t, ok)
+	})
+}
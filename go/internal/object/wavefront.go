@@ -15,7 +15,8 @@ import (
 type WavefrontObj struct {
 	Vertices,
 	Normals []ray.Vector
-	Group Group
+	TextureCoords []UV
+	Group         Group
 }
 
 func (w *WavefrontObj) Object() Object {
@@ -26,6 +27,7 @@ var pointsReg = regexp.MustCompile("(?P<Point>\\d+)(/(?P<Texture>\\d{*})/(?P<Nor
 
 func NewWavefrontObj(reader io.Reader, opts ...Option) (wv WavefrontObj, err error) {
 	var vertices, normals []ray.Vector
+	var uvs []UV
 	defaultGroup := NewGroup(opts...)
 	scanner := bufio.NewScanner(reader)
 	re := regexp.MustCompile("\\s+")
@@ -45,6 +47,13 @@ func NewWavefrontObj(reader io.Reader, opts ...Option) (wv WavefrontObj, err err
 			}
 			normals = append(normals, point)
 		}
+		if strings.HasPrefix(text, "vt ") {
+			uv, err := parseUV(re, text)
+			if err != nil {
+				return wv, err
+			}
+			uvs = append(uvs, uv)
+		}
 		if strings.HasPrefix(text, "g ") {
 			if vertices == nil {
 				return wv, errors.New("no vertices found")
@@ -60,11 +69,11 @@ func NewWavefrontObj(reader io.Reader, opts ...Option) (wv WavefrontObj, err err
 			var groupsToAdd []Object
 			switch len(points) {
 			case 4:
-				groupsToAdd, err = createTriangle(points, vertices, normals)
+				groupsToAdd, err = createTriangle(points, vertices, normals, uvs)
 			case 5:
-				groupsToAdd, err = createTrianglesFromSquare(points, vertices, normals)
+				groupsToAdd, err = createTrianglesFromSquare(points, vertices, normals, uvs)
 			case 6:
-				groupsToAdd, err = createTrianglesFromPentagon(points, vertices, normals)
+				groupsToAdd, err = createTrianglesFromPentagon(points, vertices, normals, uvs)
 			default:
 				return WavefrontObj{}, errors.New(fmt.Sprintf("do not know how to handle polygon with %v points", len(points)-1))
 			}
@@ -88,6 +97,7 @@ func NewWavefrontObj(reader io.Reader, opts ...Option) (wv WavefrontObj, err err
 	}
 	wv.Vertices = vertices
 	wv.Normals = normals
+	wv.TextureCoords = uvs
 	wv.Group = defaultGroup
 	return wv, nil
 }
@@ -110,19 +120,19 @@ func parsePoint(re *regexp.Regexp, text string) (ray.Vector, error) {
 	return point, nil
 }
 
-func createTrianglesFromSquare(points []string, vertices, normals []ray.Vector) ([]Object, error) {
+func createTrianglesFromSquare(points []string, vertices, normals []ray.Vector, uvs []UV) ([]Object, error) {
 	objs := make([]Object, 2)
-	p1, _, p1n, err := parseFaceElement(points[1])
+	p1, p1t, p1n, err := parseFaceElement(points[1])
 	if err != nil {
 		return nil, err
 	}
 
 	for i := 2; i < len(points)-1; i++ {
-		p2, _, p2n, err := parseFaceElement(points[i])
+		p2, p2t, p2n, err := parseFaceElement(points[i])
 		if err != nil {
 			return nil, err
 		}
-		p3, _, p3n, err := parseFaceElement(points[i+1])
+		p3, p3t, p3n, err := parseFaceElement(points[i+1])
 		if err != nil {
 			return nil, err
 		}
@@ -135,18 +145,42 @@ func createTrianglesFromSquare(points []string, vertices, normals []ray.Vector)
 				normals[p1n-1],
 				normals[p2n-1],
 				normals[p3n-1],
+				faceUVs(uvs, p1t, p2t, p3t)...,
 			)
 		} else {
 			objs[i-2] = NewTriangle(
 				vertices[p1-1],
 				vertices[p2-1],
 				vertices[p3-1],
+				faceUVs(uvs, p1t, p2t, p3t)...,
 			)
 		}
 	}
 	return objs, nil
 }
 
+func parseUV(re *regexp.Regexp, text string) (uv UV, err error) {
+	points := re.Split(strings.TrimSpace(text), 4)
+	if len(points) < 3 {
+		return uv, errors.New(fmt.Sprintf("texture coordinate needs u and v: %v", text))
+	}
+	if uv.U, err = strconv.ParseFloat(points[1], 64); err != nil {
+		return uv, err
+	}
+	uv.V, err = strconv.ParseFloat(points[2], 64)
+	return uv, err
+}
+
+// faceUVs are the options giving a face its texture coordinates, if all
+// its corners have one.
+func faceUVs(uvs []UV, t1, t2, t3 int) []Option {
+	in := func(t int) bool { return t > 0 && t <= len(uvs) }
+	if !in(t1) || !in(t2) || !in(t3) {
+		return nil
+	}
+	return []Option{WithUVs(uvs[t1-1], uvs[t2-1], uvs[t3-1])}
+}
+
 func parseFaceElement(face string) (v, vt, vn int, err error) {
 	sub := pointsReg.FindAllStringSubmatch(face, 3)
 	v, err = strconv.Atoi(sub[0][0])
@@ -174,19 +208,19 @@ func parseFaceElement(face string) (v, vt, vn int, err error) {
 	return
 }
 
-func createTrianglesFromPentagon(points []string, vertices, normals []ray.Vector) ([]Object, error) {
+func createTrianglesFromPentagon(points []string, vertices, normals []ray.Vector, uvs []UV) ([]Object, error) {
 	objs := make([]Object, 3)
-	p1, _, p1n, err := parseFaceElement(points[1])
+	p1, p1t, p1n, err := parseFaceElement(points[1])
 	if err != nil {
 		return nil, err
 	}
 
 	for i := 2; i < len(points)-1; i++ {
-		p2, _, p2n, err := parseFaceElement(points[i])
+		p2, p2t, p2n, err := parseFaceElement(points[i])
 		if err != nil {
 			return nil, err
 		}
-		p3, _, p3n, err := parseFaceElement(points[i+1])
+		p3, p3t, p3n, err := parseFaceElement(points[i+1])
 		if err != nil {
 			return nil, err
 		}
@@ -199,28 +233,30 @@ func createTrianglesFromPentagon(points []string, vertices, normals []ray.Vector
 				normals[p1n-1],
 				normals[p2n-1],
 				normals[p3n-1],
+				faceUVs(uvs, p1t, p2t, p3t)...,
 			)
 		} else {
 			objs[i-2] = NewTriangle(
 				vertices[p1-1],
 				vertices[p2-1],
 				vertices[p3-1],
+				faceUVs(uvs, p1t, p2t, p3t)...,
 			)
 		}
 	}
 	return objs, nil
 }
 
-func createTriangle(points []string, vertices, normals []ray.Vector) ([]Object, error) {
-	p1, _, p1n, err := parseFaceElement(points[1])
+func createTriangle(points []string, vertices, normals []ray.Vector, uvs []UV) ([]Object, error) {
+	p1, p1t, p1n, err := parseFaceElement(points[1])
 	if err != nil {
 		return nil, err
 	}
-	p2, _, p2n, err := parseFaceElement(points[2])
+	p2, p2t, p2n, err := parseFaceElement(points[2])
 	if err != nil {
 		return nil, err
 	}
-	p3, _, p3n, err := parseFaceElement(points[3])
+	p3, p3t, p3n, err := parseFaceElement(points[3])
 	if err != nil {
 		return nil, err
 	}
@@ -233,6 +269,7 @@ func createTriangle(points []string, vertices, normals []ray.Vector) ([]Object,
 			normals[p1n-1],
 			normals[p2n-1],
 			normals[p3n-1],
+			faceUVs(uvs, p1t, p2t, p3t)...,
 		)}, nil
 	}
 
@@ -240,5 +277,6 @@ func createTriangle(points []string, vertices, normals []ray.Vector) ([]Object,
 		vertices[p1-1],
 		vertices[p2-1],
 		vertices[p3-1],
+		faceUVs(uvs, p1t, p2t, p3t)...,
 	)}, nil
 }
@@ -95,6 +95,31 @@ v 1 1 0
 
 f 1/1/1 2/2/2 3/3/3
 f 1//1 3//3 4//4
+`,
+		},
+		{
+			name: "Texture coordinate records",
+			assertFunc: func(t *testing.T, wavObj object.WavefrontObj) {
+				require.Len(t, wavObj.TextureCoords, 3)
+				require.Len(t, wavObj.Group.Children, 1)
+
+				uvs := object.WithUVs(wavObj.TextureCoords[0], wavObj.TextureCoords[1], wavObj.TextureCoords[2])
+				t1 := object.NewSmoothTriangle(
+					wavObj.Vertices[0], wavObj.Vertices[1], wavObj.Vertices[2],
+					wavObj.Normals[0], wavObj.Normals[0], wavObj.Normals[0], uvs)
+				t1.SetParent(&wavObj.Group)
+				assert.Equal(t, t1, wavObj.Group.Children[0])
+				assert.Equal(t, object.UV{U: 0.5, V: 1}, wavObj.TextureCoords[2])
+			},
+			fileContent: `v -1 1 0
+v -1 0 0
+v 1 0 0
+vt 0 0
+vt 1 0
+vt 0.5 1
+vn 0 0 1
+
+f 1/1/1 2/2/1 3/3/1
 `,
 		},
 		{
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+)
+
+const (
+	// upperHalfBlock is drawn with the top pixel as the foreground colour
+	// and the bottom one as the background, two pixels to a character
+	upperHalfBlock = "▀"
+	resetColour    = "\x1b[0m"
+)
+
+type rgb struct {
+	r, g, b uint8
+}
+
+// NewTerminalOutput draws the image with 24-bit ANSI colours for terminals
+// that support them, two pixels to a character. An image wider than
+// columns characters is scaled down to fit, averaging the pixels.
+func NewTerminalOutput(img image.Image, columns int) (r io.Reader, err error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if columns > 0 && width > columns {
+		height = (height*columns + width/2) / width
+		if height < 1 {
+			height = 1
+		}
+		width = columns
+	}
+	pixels := scaleDown(img, width, height)
+
+	var b bytes.Buffer
+	for y := 0; y < height; y += 2 {
+		var fg, bg *rgb
+		for x := 0; x < width; x++ {
+			top := pixels[y*width+x]
+			if fg == nil || *fg != top {
+				fg = &top
+				if _, err = b.WriteString(fmt.Sprintf("\x1b[38;2;%v;%v;%vm", top.r, top.g, top.b)); err != nil {
+					return nil, err
+				}
+			}
+			// an odd row at the bottom leaves the terminal's own background
+			if y+1 < height {
+				bottom := pixels[(y+1)*width+x]
+				if bg == nil || *bg != bottom {
+					bg = &bottom
+					if _, err = b.WriteString(fmt.Sprintf("\x1b[48;2;%v;%v;%vm", bottom.r, bottom.g, bottom.b)); err != nil {
+						return nil, err
+					}
+				}
+			}
+			if _, err = b.WriteString(upperHalfBlock); err != nil {
+				return nil, err
+			}
+		}
+		if _, err = b.WriteString(resetColour + "\n"); err != nil {
+			return nil, err
+		}
+	}
+	return &b, nil
+}
+
+// scaleDown averages the pixels of img that fall in each of the width by
+// height pixels, a row at a time from the top left.
+func scaleDown(img image.Image, width, height int) []rgb {
+	bounds := img.Bounds()
+	pixels := make([]rgb, 0, width*height)
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := bounds.Min.Y + (y+1)*bounds.Dy()/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := bounds.Min.X + (x+1)*bounds.Dx()/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var red, green, blue, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					r, g, b, _ := img.At(sx, sy).RGBA()
+					red += uint64(r >> 8)
+					green += uint64(g >> 8)
+					blue += uint64(b >> 8)
+					n++
+				}
+			}
+			pixels = append(pixels, rgb{
+				r: uint8((red + n/2) / n),
+				g: uint8((green + n/2) / n),
+				b: uint8((blue + n/2) / n),
+			})
+		}
+	}
+	return pixels
+}
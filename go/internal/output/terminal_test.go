@@ -0,0 +1,92 @@
+package output_test
+
+import (
+	"image"
+	"image/color"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/output"
+)
+
+func TestNewTerminalOutput(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	// red on top of blue, left and right
+	stripes := func(width, height int) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		for x := 0; x < width; x++ {
+			for y := 0; y < height; y++ {
+				if y < height/2 {
+					img.Set(x, y, red)
+				} else {
+					img.Set(x, y, blue)
+				}
+			}
+		}
+		return img
+	}
+
+	testCases := []struct {
+		name    string
+		image   image.Image
+		columns int
+		want    string
+	}{
+		{
+			name:    "two pixels to a character",
+			image:   stripes(2, 2),
+			columns: 80,
+			want:    "\x1b[38;2;255;0;0m\x1b[48;2;0;0;255m▀▀\x1b[0m\n",
+		},
+		{
+			name:    "scaled down to fit",
+			image:   stripes(8, 8),
+			columns: 2,
+			want:    "\x1b[38;2;255;0;0m\x1b[48;2;0;0;255m▀▀\x1b[0m\n",
+		},
+		{
+			name: "averages the pixels",
+			image: func() image.Image {
+				img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+				img.Set(0, 0, color.White)
+				img.Set(1, 0, color.Black)
+				img.Set(0, 1, color.Black)
+				img.Set(1, 1, color.Black)
+				return img
+			}(),
+			columns: 1,
+			want:    "\x1b[38;2;64;64;64m▀\x1b[0m\n",
+		},
+		{
+			name:    "odd height leaves the background",
+			image:   stripes(1, 3),
+			columns: 0,
+			want: "\x1b[38;2;255;0;0m\x1b[48;2;0;0;255m▀\x1b[0m\n" +
+				"\x1b[38;2;0;0;255m▀\x1b[0m\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := output.NewTerminalOutput(tc.image, tc.columns)
+			require.NoError(t, err)
+			got, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(got))
+		})
+	}
+}
+
+func TestNewTerminalOutput_keepsAspectRatio(t *testing.T) {
+	r, err := output.NewTerminalOutput(image.NewRGBA(image.Rect(0, 0, 640, 320)), 80)
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(got), "\n"), "\n")
+	assert.Len(t, lines, 20, "40 pixels high")
+	assert.Equal(t, 80, strings.Count(lines[0], "▀"))
+}
@@ -0,0 +1,106 @@
+package ray
+
+import "math"
+
+// Interpolate blends between transforms a and b, t from 0 at a to 1 at b.
+// The transforms are split into translation, rotation and scale so a
+// turning object keeps its shape, rotating the shortest way round. Shears
+// aren't kept, and turns of more than half a circle need keyframes between.
+func Interpolate(a, b Matrix, t float64) Matrix {
+	if t <= 0 {
+		return a
+	}
+	if t >= 1 {
+		return b
+	}
+	ta, ra, sa := decompose(a)
+	tb, rb, sb := decompose(b)
+	var translate, scale [3]float64
+	for i := range translate {
+		translate[i] = ta[i] + (tb[i]-ta[i])*t
+		scale[i] = sa[i] + (sb[i]-sa[i])*t
+	}
+	return Translation(translate[0], translate[1], translate[2]).
+		Multiply(slerp(ra, rb, t).matrix()).
+		Multiply(Scaling(scale[0], scale[1], scale[2]))
+}
+
+// quaternion is a rotation, w the real part.
+type quaternion struct {
+	w, x, y, z float64
+}
+
+func decompose(m Matrix) (translate [3]float64, rotation quaternion, scale [3]float64) {
+	var r [3][3]float64
+	for col := 0; col < 3; col++ {
+		translate[col] = m[col][3]
+		scale[col] = math.Sqrt(m[0][col]*m[0][col] + m[1][col]*m[1][col] + m[2][col]*m[2][col])
+	}
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	if det < 0 {
+		// a mirror, put it in the scale so what's left is a rotation
+		scale[0] = -scale[0]
+	}
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			if scale[col] != 0 {
+				r[row][col] = m[row][col] / scale[col]
+			}
+		}
+	}
+	return translate, quaternionFrom(r), scale
+}
+
+func quaternionFrom(r [3][3]float64) quaternion {
+	var q quaternion
+	switch trace := r[0][0] + r[1][1] + r[2][2]; {
+	case trace > 0:
+		s := 2 * math.Sqrt(trace+1)
+		q = quaternion{s / 4, (r[2][1] - r[1][2]) / s, (r[0][2] - r[2][0]) / s, (r[1][0] - r[0][1]) / s}
+	case r[0][0] > r[1][1] && r[0][0] > r[2][2]:
+		s := 2 * math.Sqrt(1+r[0][0]-r[1][1]-r[2][2])
+		q = quaternion{(r[2][1] - r[1][2]) / s, s / 4, (r[0][1] + r[1][0]) / s, (r[0][2] + r[2][0]) / s}
+	case r[1][1] > r[2][2]:
+		s := 2 * math.Sqrt(1+r[1][1]-r[0][0]-r[2][2])
+		q = quaternion{(r[0][2] - r[2][0]) / s, (r[0][1] + r[1][0]) / s, s / 4, (r[1][2] + r[2][1]) / s}
+	default:
+		s := 2 * math.Sqrt(1+r[2][2]-r[0][0]-r[1][1])
+		q = quaternion{(r[1][0] - r[0][1]) / s, (r[0][2] + r[2][0]) / s, (r[1][2] + r[2][1]) / s, s / 4}
+	}
+	return q
+}
+
+func slerp(a, b quaternion, t float64) quaternion {
+	cos := a.w*b.w + a.x*b.x + a.y*b.y + a.z*b.z
+	if cos < 0 {
+		// the same rotation the shorter way round
+		b = quaternion{-b.w, -b.x, -b.y, -b.z}
+		cos = -cos
+	}
+	wa, wb := 1-t, t
+	if cos < 0.9995 {
+		angle := math.Acos(cos)
+		sin := math.Sin(angle)
+		wa = math.Sin((1-t)*angle) / sin
+		wb = math.Sin(t*angle) / sin
+	}
+	q := quaternion{
+		wa*a.w + wb*b.w,
+		wa*a.x + wb*b.x,
+		wa*a.y + wb*b.y,
+		wa*a.z + wb*b.z,
+	}
+	length := math.Sqrt(q.w*q.w + q.x*q.x + q.y*q.y + q.z*q.z)
+	return quaternion{q.w / length, q.x / length, q.y / length, q.z / length}
+}
+
+func (q quaternion) matrix() Matrix {
+	w, x, y, z := q.w, q.x, q.y, q.z
+	return NewMatrix(4, 4,
+		RowValues{1 - 2*(y*y+z*z), 2 * (x*y - w*z), 2 * (x*z + w*y), 0},
+		RowValues{2 * (x*y + w*z), 1 - 2*(x*x+z*z), 2 * (y*z - w*x), 0},
+		RowValues{2 * (x*z - w*y), 2 * (y*z + w*x), 1 - 2*(x*x+y*y), 0},
+		RowValues{0, 0, 0, 1})
+}
@@ -0,0 +1,79 @@
+package ray_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+func TestInterpolate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		a, b     ray.Matrix
+		t        float64
+		expected ray.Matrix
+	}{
+		{
+			name:     "start",
+			a:        ray.Translation(1, 2, 3),
+			b:        ray.Translation(3, 2, 1),
+			t:        0,
+			expected: ray.Translation(1, 2, 3),
+		},
+		{
+			name:     "end",
+			a:        ray.Translation(1, 2, 3),
+			b:        ray.Translation(3, 2, 1),
+			t:        1,
+			expected: ray.Translation(3, 2, 1),
+		},
+		{
+			name:     "half way along",
+			a:        ray.Translation(1, 2, 3),
+			b:        ray.Translation(3, 2, 1),
+			t:        0.5,
+			expected: ray.Translation(2, 2, 2),
+		},
+		{
+			name:     "turning keeps the shape",
+			a:        ray.DefaultIdentityMatrix(),
+			b:        ray.Rotation(ray.Y, math.Pi/2),
+			t:        0.5,
+			expected: ray.Rotation(ray.Y, math.Pi/4),
+		},
+		{
+			name:     "turning the shorter way round",
+			a:        ray.Rotation(ray.Z, -3*math.Pi/4),
+			b:        ray.Rotation(ray.Z, 3*math.Pi/4),
+			t:        0.5,
+			expected: ray.Rotation(ray.Z, math.Pi),
+		},
+		{
+			name:     "growing",
+			a:        ray.Scaling(1, 1, 1),
+			b:        ray.Scaling(3, 1, 2),
+			t:        0.25,
+			expected: ray.Scaling(1.5, 1, 1.25),
+		},
+		{
+			name:     "everything at once",
+			a:        ray.Translation(0, 0, 0).Multiply(ray.Rotation(ray.X, 0)).Multiply(ray.Scaling(1, 1, 1)),
+			b:        ray.Translation(4, 0, 0).Multiply(ray.Rotation(ray.X, math.Pi/3)).Multiply(ray.Scaling(3, 3, 3)),
+			t:        0.5,
+			expected: ray.Translation(2, 0, 0).Multiply(ray.Rotation(ray.X, math.Pi/6)).Multiply(ray.Scaling(2, 2, 2)),
+		},
+		{
+			name:     "mirrored",
+			a:        ray.Scaling(-1, 1, 1),
+			b:        ray.Scaling(-3, 1, 1),
+			t:        0.5,
+			expected: ray.Scaling(-2, 1, 1),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assertMatrixEqual(t, tt.expected, ray.Interpolate(tt.a, tt.b, tt.t))
+		})
+	}
+}
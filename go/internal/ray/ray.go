@@ -5,9 +5,12 @@ import (
 )
 
 type ray struct {
-	o     Vector
-	d     Vector
-	color color.RGBA
+	o          Vector
+	d          Vector
+	color      color.RGBA
+	wavelength float64
+	time       float64
+	bounce     int
 }
 
 func (r ray) Origin() Vector {
@@ -18,6 +21,31 @@ func (r ray) Direction() Vector {
 	return r.d
 }
 
+// Wavelength is in nanometres, zero for a ray carrying all visible light.
+func (r ray) Wavelength() float64 {
+	return r.wavelength
+}
+
+// Time is when in the shutter interval the ray was fired, so moving objects
+// can be where they were at that moment.
+func (r ray) Time() float64 {
+	return r.time
+}
+
+func (r *ray) SetTime(time float64) {
+	r.time = time
+}
+
+// Bounce is how many surfaces the light was bounced off on its way from
+// the camera, zero for rays fired by the camera.
+func (r ray) Bounce() int {
+	return r.bounce
+}
+
+func (r *ray) SetBounce(bounce int) {
+	r.bounce = bounce
+}
+
 func (r ray) PointAt(parameter float64) Vector {
 	return r.o.Add(r.d.Multiply(parameter))
 }
@@ -41,7 +69,13 @@ func (r ray) RGBA() (red, green, blue, alpha uint32) {
 func (r ray) Transform(transformation Matrix) (transformed Ray) {
 	o := transformation.MultiplyByVector(r.o)
 	d := transformation.MultiplyByVector(r.d)
-	return NewRayAt(o, d)
+	return &ray{
+		o:          o,
+		d:          d,
+		wavelength: r.wavelength,
+		time:       r.time,
+		bounce:     r.bounce,
+	}
 }
 
 type Ray interface {
@@ -49,6 +83,11 @@ type Ray interface {
 	Origin() Vector
 	Direction() Vector
 	PointAt(parameter float64) Vector
+	Wavelength() float64
+	Time() float64
+	SetTime(time float64)
+	Bounce() int
+	SetBounce(bounce int)
 	SetR(redVal uint8)
 	SetG(greenVal uint8)
 	SetB(blueVal uint8)
@@ -62,6 +101,16 @@ func NewRayAt(point, vector Vector) Ray {
 	}
 }
 
+// NewSpectralRay is a ray carrying a single wavelength of light, used to
+// split light up through dispersive materials.
+func NewSpectralRay(point, vector Vector, wavelength float64) Ray {
+	return &ray{
+		o:          point,
+		d:          vector,
+		wavelength: wavelength,
+	}
+}
+
 func NewRay(x, y, z float64, r, g, b uint8) Ray {
 	return &ray{
 		o: NewVec(x, y, z),
@@ -128,3 +128,28 @@ func TestRay_PointAt(t *testing.T) {
 		})
 	}
 }
+
+func TestRay_Wavelength(t *testing.T) {
+	assert.Equal(t, 0.0, ray.NewRayAt(ray.NewPoint(1, 2, 3), ray.NewVec(0, 1, 0)).Wavelength())
+
+	r := ray.NewSpectralRay(ray.NewPoint(1, 2, 3), ray.NewVec(0, 1, 0), 550)
+	assert.Equal(t, 550.0, r.Wavelength())
+	assert.Equal(t, ray.NewSpectralRay(ray.NewPoint(4, 6, 8), ray.NewVec(0, 1, 0), 550),
+		r.Transform(ray.Translation(3, 4, 5)), "transforming keeps the wavelength")
+}
+
+func TestRay_Time(t *testing.T) {
+	r := ray.NewRayAt(ray.NewPoint(1, 2, 3), ray.NewVec(0, 1, 0))
+	assert.Equal(t, 0.0, r.Time())
+	r.SetTime(0.25)
+	assert.Equal(t, 0.25, r.Time())
+	assert.Equal(t, 0.25, r.Transform(ray.Translation(3, 4, 5)).Time(), "transforming keeps the time")
+}
+
+func TestRay_Bounce(t *testing.T) {
+	r := ray.NewRayAt(ray.NewPoint(1, 2, 3), ray.NewVec(0, 1, 0))
+	assert.Equal(t, 0, r.Bounce())
+	r.SetBounce(2)
+	assert.Equal(t, 2, r.Bounce())
+	assert.Equal(t, 2, r.Transform(ray.Translation(3, 4, 5)).Bounce(), "transforming keeps the bounce")
+}
@@ -0,0 +1,21 @@
+// Package raytest has assertions shared by the tests of packages using
+// ray.
+package raytest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+// AssertMatrixEqual checks the matrices are the same, give or take the
+// rounding from building them different ways.
+func AssertMatrixEqual(t *testing.T, expected, actual ray.Matrix) {
+	require.Len(t, actual, len(expected))
+	for row := range expected {
+		assert.InDeltaSlice(t, expected[row], actual[row], 1e-9, "row %v", row)
+	}
+}
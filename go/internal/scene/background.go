@@ -0,0 +1,155 @@
+package scene
+
+import (
+	"math"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+// Background is what a ray sees when it misses everything in the world.
+type Background interface {
+	ColorFor(direction ray.Vector) object.RGB
+}
+
+type solidBackground struct {
+	color object.RGB
+}
+
+func NewSolidBackground(color object.RGB) Background {
+	return solidBackground{color: color}
+}
+
+func (b solidBackground) ColorFor(_ ray.Vector) object.RGB {
+	return b.color
+}
+
+type gradientBackground struct {
+	bottom, top object.RGB
+}
+
+// NewGradientBackground blends from bottom, looking straight down, to top,
+// looking straight up.
+func NewGradientBackground(bottom, top object.RGB) Background {
+	return gradientBackground{
+		bottom: bottom,
+		top:    top,
+	}
+}
+
+func (b gradientBackground) ColorFor(direction ray.Vector) object.RGB {
+	t := 0.5 * (direction.Normalize().GetY() + 1)
+	return b.bottom.MultiplyBy(1 - t).Add(b.top.MultiplyBy(t))
+}
+
+type skyBackground struct {
+	ground, horizon, zenith object.RGB
+}
+
+// NewSkyBackground blends from the horizon to the zenith above the horizon
+// and from the horizon to the ground below it.
+func NewSkyBackground(ground, horizon, zenith object.RGB) Background {
+	return skyBackground{
+		ground:  ground,
+		horizon: horizon,
+		zenith:  zenith,
+	}
+}
+
+func (b skyBackground) ColorFor(direction ray.Vector) object.RGB {
+	y := direction.Normalize().GetY()
+	if y < 0 {
+		return b.horizon.MultiplyBy(1 + y).Add(b.ground.MultiplyBy(-y))
+	}
+	return b.horizon.MultiplyBy(1 - y).Add(b.zenith.MultiplyBy(y))
+}
+
+type environmentMap struct {
+	image Canvas
+}
+
+// NewEnvironmentMap wraps an equirectangular (latitude/longitude) image
+// around the world with +z in the middle of the image, +x to its right and
+// +y at the top.
+func NewEnvironmentMap(image Canvas) Background {
+	return environmentMap{image: image}
+}
+
+func (b environmentMap) ColorFor(direction ray.Vector) object.RGB {
+	u, v := equirectangularUV(direction)
+	return b.image.Sample(u, v)
+}
+
+func equirectangularUV(direction ray.Vector) (u, v float64) {
+	d := direction.Normalize()
+	u = 0.5 + math.Atan2(d.GetX(), d.GetZ())/(2*math.Pi)
+	v = math.Acos(clamp(d.GetY(), -1, 1)) / math.Pi
+	return u, v
+}
+
+// equirectangularDirection is the inverse of equirectangularUV.
+func equirectangularDirection(u, v float64) ray.Vector {
+	phi := (u - 0.5) * 2 * math.Pi
+	theta := v * math.Pi
+	return ray.NewVec(
+		math.Sin(theta)*math.Sin(phi),
+		math.Cos(theta),
+		math.Sin(theta)*math.Cos(phi))
+}
+
+type CubeFace int
+
+const (
+	PositiveX CubeFace = iota
+	NegativeX
+	PositiveY
+	NegativeY
+	PositiveZ
+	NegativeZ
+)
+
+type cubeMap struct {
+	faces [6]Canvas
+}
+
+// NewCubeMap builds an environment from six square images, indexed by
+// CubeFace, as seen from inside the cube. The side faces have +y up and the
+// top and bottom faces join the +z face along their bottom and top edges.
+func NewCubeMap(faces [6]Canvas) Background {
+	return cubeMap{faces: faces}
+}
+
+func (b cubeMap) ColorFor(direction ray.Vector) object.RGB {
+	x, y, z := direction.GetX(), direction.GetY(), direction.GetZ()
+	absX, absY, absZ := math.Abs(x), math.Abs(y), math.Abs(z)
+
+	var face CubeFace
+	var u, v, major float64
+	switch {
+	case absX >= absY && absX >= absZ:
+		major = absX
+		if x > 0 {
+			face, u, v = PositiveX, -z, -y
+		} else {
+			face, u, v = NegativeX, z, -y
+		}
+	case absY >= absZ:
+		major = absY
+		if y > 0 {
+			face, u, v = PositiveY, x, z
+		} else {
+			face, u, v = NegativeY, x, -z
+		}
+	default:
+		major = absZ
+		if z > 0 {
+			face, u, v = PositiveZ, x, -y
+		} else {
+			face, u, v = NegativeZ, -x, -y
+		}
+	}
+	// the faces don't wrap, the texel across an edge is on another face
+	return b.faces[face].SampleClamped(
+		0.5*(u/major+1),
+		0.5*(v/major+1))
+}
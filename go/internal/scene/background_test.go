@@ -0,0 +1,140 @@
+package scene_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+func TestBackground_ColorFor(t *testing.T) {
+	equirectangular := scene.NewCanvas(4, 2)
+	equirectangular.SetColor(0, 0, object.Red)
+	equirectangular.SetColor(1, 0, object.Green)
+	equirectangular.SetColor(2, 0, object.Blue)
+	equirectangular.SetColor(3, 0, object.White)
+
+	var faces [6]scene.Canvas
+	for i := range faces {
+		faces[i] = scene.NewCanvas(1, 1)
+		faces[i].SetColor(0, 0, object.NewColor(float64(i), 0, 0))
+	}
+
+	testCases := []struct {
+		name       string
+		background scene.Background
+		direction  ray.Vector
+		expected   object.RGB
+	}{
+		{
+			name:       "A solid colour",
+			background: scene.NewSolidBackground(object.Red),
+			direction:  ray.NewVec(0, 0, 1),
+			expected:   object.Red,
+		},
+		{
+			name:       "A gradient looking up",
+			background: scene.NewGradientBackground(object.White, object.Blue),
+			direction:  ray.NewVec(0, 2, 0),
+			expected:   object.Blue,
+		},
+		{
+			name:       "A gradient looking at the horizon",
+			background: scene.NewGradientBackground(object.White, object.Blue),
+			direction:  ray.NewVec(0, 0, 1),
+			expected:   object.NewColor(0.5, 0.5, 1),
+		},
+		{
+			name:       "A sky looking down",
+			background: scene.NewSkyBackground(object.Black, object.White, object.Blue),
+			direction:  ray.NewVec(0, -1, 0),
+			expected:   object.Black,
+		},
+		{
+			name:       "A sky halfway up",
+			background: scene.NewSkyBackground(object.Black, object.White, object.Blue),
+			direction:  ray.NewVec(0, 1, math.Sqrt(3)),
+			expected:   object.NewColor(0.5, 0.5, 1),
+		},
+		{
+			name:       "An environment map looking behind",
+			background: scene.NewEnvironmentMap(equirectangular),
+			direction:  ray.NewVec(0, 1, -1),
+			expected:   object.NewColor(1, 0.5, 0.5),
+		},
+		{
+			name:       "An environment map looking to the left",
+			background: scene.NewEnvironmentMap(equirectangular),
+			direction:  ray.NewVec(-1, 1, 0),
+			expected:   object.NewColor(0.5, 0.5, 0),
+		},
+		{
+			name:       "An environment map looking ahead",
+			background: scene.NewEnvironmentMap(equirectangular),
+			direction:  ray.NewVec(0, 1, 1),
+			expected:   object.NewColor(0, 0.5, 0.5),
+		},
+		{name: "A cube map +x", background: scene.NewCubeMap(faces), direction: ray.NewVec(2, 1, 1), expected: object.NewColor(float64(scene.PositiveX), 0, 0)},
+		{name: "A cube map -x", background: scene.NewCubeMap(faces), direction: ray.NewVec(-2, 1, 1), expected: object.NewColor(float64(scene.NegativeX), 0, 0)},
+		{name: "A cube map +y", background: scene.NewCubeMap(faces), direction: ray.NewVec(1, 2, 1), expected: object.NewColor(float64(scene.PositiveY), 0, 0)},
+		{name: "A cube map -y", background: scene.NewCubeMap(faces), direction: ray.NewVec(1, -2, 1), expected: object.NewColor(float64(scene.NegativeY), 0, 0)},
+		{name: "A cube map +z", background: scene.NewCubeMap(faces), direction: ray.NewVec(1, 1, 2), expected: object.NewColor(float64(scene.PositiveZ), 0, 0)},
+		{name: "A cube map -z", background: scene.NewCubeMap(faces), direction: ray.NewVec(1, 1, -2), expected: object.NewColor(float64(scene.NegativeZ), 0, 0)},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assertColorEqual(t, tt.expected, tt.background.ColorFor(tt.direction))
+		})
+	}
+}
+
+func TestCubeMap_edgeTexels(t *testing.T) {
+	// the +z face is red on its left column and blue on its right
+	var faces [6]scene.Canvas
+	for i := range faces {
+		faces[i] = scene.NewCanvas(4, 4)
+	}
+	for y := 0; y < 4; y++ {
+		faces[scene.PositiveZ].SetColor(0, y, object.Red)
+		faces[scene.PositiveZ].SetColor(3, y, object.Blue)
+	}
+	b := scene.NewCubeMap(faces)
+
+	// just inside the left and right edges of the +z face
+	assertColorEqual(t, object.Red, b.ColorFor(ray.NewVec(-0.999, 0, 1)))
+	assertColorEqual(t, object.Blue, b.ColorFor(ray.NewVec(0.999, 0, 1)))
+}
+
+func TestWorld_ColorAt_background(t *testing.T) {
+	w, err := scene.DefaultWorld()
+	assert.NoError(t, err)
+	assert.Nil(t, w.Background())
+	r := ray.NewRayAt(ray.NewPoint(0, 0, -5), ray.NewVec(0, 1, 0))
+	assertColorEqual(t, object.Black, w.ColorAt(r, 1))
+
+	background := scene.NewSolidBackground(object.Red)
+	w.SetBackground(background)
+	assert.Equal(t, background, w.Background())
+	assertColorEqual(t, object.Red, w.ColorAt(r, 1))
+}
+
+func TestWorld_ColorAt_reflectedBackground(t *testing.T) {
+	w := scene.NewWorld()
+	w.AddLight(scene.DefaultWorldLight())
+	w.SetBackground(scene.NewGradientBackground(object.Black, object.White))
+	m := object.DefaultMaterial()
+	m.Color = object.Black
+	m.Ambient = 0
+	m.Diffuse = 0
+	m.Specular = 0
+	m.Reflective = 1
+	w.AddObject(object.NewPlane(object.WithMaterial(m)))
+
+	// a mirror floor shows the sky reflected straight back up
+	r := ray.NewRayAt(ray.NewPoint(0, 1, 0), ray.NewVec(0, -1, 0))
+	assertColorEqual(t, object.White, w.ColorAt(r, 2))
+}
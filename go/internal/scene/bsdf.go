@@ -0,0 +1,169 @@
+package scene
+
+import (
+	"math"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+// bsdf maps a Phong material onto lobes a path tracer can sample: a
+// lambertian diffuse lobe, a normalised Phong glossy lobe, a mirror and a
+// dielectric that reflects or refracts by Fresnel, the last two blurred by
+// the material roughness. PBR materials swap the diffuse and glossy lobes
+// for Cook-Torrance GGX.
+type bsdf struct {
+	comps     Computation
+	color     object.RGB
+	axis      ray.Vector
+	shininess float64
+	roughness float64
+
+	pbr                    *object.PBR
+	metallic, pbrRoughness float64
+
+	// the fraction of light each lobe scatters, whatever is left is absorbed
+	diffuse, glossy, mirror, transmit float64
+}
+
+type bsdfSample struct {
+	direction   ray.Vector
+	weight      object.RGB // f * cos / pdf
+	pdf         float64
+	delta       bool
+	transmitted bool
+}
+
+func newBSDF(comps Computation) bsdf {
+	m := comps.obj.Material()
+	// Phong happily reflects more light than arrives, a path tracer can't
+	kd, ks := math.Max(m.Diffuse, 0), math.Max(m.Specular, 0)
+	if kd+ks > 1 {
+		kd, ks = kd/(kd+ks), ks/(kd+ks)
+	}
+	transmit := clamp(m.Transparency, 0, 1)
+	mirror := (1 - transmit) * clamp(m.Reflective, 0, 1)
+	base := 1 - transmit - mirror
+
+	b := bsdf{
+		comps:     comps,
+		color:     m.ColorAt(comps.obj, comps.overPoint),
+		axis:      comps.reflectv.Normalize(),
+		shininess: m.Shininess,
+		roughness: m.Roughness,
+		diffuse:   base * kd,
+		glossy:    base * ks,
+		mirror:    mirror,
+		transmit:  transmit,
+	}
+	if m.PBR != nil {
+		b.pbr = m.PBR
+		b.metallic = m.PBR.MetallicAt(comps.obj, comps.overPoint)
+		b.pbrRoughness = m.PBR.RoughnessAt(comps.obj, comps.overPoint)
+		// the split only decides which lobe is sampled, metals are all specular
+		specular := 0.5 + 0.5*b.metallic
+		b.diffuse, b.glossy = base*(1-specular), base*specular
+	}
+	return b
+}
+
+func (b bsdf) total() float64 {
+	return b.diffuse + b.glossy + b.mirror + b.transmit
+}
+
+// IsDelta is true when the material only scatters in single directions, so
+// there's no point sampling lights for it.
+func (b bsdf) IsDelta() bool {
+	return b.diffuse+b.glossy <= 0
+}
+
+// Eval is the scattering toward direction from the lobes that aren't deltas.
+func (b bsdf) Eval(direction ray.Vector) (f object.RGB) {
+	if ray.Dot(direction, b.comps.normalv) <= 0 {
+		return object.Black
+	}
+	if b.pbr != nil {
+		return object.CookTorrance(b.color, b.metallic, b.pbrRoughness, b.pbr.Specular,
+			b.comps.normalv, b.comps.eyev, direction).
+			MultiplyBy(b.diffuse + b.glossy)
+	}
+	if b.diffuse > 0 {
+		f = b.color.MultiplyBy(b.diffuse / math.Pi)
+	}
+	if b.glossy > 0 {
+		if rde := ray.Dot(direction, b.axis); rde > 0 {
+			specular := b.glossy * (b.shininess + 2) / (2 * math.Pi) * math.Pow(rde, b.shininess)
+			f = f.Add(object.NewColor(specular, specular, specular))
+		}
+	}
+	return f
+}
+
+// Pdf is the density Sample picks direction with, ignoring the delta lobes.
+func (b bsdf) Pdf(direction ray.Vector) (pdf float64) {
+	total := b.total()
+	if total <= 0 {
+		return 0
+	}
+	if cos := ray.Dot(direction, b.comps.normalv); b.diffuse > 0 && cos > 0 {
+		pdf += b.diffuse / total * cos / math.Pi
+	}
+	if b.glossy > 0 {
+		if b.pbr != nil {
+			pdf += b.glossy / total * ggxPdf(b.comps.normalv, b.comps.eyev, direction, object.Alpha(b.pbrRoughness))
+		} else {
+			pdf += b.glossy / total * phongLobePdf(b.axis, direction, b.shininess)
+		}
+	}
+	return pdf
+}
+
+func (b bsdf) Sample(u0, u1, u2 float64) (s bsdfSample, ok bool) {
+	total := b.total()
+	if total <= 0 {
+		return s, false
+	}
+
+	pick := u0 * total
+	switch {
+	case pick < b.diffuse+b.glossy:
+		if pick < b.diffuse {
+			s.direction = sampleCosineHemisphere(b.comps.normalv, u1, u2)
+		} else if b.pbr != nil {
+			s.direction = sampleGGX(b.comps.normalv, b.comps.eyev, object.Alpha(b.pbrRoughness), u1, u2)
+		} else {
+			s.direction = samplePhongLobe(b.axis, b.shininess, u1, u2)
+		}
+		s.pdf = b.Pdf(s.direction)
+		cos := ray.Dot(s.direction, b.comps.normalv)
+		if s.pdf <= 0 || cos <= 0 {
+			return s, false
+		}
+		s.weight = b.Eval(s.direction).MultiplyBy(cos / s.pdf)
+		return s, true
+
+	case pick < b.diffuse+b.glossy+b.mirror:
+		// rough mirrors are still only lit through the rays they spawn
+		s.direction = glossyDirection(b.comps.reflectv, b.comps.normalv, b.roughness, u1, u2)
+		s.weight = object.White.MultiplyBy(total)
+		s.delta = true
+		return s, true
+
+	default:
+		s.delta = true
+		s.weight = object.White.MultiplyBy(total)
+		reflectance := 1.0
+		direction, ok := refractDirection(b.comps)
+		if ok {
+			reflectance = Schlick(b.comps)
+		}
+		if u1 >= reflectance {
+			// reuse what's left of u1 to jitter the frosted refraction
+			s.direction = glossyDirection(direction, b.comps.normalv, b.roughness, (u1-reflectance)/(1-reflectance), u2)
+			s.transmitted = true
+		} else {
+			s.direction = glossyDirection(b.comps.reflectv, b.comps.normalv, b.roughness, u1/reflectance, u2)
+		}
+		return s, true
+	}
+}
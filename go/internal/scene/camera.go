@@ -1,60 +1,122 @@
 package scene
 
 import (
+	"context"
 	"math"
-	"sync"
+	"math/rand"
 
 	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
 	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
 )
 
 func NewCamera(hSize, vSize int, from, to, vup ray.Vector) (c Camera, err error) {
-	return newCamera(hSize, vSize, math.Pi/2, ray.ViewTransform(from, to, vup))
+	return NewLookAtCamera(hSize, vSize, from, to, vup)
 }
 
 func NewBasicCamera(hSize, vSize int, fieldOfView float64) (c Camera, err error) {
-	return newCamera(hSize, vSize, fieldOfView, ray.IdentityMatrix(4, 4))
-}
-
-func newCamera(hSize, vSize int, fieldOfView float64, transform ray.Matrix) (c Camera, err error) {
-	pixelSize, halfWidth, halfHeight := calculatePixelSize(hSize, vSize, fieldOfView)
-	inverse, err := transform.Inverse()
-	return &camera{
-		hSize:            hSize,
-		vSize:            vSize,
-		fieldOfView:      fieldOfView,
-		origin:           ray.NewPoint(0, 0, 0),
-		focalLength:      1.0,
-		transform:        transform,
-		transformInverse: inverse,
-		pixelSize:        pixelSize,
-		halfWidth:        halfWidth,
-		halfHeight:       halfHeight,
-	}, err
+	return newCamera(Perspective, hSize, vSize, fieldOfView, ray.IdentityMatrix(4, 4))
+}
+
+// NewLookAtCamera is a perspective camera at from looking at to, with a 90°
+// field of view unless the options say otherwise.
+func NewLookAtCamera(hSize, vSize int, from, to, up ray.Vector, opts ...CameraOption) (c Camera, err error) {
+	c, err = newCamera(Perspective, hSize, vSize, math.Pi/2, ray.IdentityMatrix(4, 4))
+	if err != nil {
+		return nil, err
+	}
+	if err = c.SetLookAt(from, to, up); err != nil {
+		return nil, err
+	}
+	for i := range opts {
+		if err = opts[i](c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func newCamera(projection Projection, hSize, vSize int, fieldOfView float64, transform ray.Matrix) (c Camera, err error) {
+	cam := &camera{
+		projection:    projection,
+		hSize:         hSize,
+		vSize:         vSize,
+		fieldOfView:   fieldOfView,
+		origin:        ray.NewPoint(0, 0, 0),
+		focalLength:   1.0,
+		focalDistance: 1.0,
+	}
+	cam.update()
+	err = cam.SetTransform(transform)
+	return cam, err
 }
 
 type Camera interface {
 	HSize() int
 	VSize() int
 	Origin() ray.Vector
+	// Position is where the camera is in the world.
+	Position() ray.Vector
 	PixelSize() float64
 	FocalLength() float64
 	RayForPixel(nx, ny float64) ray.Ray
+	Projection() Projection
+	SetProjection(p Projection)
+	// FieldOfView is the angle seen across the longer side of the image,
+	// or the width of the view for orthographic cameras.
 	FieldOfView() float64
+	SetFieldOfView(fieldOfView float64)
+	SetHorizontalFieldOfView(fieldOfView float64)
+	SetVerticalFieldOfView(fieldOfView float64)
+	Transform() ray.Matrix
+	// SetTransform places the camera with a view transform, before any roll.
 	SetTransform(by ray.Matrix) error
+	// SetLookAt places the camera at from looking at to.
+	SetLookAt(from, to, up ray.Vector) error
+	// SetRoll turns the camera about the direction it's looking, anticlockwise
+	// from behind in radians.
+	SetRoll(roll float64) error
+	// SetLensShift moves the image across, as a fraction of its width and
+	// height, without turning the camera. Positive x is to the right and
+	// positive y is up, handy for keeping verticals straight in architecture.
+	SetLensShift(x, y float64)
+	Aperture() float64
+	FocalDistance() float64
+	// SetLens turns the pinhole into a thin lens with a radius of aperture,
+	// keeping things focalDistance in front of the camera sharp. A zero
+	// aperture is back to a pinhole with everything in focus.
+	SetLens(aperture, focalDistance float64)
+	// SetBokeh shapes the aperture as a polygon with blades sides, turned
+	// by rotation radians, for out of focus highlights. Fewer than three
+	// blades is a round aperture.
+	SetBokeh(blades int, rotation float64)
+	// Shutter is when the shutter opens and closes, each ray fired at a
+	// time in between so moving objects blur.
+	Shutter() (open, close float64)
+	SetShutter(open, close float64)
 }
 
 type camera struct {
+	projection       Projection
 	hSize            int
 	vSize            int
 	fieldOfView      float64
 	focalLength      float64
 	origin           ray.Vector
+	view             ray.Matrix
+	roll             float64
 	transform        ray.Matrix
 	transformInverse ray.Matrix
+	position         ray.Vector
 	pixelSize        float64
 	halfWidth        float64
 	halfHeight       float64
+	shiftX, shiftY   float64
+	aperture         float64
+	focalDistance    float64
+	blades           int
+	bladeRotation    float64
+	shutterOpen      float64
+	shutterClose     float64
 }
 
 func (c camera) HSize() int {
@@ -73,9 +135,96 @@ func (c camera) FieldOfView() float64 {
 	return c.fieldOfView
 }
 
-func calculatePixelSize(hSize, vSize int, fieldOfView float64) (pixelSize, halfWidth, halfHeight float64) {
-	// half_view ← tan(camera.field_of_view / 2)
-	halfView := math.Tan(fieldOfView / 2)
+func (c camera) Projection() Projection {
+	return c.projection
+}
+
+func (c *camera) SetProjection(p Projection) {
+	c.projection = p
+	c.update()
+}
+
+func (c *camera) SetFieldOfView(fieldOfView float64) {
+	c.fieldOfView = fieldOfView
+	c.update()
+}
+
+func (c *camera) SetHorizontalFieldOfView(fieldOfView float64) {
+	c.SetFieldOfView(c.acrossLongerSide(fieldOfView, c.hSize))
+}
+
+func (c *camera) SetVerticalFieldOfView(fieldOfView float64) {
+	c.SetFieldOfView(c.acrossLongerSide(fieldOfView, c.vSize))
+}
+
+// acrossLongerSide turns a field of view across size pixels into the one
+// across the longer side of the image.
+func (c camera) acrossLongerSide(fieldOfView float64, size int) float64 {
+	longer := c.hSize
+	if c.vSize > longer {
+		longer = c.vSize
+	}
+	ratio := float64(longer) / float64(size)
+	switch c.projection {
+	case Perspective:
+		return 2 * math.Atan(ratio*math.Tan(fieldOfView/2))
+	default:
+		// the others grow evenly across the image
+		return fieldOfView * ratio
+	}
+}
+
+func (c camera) Aperture() float64 {
+	return c.aperture
+}
+
+func (c camera) FocalDistance() float64 {
+	return c.focalDistance
+}
+
+func (c *camera) SetLens(aperture, focalDistance float64) {
+	c.aperture = math.Max(0, aperture)
+	if focalDistance > 0 {
+		c.focalDistance = focalDistance
+	}
+}
+
+func (c *camera) SetBokeh(blades int, rotation float64) {
+	c.blades = blades
+	c.bladeRotation = rotation
+}
+
+func (c camera) Shutter() (open, close float64) {
+	return c.shutterOpen, c.shutterClose
+}
+
+func (c *camera) SetShutter(open, close float64) {
+	c.shutterOpen = open
+	c.shutterClose = math.Max(open, close)
+}
+
+func (c *camera) SetLensShift(x, y float64) {
+	c.shiftX = x
+	c.shiftY = y
+}
+
+// update works out the size of the image plane after the projection or
+// field of view change.
+func (c *camera) update() {
+	var halfView float64
+	switch c.projection {
+	case Orthographic:
+		halfView = c.fieldOfView / 2
+	case Fisheye, Equirectangular:
+		// the plane runs from -1 to 1 across the longer side
+		halfView = 1
+	default:
+		halfView = math.Tan(c.fieldOfView / 2)
+	}
+	c.pixelSize, c.halfWidth, c.halfHeight = calculatePixelSize(c.hSize, c.vSize, halfView)
+}
+
+func calculatePixelSize(hSize, vSize int, halfView float64) (pixelSize, halfWidth, halfHeight float64) {
 	aspect := float64(hSize) / float64(vSize)
 	if aspect >= 1 {
 		halfWidth = halfView
@@ -92,23 +241,67 @@ func (c camera) PixelSize() float64 {
 	return c.pixelSize
 }
 
+func (c camera) Transform() ray.Matrix {
+	return c.transform
+}
+
 func (c *camera) SetTransform(by ray.Matrix) error {
-	c.transform = by
-	inverse, err := by.Inverse()
+	c.view = by
+	return c.orient()
+}
+
+func (c *camera) SetLookAt(from, to, up ray.Vector) error {
+	return c.SetTransform(ray.ViewTransform(from, to, up))
+}
+
+func (c *camera) SetRoll(roll float64) error {
+	c.roll = roll
+	return c.orient()
+}
+
+// orient works out the camera's transform from its view and roll, and
+// where that puts it in the world.
+func (c *camera) orient() error {
+	c.transform = c.view
+	if c.roll != 0 {
+		c.transform = ray.Rotation(ray.Z, c.roll).Multiply(c.view)
+	}
+	inverse, err := c.transform.Inverse()
 	c.transformInverse = inverse
+	if err == nil {
+		c.position = inverse.MultiplyByVector(c.origin)
+	}
 	return err
 }
 
+func (c camera) Position() ray.Vector {
+	return c.position
+}
+
 func (c camera) RayForPixel(nx, ny float64) ray.Ray {
-	// the offset from the edge of the canvas to the pixel's center
+	r := c.pixelRay(nx, ny)
+	r.SetTime(shutterTime(c.shutterOpen, c.shutterClose, rand.Float64()))
+	return r
+}
 
-	xOffset := (nx + 0.5) * c.pixelSize
-	yOffset := (ny + 0.5) * c.pixelSize
+// shutterTime is the time u of the way through the shutter.
+func shutterTime(open, close, u float64) float64 {
+	return open + u*(close-open)
+}
+
+func (c camera) pixelRay(nx, ny float64) ray.Ray {
+	switch c.projection {
+	case Orthographic:
+		return c.orthographicRay(nx, ny)
+	case Fisheye:
+		return c.fisheyeRay(nx, ny)
+	case Equirectangular:
+		return c.equirectangularRay(nx, ny)
+	}
 
 	// the untransformed coordinates of the pixel in world space.
 	// (remember that the camera looks toward -z, so +x is to the *left*.)
-	worldX := c.halfWidth - xOffset
-	worldY := c.halfHeight - yOffset
+	worldX, worldY := c.planeAt(nx, ny)
 
 	// using the camera matrix, transform the canvas point and the origin
 	// and then compute the ray's direction vector.
@@ -116,79 +309,101 @@ func (c camera) RayForPixel(nx, ny float64) ray.Ray {
 
 	// pixel ← inverse(camera.transform) * point(world_x, world_y, -1)
 	inv := c.transformInverse
+	if c.aperture > 0 {
+		return c.lensRay(inv, worldX, worldY)
+	}
 	pixel := inv.MultiplyByVector(ray.NewPoint(worldX, worldY, -c.focalLength))
-	origin := inv.MultiplyByVector(c.origin)
-	// lower_left_corner = origin - horizontal/2 - vertical/2 - vec3(0, 0, focal_length)
-	// lower_left_corner + u*horizontal + v*vertical - origin
 
 	// direction ← normalize(pixel - origin)
-	direction := pixel.Subtract(origin).Normalize()
-	return ray.NewRayAt(origin, direction)
+	direction := pixel.Subtract(c.position).Normalize()
+	return ray.NewRayAt(c.position, direction)
+}
+
+// planeAt is where a pixel's centre is on the camera's image plane, +x on
+// the left as the camera looks down -z.
+func (c camera) planeAt(nx, ny float64) (x, y float64) {
+	// the offset from the edge of the canvas to the pixel's center
+	xOffset := (nx + 0.5) * c.pixelSize
+	yOffset := (ny + 0.5) * c.pixelSize
+	return c.halfWidth*(1-2*c.shiftX) - xOffset, c.halfHeight*(1+2*c.shiftY) - yOffset
+}
+
+// lensRay starts the ray from a random point on the aperture and aims it
+// where the pinhole ray crosses the plane of focus, so only things at the
+// focal distance stay sharp.
+func (c camera) lensRay(inv ray.Matrix, worldX, worldY float64) ray.Ray {
+	scale := c.focalDistance / c.focalLength
+	focus := inv.MultiplyByVector(ray.NewPoint(worldX*scale, worldY*scale, -c.focalDistance))
+
+	var lx, ly float64
+	if c.blades >= 3 {
+		lx, ly = samplePolygon(c.blades, c.bladeRotation, rand.Float64(), rand.Float64())
+	} else {
+		lx, ly = sampleDisk(rand.Float64(), rand.Float64())
+	}
+	origin := inv.MultiplyByVector(c.origin.Add(ray.NewVec(lx*c.aperture, ly*c.aperture, 0)))
+	return ray.NewRayAt(origin, focus.Subtract(origin).Normalize())
 }
 
 func (c camera) Origin() ray.Vector {
 	return c.origin
 }
 
-func MultiThreadedRender(c Camera, w World, noOfWorkers, queueSize int) Canvas {
-	canvas := NewCanvas(c.HSize(), c.VSize())
-	const cl = 255.99
-	type result struct {
-		color object.RGB
-		x, y  int
-	}
-	ch := make(chan result, queueSize)
-	calcCh := make(chan result, queueSize)
-	done := make(chan bool)
-	workersWg := sync.WaitGroup{}
-	workersWg.Add(noOfWorkers)
-
-	canvasWg := sync.WaitGroup{}
-	canvasWg.Add(c.HSize() * c.VSize())
-
-	go func() {
-		for out := range ch {
-			canvas[out.x][out.y] = out.color
-			canvasWg.Done()
-		}
+// RenderSettings controls how each pixel is worked out.
+type RenderSettings struct {
+	Integrator Integrator
+	// Samples is the number of rays per pixel, more than one jitters them
+	// across the pixel for anti-aliasing and to let stochastic effects converge.
+	Samples int
+}
 
-		done <- true
-	}()
-
-	for workers := 0; workers < noOfWorkers; workers++ {
-		go func() {
-			for res := range calcCh {
-				r := c.RayForPixel(float64(res.x), float64(res.y))
-				res.color = w.ColorAt(r, defaultRecursiveDepth)
-				ch <- res
-			}
-			workersWg.Done()
-		}()
-	}
-	for y := 0; y < c.VSize()-1; y++ {
-		for x := 0; x < c.HSize()-1; x++ {
-			calcCh <- result{
-				x: x,
-				y: y,
-			}
-		}
+func DefaultRenderSettings() RenderSettings {
+	return RenderSettings{
+		Integrator: NewWhittedIntegrator(),
+		Samples:    1,
 	}
-	close(calcCh)
-	workersWg.Wait()
-	canvasWg.Done()
-	close(ch)
-	<-done
-	close(done)
+}
+
+// RenderPixel averages the settings' samples for a single pixel.
+func RenderPixel(c Camera, w World, settings RenderSettings, x, y int) (color object.RGB) {
+	if settings.Samples <= 1 {
+		return settings.Integrator.Li(w, c.RayForPixel(float64(x), float64(y)))
+	}
+	return samplePixel(c, w, settings, x, y).MultiplyBy(1 / float64(settings.Samples))
+}
+
+// samplePixel adds up the settings' samples for a single pixel, always
+// jittered across it, even just the one.
+func samplePixel(c Camera, w World, settings RenderSettings, x, y int) (color object.RGB) {
+	open, close := c.Shutter()
+	for i := 0; i < settings.Samples; i++ {
+		r := c.RayForPixel(float64(x)+rand.Float64()-0.5, float64(y)+rand.Float64()-0.5)
+		// spread the samples evenly across the shutter
+		r.SetTime(shutterTime(open, close, (float64(i)+rand.Float64())/float64(settings.Samples)))
+		color = color.Add(settings.Integrator.Li(w, r))
+	}
+	return color
+}
+
+func MultiThreadedRender(c Camera, w World, noOfWorkers int) Canvas {
+	return MultiThreadedRenderWith(c, w, DefaultRenderSettings(), noOfWorkers)
+}
+
+// MultiThreadedRenderWith renders the image in tiles across the workers.
+func MultiThreadedRenderWith(c Camera, w World, settings RenderSettings, noOfWorkers int) Canvas {
+	canvas, _ := RenderTiles(context.Background(), c, w, settings, WithWorkers(noOfWorkers))
 	return canvas
 }
 
 func Render(c Camera, w World) Canvas {
+	return RenderWith(c, w, DefaultRenderSettings())
+}
+
+func RenderWith(c Camera, w World, settings RenderSettings) Canvas {
 	canvas := NewCanvas(c.HSize(), c.VSize())
-	const cl = 255.99
-	for y := 0; y < c.VSize()-1; y++ {
-		for x := 0; x < c.HSize()-1; x++ {
-			r := c.RayForPixel(float64(x), float64(y))
-			canvas[x][y] = w.ColorAt(r, defaultRecursiveDepth)
+	for y := 0; y < c.VSize(); y++ {
+		for x := 0; x < c.HSize(); x++ {
+			canvas[x][y] = RenderPixel(c, w, settings, x, y)
 		}
 	}
 	return canvas
@@ -0,0 +1,86 @@
+package scene
+
+import (
+	"fmt"
+	"math"
+)
+
+// CameraOption sets up a camera made by NewLookAtCamera.
+type CameraOption func(c Camera) error
+
+func WithProjection(p Projection) CameraOption {
+	return func(c Camera) error {
+		c.SetProjection(p)
+		return nil
+	}
+}
+
+// WithFieldOfView is the angle across the longer side of the image.
+func WithFieldOfView(fieldOfView float64) CameraOption {
+	return func(c Camera) error {
+		c.SetFieldOfView(fieldOfView)
+		return nil
+	}
+}
+
+func WithHorizontalFieldOfView(fieldOfView float64) CameraOption {
+	return func(c Camera) error {
+		c.SetHorizontalFieldOfView(fieldOfView)
+		return nil
+	}
+}
+
+func WithVerticalFieldOfView(fieldOfView float64) CameraOption {
+	return func(c Camera) error {
+		c.SetVerticalFieldOfView(fieldOfView)
+		return nil
+	}
+}
+
+// WithFocalLength sets the field of view like a real camera, with a lens of
+// focalLength millimetres on a sensorWidth by sensorHeight sensor (36 by 24
+// for full frame). The image fills the sensor's width unless it's taller
+// than the sensor, when it fills the height instead.
+func WithFocalLength(focalLength, sensorWidth, sensorHeight float64) CameraOption {
+	return func(c Camera) error {
+		if focalLength <= 0 || sensorWidth <= 0 || sensorHeight <= 0 {
+			return fmt.Errorf("focal length and sensor size must be positive, got %vmm on %vx%vmm",
+				focalLength, sensorWidth, sensorHeight)
+		}
+		aspect := float64(c.HSize()) / float64(c.VSize())
+		if aspect >= sensorWidth/sensorHeight {
+			c.SetHorizontalFieldOfView(2 * math.Atan(sensorWidth/(2*focalLength)))
+		} else {
+			c.SetVerticalFieldOfView(2 * math.Atan(sensorHeight/(2*focalLength)))
+		}
+		return nil
+	}
+}
+
+// WithRoll turns the camera anticlockwise, as seen from behind, about the direction it's looking.
+func WithRoll(roll float64) CameraOption {
+	return func(c Camera) error {
+		return c.SetRoll(roll)
+	}
+}
+
+func WithLensShift(x, y float64) CameraOption {
+	return func(c Camera) error {
+		c.SetLensShift(x, y)
+		return nil
+	}
+}
+
+func WithLens(aperture, focalDistance float64) CameraOption {
+	return func(c Camera) error {
+		c.SetLens(aperture, focalDistance)
+		return nil
+	}
+}
+
+func WithShutter(open, close float64) CameraOption {
+	return func(c Camera) error {
+		c.SetShutter(open, close)
+		return nil
+	}
+}
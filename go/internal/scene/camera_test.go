@@ -83,6 +83,60 @@ func assertVectorEqual(t *testing.T, expected ray.Vector, actual ray.Vector) {
 	assert.InDelta(t, expected.GetW(), actual.GetW(), 0.0001)
 }
 
+func TestCamera_SetLens(t *testing.T) {
+	c, err := scene.NewBasicCamera(201, 101, math.Pi/2)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, c.Aperture(), "pinhole by default")
+	assert.Equal(t, 1.0, c.FocalDistance())
+
+	testCases := []struct {
+		name     string
+		blades   int
+		onLens   func(x, y float64) bool
+		aperture float64
+	}{
+		{
+			name:     "round aperture",
+			aperture: 0.5,
+			onLens: func(x, y float64) bool {
+				return math.Hypot(x, y) <= 0.5+1e-9
+			},
+		},
+		{
+			name:     "square bokeh",
+			aperture: 0.5,
+			blades:   4,
+			onLens: func(x, y float64) bool {
+				return math.Abs(x)+math.Abs(y) <= 0.5+1e-9
+			},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := scene.NewBasicCamera(201, 101, math.Pi/2)
+			require.NoError(t, err)
+			require.NoError(t, c.SetTransform(ray.Translation(0, 0, -2)))
+			c.SetLens(tt.aperture, 5)
+			c.SetBokeh(tt.blades, 0)
+			assert.Equal(t, tt.aperture, c.Aperture())
+			assert.Equal(t, 5.0, c.FocalDistance())
+
+			// the corner pixel's pinhole ray crosses the plane of focus here
+			pinhole := ray.NewPoint(5*200.0/201, 5*100.0/201, -3)
+			origins := map[ray.Vector]bool{}
+			for i := 0; i < 100; i++ {
+				r := c.RayForPixel(0, 0)
+				o := r.Origin()
+				assert.InDelta(t, 2, o.GetZ(), 1e-9, "starts on the lens")
+				assert.True(t, tt.onLens(o.GetX(), o.GetY()), "starts within the aperture")
+				assertVectorEqual(t, pinhole, r.PointAt((pinhole.GetZ()-o.GetZ())/r.Direction().GetZ()))
+				origins[o] = true
+			}
+			assert.Greater(t, len(origins), 1, "spread over the lens")
+		})
+	}
+}
+
 func TestCamera_PixelSize(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -128,7 +182,7 @@ func TestRender(t *testing.T) {
 
 func TestMultiThreadedRender(t *testing.T) {
 	w, c := setupRenderTest(t)
-	img := scene.MultiThreadedRender(c, w, 10, 100)
+	img := scene.MultiThreadedRender(c, w, 10)
 	assertRenderImg(t, img)
 }
 
@@ -159,7 +213,7 @@ func canvasRender(world scene.World, camera scene.Camera) func() scene.Canvas {
 
 func canvasMultiThreadedRender(world scene.World, camera scene.Camera) func() scene.Canvas {
 	return func() scene.Canvas {
-		return scene.MultiThreadedRender(camera, world, 10, 100)
+		return scene.MultiThreadedRender(camera, world, 10)
 	}
 }
 
@@ -295,3 +349,188 @@ func testDefaultWorld(tb testing.TB) (world scene.World) {
 	require.NoError(tb, err)
 	return world
 }
+
+func TestNewLookAtCamera(t *testing.T) {
+	from, to := ray.NewPoint(1, 2, 3), ray.NewPoint(1, 2, 0)
+	testCases := []struct {
+		name        string
+		hSize       int
+		vSize       int
+		opts        []scene.CameraOption
+		fieldOfView float64
+		nx, ny      float64
+		direction   ray.Vector
+	}{
+		{
+			name:        "90° by default",
+			hSize:       200,
+			vSize:       100,
+			fieldOfView: math.Pi / 2,
+			nx:          99.5,
+			ny:          49.5,
+			direction:   ray.NewVec(0, 0, -1),
+		},
+		{
+			name:        "vertical field of view",
+			hSize:       200,
+			vSize:       100,
+			opts:        []scene.CameraOption{scene.WithVerticalFieldOfView(math.Pi / 3)},
+			fieldOfView: 2 * math.Atan(2*math.Tan(math.Pi/6)),
+			nx:          99.5,
+			ny:          -0.5,
+			direction:   ray.NewVec(0, math.Sin(math.Pi/6), -math.Cos(math.Pi/6)),
+		},
+		{
+			name:        "horizontal field of view",
+			hSize:       100,
+			vSize:       200,
+			opts:        []scene.CameraOption{scene.WithHorizontalFieldOfView(math.Pi / 3)},
+			fieldOfView: 2 * math.Atan(2*math.Tan(math.Pi/6)),
+			nx:          -0.5,
+			ny:          99.5,
+			direction:   ray.NewVec(math.Sin(math.Pi/6), 0, -math.Cos(math.Pi/6)),
+		},
+		{
+			name:        "focal length fills the sensor's width",
+			hSize:       300,
+			vSize:       200,
+			opts:        []scene.CameraOption{scene.WithFocalLength(50, 36, 24)},
+			fieldOfView: 2 * math.Atan(18.0/50),
+			nx:          -0.5,
+			ny:          99.5,
+			direction:   ray.NewVec(18, 0, -50).Normalize(),
+		},
+		{
+			name:        "focal length fills the sensor's height when taller",
+			hSize:       100,
+			vSize:       200,
+			opts:        []scene.CameraOption{scene.WithFocalLength(50, 36, 24)},
+			fieldOfView: 2 * math.Atan(12.0/50),
+			nx:          49.5,
+			ny:          -0.5,
+			direction:   ray.NewVec(0, 12, -50).Normalize(),
+		},
+		{
+			name:        "roll turns the image",
+			hSize:       200,
+			vSize:       200,
+			opts:        []scene.CameraOption{scene.WithRoll(math.Pi / 2)},
+			fieldOfView: math.Pi / 2,
+			nx:          99.5,
+			ny:          -0.5,
+			direction:   ray.NewVec(1, 0, -1).Normalize(),
+		},
+		{
+			name:        "lens shift moves the image up",
+			hSize:       200,
+			vSize:       200,
+			opts:        []scene.CameraOption{scene.WithLensShift(0, 0.25)},
+			fieldOfView: math.Pi / 2,
+			nx:          99.5,
+			ny:          99.5,
+			direction:   ray.NewVec(0, 0.5, -1).Normalize(),
+		},
+		{
+			name:        "lens shift moves the image right",
+			hSize:       200,
+			vSize:       200,
+			opts:        []scene.CameraOption{scene.WithLensShift(0.25, 0)},
+			fieldOfView: math.Pi / 2,
+			nx:          99.5,
+			ny:          99.5,
+			direction:   ray.NewVec(-0.5, 0, -1).Normalize(),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := scene.NewLookAtCamera(tt.hSize, tt.vSize, from, to, ray.NewVec(0, 1, 0), tt.opts...)
+			require.NoError(t, err)
+			assertVectorEqual(t, from, c.Position())
+			assert.InDelta(t, tt.fieldOfView, c.FieldOfView(), 1e-9)
+			r := c.RayForPixel(tt.nx, tt.ny)
+			assertVectorEqual(t, from, r.Origin())
+			assertVectorEqual(t, tt.direction, r.Direction())
+		})
+	}
+
+	t.Run("bad focal length", func(t *testing.T) {
+		_, err := scene.NewLookAtCamera(200, 100, from, to, ray.NewVec(0, 1, 0), scene.WithFocalLength(0, 36, 24))
+		assert.Error(t, err)
+	})
+}
+
+func TestCamera_setters(t *testing.T) {
+	c, err := scene.NewLookAtCamera(200, 200, ray.ZeroPoint, ray.NewPoint(0, 0, -1), ray.NewVec(0, 1, 0),
+		scene.WithRoll(math.Pi/2))
+	require.NoError(t, err)
+
+	t.Run("moving keeps the roll", func(t *testing.T) {
+		require.NoError(t, c.SetLookAt(ray.NewPoint(0, 0, 5), ray.ZeroPoint, ray.NewVec(0, 1, 0)))
+		assertVectorEqual(t, ray.NewPoint(0, 0, 5), c.Position())
+		r := c.RayForPixel(99.5, -0.5)
+		assertVectorEqual(t, ray.NewPoint(0, 0, 5), r.Origin())
+		assertVectorEqual(t, ray.NewVec(1, 0, -1).Normalize(), r.Direction())
+	})
+
+	t.Run("changing the field of view resizes the pixels", func(t *testing.T) {
+		c.SetFieldOfView(math.Pi / 2)
+		assert.InDelta(t, 0.01, c.PixelSize(), 1e-9)
+		c.SetVerticalFieldOfView(2 * math.Atan(0.5))
+		assert.InDelta(t, 0.005, c.PixelSize(), 1e-9)
+	})
+
+	t.Run("changing the projection", func(t *testing.T) {
+		c.SetProjection(scene.Orthographic)
+		c.SetFieldOfView(4)
+		assert.Equal(t, scene.Orthographic, c.Projection())
+		assert.InDelta(t, 0.02, c.PixelSize(), 1e-9)
+		r := c.RayForPixel(99.5, 99.5)
+		assertVectorEqual(t, ray.NewPoint(0, 0, 5), r.Origin())
+		assertVectorEqual(t, ray.NewVec(0, 0, -1), r.Direction())
+	})
+
+	t.Run("unrolling", func(t *testing.T) {
+		require.NoError(t, c.SetRoll(0))
+		assert.Equal(t, ray.ViewTransform(ray.NewPoint(0, 0, 5), ray.ZeroPoint, ray.NewVec(0, 1, 0)), c.Transform())
+	})
+}
+
+func TestCamera_SetShutter(t *testing.T) {
+	c, err := scene.NewBasicCamera(11, 11, math.Pi/2)
+	require.NoError(t, err)
+	open, closed := c.Shutter()
+	assert.Equal(t, 0.0, open)
+	assert.Equal(t, 0.0, closed)
+	assert.Equal(t, 0.0, c.RayForPixel(5, 5).Time(), "an instant by default")
+
+	c.SetShutter(0.25, 0.75)
+	for i := 0; i < 100; i++ {
+		time := c.RayForPixel(5, 5).Time()
+		assert.GreaterOrEqual(t, time, 0.25)
+		assert.LessOrEqual(t, time, 0.75)
+	}
+
+	t.Run("moving objects blur", func(t *testing.T) {
+		m := object.DefaultMaterial()
+		m.Emission = object.White
+		m.EmissionStrength = 1
+		m.Ambient, m.Diffuse, m.Specular = 0, 0, 0
+		w := scene.NewWorld()
+		// in front of the camera for the first half of the shutter only
+		w.AddObject(object.DefaultSphere(
+			object.WithMaterial(m),
+			object.WithKeyframes(
+				object.Keyframe{Time: 0, Transform: ray.Translation(0, 0, -5)},
+				object.Keyframe{Time: 0.5, Transform: ray.Translation(0, 0, -5)},
+				object.Keyframe{Time: 0.5001, Transform: ray.Translation(100, 0, -5)})))
+
+		c.SetShutter(0, 1)
+		settings := scene.DefaultRenderSettings()
+		settings.Samples = 64
+		color := scene.RenderPixel(c, w, settings, 5, 5)
+		assert.InDelta(t, 0.5, color.R, 0.05)
+
+		c.SetShutter(0, 0.4)
+		assertColorEqual(t, object.White, scene.RenderPixel(c, w, settings, 5, 5))
+	})
+}
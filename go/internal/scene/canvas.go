@@ -30,6 +30,64 @@ func (c Canvas) SetColor(x, y int, col object.RGB) {
 	c[x][y] = col
 }
 
+func (c Canvas) Width() int {
+	return len(c)
+}
+
+func (c Canvas) Height() int {
+	if len(c) == 0 {
+		return 0
+	}
+	return len(c[0])
+}
+
+// Sample bilinearly filters the canvas at u, v between 0 and 1, wrapping
+// around horizontally and clamping vertically.
+func (c Canvas) Sample(u, v float64) object.RGB {
+	return c.sample(u, v, true)
+}
+
+// SampleClamped is Sample clamping to the edges in both directions, for
+// images that don't join up with themselves like the faces of a cube map.
+func (c Canvas) SampleClamped(u, v float64) object.RGB {
+	return c.sample(clamp(u, 0, 1), v, false)
+}
+
+func (c Canvas) sample(u, v float64, wrap bool) object.RGB {
+	x := u*float64(c.Width()) - 0.5
+	y := clamp(v, 0, 1)*float64(c.Height()) - 0.5
+	x0 := math.Floor(x)
+	y0 := math.Floor(y)
+	fx := x - x0
+	fy := y - y0
+
+	at := func(x, y int) object.RGB {
+		if wrap {
+			x = ((x % c.Width()) + c.Width()) % c.Width()
+		} else {
+			x = int(clamp(float64(x), 0, float64(c.Width()-1)))
+		}
+		y = int(clamp(float64(y), 0, float64(c.Height()-1)))
+		return c[x][y]
+	}
+	top := at(int(x0), int(y0)).MultiplyBy(1 - fx).Add(at(int(x0)+1, int(y0)).MultiplyBy(fx))
+	bottom := at(int(x0), int(y0)+1).MultiplyBy(1 - fx).Add(at(int(x0)+1, int(y0)+1).MultiplyBy(fx))
+	return top.MultiplyBy(1 - fy).Add(bottom.MultiplyBy(fy))
+}
+
+func CanvasFromImage(img image.Image) Canvas {
+	const maxC = 0xffff
+	bounds := img.Bounds()
+	c := NewCanvas(bounds.Dx(), bounds.Dy())
+	for x := 0; x < bounds.Dx(); x++ {
+		for y := 0; y < bounds.Dy(); y++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			c[x][y] = object.NewColor(float64(r)/maxC, float64(g)/maxC, float64(b)/maxC)
+		}
+	}
+	return c
+}
+
 func (c Canvas) GenerateImg() (img *image.RGBA) {
 	const maxC = 255
 	const min = 0.0
@@ -57,3 +57,44 @@ func TestNewCanvas(t *testing.T) {
 		A: 0,
 	}, img.At(1, 1))
 }
+
+func TestCanvas_Sample(t *testing.T) {
+	c := scene.NewCanvas(2, 2)
+	c.SetColor(0, 0, object.Red)
+	c.SetColor(1, 0, object.Green)
+	c.SetColor(0, 1, object.Blue)
+	c.SetColor(1, 1, object.White)
+
+	testCases := []struct {
+		name     string
+		u, v     float64
+		expected object.RGB
+	}{
+		{name: "Centre of a pixel", u: 0.25, v: 0.25, expected: object.Red},
+		{name: "Between two pixels", u: 0.5, v: 0.25, expected: object.NewColor(0.5, 0.5, 0)},
+		{name: "Between four pixels", u: 0.5, v: 0.5, expected: object.NewColor(0.5, 0.5, 0.5)},
+		{name: "Wraps around horizontally", u: 0, v: 0.25, expected: object.NewColor(0.5, 0.5, 0)},
+		{name: "Clamps vertically", u: 0.75, v: 1.5, expected: object.White},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assertColorEqual(t, tt.expected, c.Sample(tt.u, tt.v))
+		})
+	}
+
+	t.Run("Clamped doesn't wrap around", func(t *testing.T) {
+		assertColorEqual(t, object.Red, c.SampleClamped(0, 0.25))
+		assertColorEqual(t, object.Green, c.SampleClamped(1, 0.25))
+		assertColorEqual(t, object.NewColor(0.5, 0.5, 0), c.SampleClamped(0.5, 0.25))
+	})
+}
+
+func TestCanvasFromImage(t *testing.T) {
+	c := scene.NewCanvas(3, 2)
+	c.SetColor(0, 0, object.Red)
+	c.SetColor(2, 1, object.White)
+	actual := scene.CanvasFromImage(c.GenerateImg())
+	assert.Equal(t, 3, actual.Width())
+	assert.Equal(t, 2, actual.Height())
+	assert.Equal(t, c, actual)
+}
@@ -0,0 +1,98 @@
+package scene
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+)
+
+const checkpointVersion = 1
+
+// ErrCheckpointMismatch is returned when a checkpoint is for a different
+// scene or settings than the render resuming it.
+var ErrCheckpointMismatch = errors.New("checkpoint is for a different scene or settings")
+
+type checkpoint struct {
+	Version       int
+	Hash          string
+	Width, Height int
+	Sum           []object.RGB
+	Samples       []int
+}
+
+// WriteCheckpoint saves the samples so far, with a hash of the scene and
+// settings they were rendered with so they're only resumed with the same.
+func (a *Accumulator) WriteCheckpoint(w io.Writer, hash string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return gob.NewEncoder(w).Encode(checkpoint{
+		Version: checkpointVersion,
+		Hash:    hash,
+		Width:   a.width,
+		Height:  a.height,
+		Sum:     a.sum,
+		Samples: a.samples,
+	})
+}
+
+// ReadCheckpoint loads the samples WriteCheckpoint saved, as long as they
+// were rendered with the scene and settings hash describes.
+func ReadCheckpoint(r io.Reader, hash string) (*Accumulator, error) {
+	var cp checkpoint
+	if err := gob.NewDecoder(r).Decode(&cp); err != nil {
+		return nil, fmt.Errorf("reading checkpoint: %w", err)
+	}
+	if cp.Version != checkpointVersion {
+		return nil, fmt.Errorf("checkpoint version %v, expected %v", cp.Version, checkpointVersion)
+	}
+	if cp.Hash != hash {
+		return nil, ErrCheckpointMismatch
+	}
+	if cp.Width*cp.Height != len(cp.Sum) || len(cp.Sum) != len(cp.Samples) {
+		return nil, errors.New("checkpoint is corrupt, its buffers aren't the image's size")
+	}
+	return &Accumulator{
+		width:   cp.Width,
+		height:  cp.Height,
+		sum:     cp.Sum,
+		samples: cp.Samples,
+	}, nil
+}
+
+// SaveCheckpoint writes a checkpoint to fname, through a temporary file so
+// a crash part way through leaves the last one whole.
+func (a *Accumulator) SaveCheckpoint(fname, hash string) (err error) {
+	part := fname + ".part"
+	f, err := os.Create(part)
+	if err != nil {
+		return err
+	}
+	if err = a.WriteCheckpoint(f, hash); err != nil {
+		_ = f.Close()
+		_ = os.Remove(part)
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(part, fname)
+}
+
+func LoadCheckpoint(fname, hash string) (*Accumulator, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return ReadCheckpoint(f, hash)
+}
@@ -0,0 +1,93 @@
+package scene_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+func TestAccumulator_WriteCheckpoint(t *testing.T) {
+	a := scene.NewAccumulator(3, 2)
+	a.Add(2, 1, object.NewColor(1, 2, 3), 4)
+	a.Add(0, 0, object.NewColor(0.5, 0.5, 0.5), 1)
+
+	var buf bytes.Buffer
+	require.NoError(t, a.WriteCheckpoint(&buf, "scene"))
+	saved := buf.Bytes()
+
+	testCases := []struct {
+		name    string
+		data    []byte
+		hash    string
+		wantErr bool
+		errIs   error
+	}{
+		{
+			name: "same scene",
+			data: saved,
+			hash: "scene",
+		},
+		{
+			name:    "changed scene",
+			data:    saved,
+			hash:    "another scene",
+			wantErr: true,
+			errIs:   scene.ErrCheckpointMismatch,
+		},
+		{
+			name:    "truncated",
+			data:    saved[:len(saved)/2],
+			hash:    "scene",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := scene.ReadCheckpoint(bytes.NewReader(tc.data), tc.hash)
+			if tc.wantErr {
+				assert.Error(t, err)
+				if tc.errIs != nil {
+					assert.ErrorIs(t, err, tc.errIs)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 3, got.Width())
+			assert.Equal(t, 2, got.Height())
+			assert.Equal(t, 4, got.Samples(2, 1))
+			assert.Equal(t, 1, got.Samples(0, 0))
+			assert.Equal(t, a.Canvas(), got.Canvas())
+		})
+	}
+}
+
+func TestSaveCheckpoint_resume(t *testing.T) {
+	w, c := progressiveTestScene(t)
+	fname := filepath.Join(t.TempDir(), "render.checkpoint")
+
+	acc, err := scene.RenderProgressive(context.Background(), c, w, scene.DefaultRenderSettings(),
+		scene.ProgressiveSettings{Samples: 2}, scene.WithWorkers(2))
+	require.NoError(t, err)
+	require.NoError(t, acc.SaveCheckpoint(fname, "scene"))
+	assert.NoFileExists(t, fname+".part")
+
+	_, err = scene.LoadCheckpoint(fname, "changed")
+	assert.ErrorIs(t, err, scene.ErrCheckpointMismatch)
+
+	loaded, err := scene.LoadCheckpoint(fname, "scene")
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded.MinSamples())
+
+	acc, err = scene.ResumeProgressive(context.Background(), loaded, c, w, scene.DefaultRenderSettings(),
+		scene.ProgressiveSettings{Samples: 5}, scene.WithWorkers(2))
+	require.NoError(t, err)
+	assert.Equal(t, 5, acc.MinSamples())
+	assertColorEqual(t, object.NewColor(0.5, 0.5, 0.5), acc.Canvas()[4][2])
+}
@@ -23,6 +23,13 @@ type Computation struct {
 	inside     bool
 	n1         float64
 	n2         float64
+	wavelength float64
+	// when in the shutter the ray was fired
+	time float64
+	// distance the ray travelled to reach the point
+	distance float64
+	// surfaces bounced off before this one
+	bounce int
 }
 
 func (c Computation) Intersect() float64 {
@@ -78,21 +85,37 @@ func contains(objs []object.Object, obj object.Object) (found bool, idx int) {
 	return false, 0
 }
 
+// spawn starts a new ray from the hit, keeping the wavelength and time
+// and counting the bounce.
+func (c Computation) spawn(origin, direction ray.Vector) ray.Ray {
+	r := ray.NewSpectralRay(origin, direction, c.wavelength)
+	r.SetTime(c.time)
+	r.SetBounce(c.bounce + 1)
+	return r
+}
+
 func PrepareComputations(i object.Intersection, r ray.Ray, xs ...object.Intersection) (comps Computation) {
 
 	comps.t = i.T
 	comps.obj = i.Obj
 	comps.point = r.PointAt(comps.t)
 	comps.eyev = r.Direction().Negate()
-	comps.normalv = object.NormalAt(i, comps.point)
-	comps.reflectv = r.Direction().Reflect(comps.normalv)
-
-	if ray.Dot(comps.normalv, comps.eyev) < 0 {
+	geometric := object.NormalAt(i, comps.point)
+	comps.normalv = comps.obj.Material().PerturbNormal(i, comps.point, geometric)
+	comps.wavelength = r.Wavelength()
+	comps.time = r.Time()
+	comps.bounce = r.Bounce()
+	comps.distance = comps.t * r.Direction().Magnitude()
+
+	// which side we're on comes from the real surface, not the bumped one
+	if ray.Dot(geometric, comps.eyev) < 0 {
 		comps.inside = true
+		geometric = geometric.Negate()
 		comps.normalv = comps.normalv.Negate()
 	}
+	comps.reflectv = r.Direction().Reflect(comps.normalv)
 
-	normalvMultiplyByEpsilon := comps.normalv.Multiply(epsilon)
+	normalvMultiplyByEpsilon := geometric.Multiply(epsilon)
 	comps.overPoint = comps.point.Add(normalvMultiplyByEpsilon)
 	comps.underPoint = comps.point.Subtract(normalvMultiplyByEpsilon)
 
@@ -103,7 +126,7 @@ func PrepareComputations(i object.Intersection, r ray.Ray, xs ...object.Intersec
 	for idx := range xs {
 		if i == xs[idx] {
 			if len(containers) > 0 {
-				comps.n1 = containers[len(containers)-1].Material().RefractiveIndex
+				comps.n1 = containers[len(containers)-1].Material().IndexAt(comps.wavelength)
 			}
 		}
 
@@ -115,7 +138,7 @@ func PrepareComputations(i object.Intersection, r ray.Ray, xs ...object.Intersec
 
 		if i == xs[idx] {
 			if len(containers) > 0 {
-				comps.n2 = containers[len(containers)-1].Material().RefractiveIndex
+				comps.n2 = containers[len(containers)-1].Material().IndexAt(comps.wavelength)
 			}
 			break
 		}
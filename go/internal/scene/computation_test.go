@@ -192,6 +192,22 @@ func TestComputation_Reflectv(t *testing.T) {
 	assert.Equal(t, ray.NewVec(0, math.Sqrt(2)/2, math.Sqrt(2)/2), actual.Reflectv())
 }
 
+func TestPrepareComputations_perturbedNormal(t *testing.T) {
+	m := object.DefaultMaterial()
+	m.BumpMap = object.NewGradientPattern(object.Black, object.White)
+	m.BumpScale = 1
+	shape := object.NewPlane(object.WithMaterial(m))
+	r := ray.NewRayAt(ray.NewPoint(0.5, 1, 0.5), ray.NewVec(0, -1, 0))
+
+	actual := scene.PrepareComputations(object.Intersection{T: 1, Obj: shape}, r)
+	assert.False(t, actual.Inside())
+	assertVectorEqual(t, ray.NewVec(-1, 1, 0).Normalize(), actual.Normalv())
+	assertVectorEqual(t, ray.NewVec(-1, 0, 0), actual.Reflectv())
+	// the surface hasn't moved, so neither have the offset points
+	assertVectorEqual(t, ray.NewPoint(0.5, 0.00000001, 0.5), actual.OverPoint())
+	assertVectorEqual(t, ray.NewPoint(0.5, -0.00000001, 0.5), actual.UnderPoint())
+}
+
 func TestSchlick(t *testing.T) {
 
 	shape := object.DefaultGlassSphere()
@@ -0,0 +1,106 @@
+package scene
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+const (
+	defaultEmitterSamples = 16
+	// shadow rays stop just short of the light so they don't hit it
+	shadowEpsilon = 1e-4
+)
+
+// emitterSample is a point on an emissive object seen from a hit.
+type emitterSample struct {
+	direction ray.Vector
+	radiance  object.RGB
+	// pdf is per unit solid angle around the hit
+	pdf float64
+}
+
+// isEmitter reports whether obj is an emissive shape that can be sampled
+// as a light.
+func isEmitter(obj object.Object) bool {
+	_, ok := obj.(object.SurfaceSampler)
+	return ok && obj.Material().IsEmissive()
+}
+
+// appendEmitters adds obj to emitters if it's one, otherwise looks for
+// emitters among its children when it's a group. A group that glows as a
+// whole is a single emitter, sampled over its children by area.
+func appendEmitters(emitters []object.Object, obj object.Object) []object.Object {
+	if isEmitter(obj) {
+		return append(emitters, obj)
+	}
+	if g, ok := obj.(*object.Group); ok {
+		for _, child := range g.Children {
+			emitters = appendEmitters(emitters, child)
+		}
+	}
+	return emitters
+}
+
+// isSampledEmitter reports whether a hit object is, or is part of, an
+// emitter the lights are sampled from.
+func isSampledEmitter(obj object.Object) bool {
+	for ; obj != nil; obj = obj.Parent() {
+		if isEmitter(obj) {
+			return true
+		}
+	}
+	return false
+}
+
+// sampleEmitter picks a point on an emitter that can be seen from the hit,
+// opaque treats transparent objects in the way as blocking the light.
+func sampleEmitter(w World, comps Computation, emitter object.Object, u1, u2 float64, opaque bool) (s emitterSample, ok bool) {
+	point, normal, pdf, ok := object.SampleSurface(emitter, u1, u2)
+	if !ok {
+		return s, false
+	}
+	toLight := point.Subtract(comps.overPoint)
+	distance := toLight.Magnitude()
+	if distance <= 0 {
+		return s, false
+	}
+	s.direction = toLight.Normalize()
+	cosLight := math.Abs(ray.Dot(normal, s.direction))
+	if cosLight <= 0 || ray.Dot(s.direction, comps.normalv) <= 0 {
+		return s, false
+	}
+
+	transmittance := visibility(w, comps, toLight, 1-shadowEpsilon, opaque)
+	if transmittance == object.Black {
+		return s, false
+	}
+
+	s.radiance = emitter.Material().Emitted().Multiply(transmittance)
+	s.pdf = pdf * distance * distance / cosLight
+	return s, true
+}
+
+// EmitterLighting is the light emissive objects shine on a hit, estimated
+// with shadow rays toward points picked on their surfaces.
+func EmitterLighting(w World, comps Computation) object.RGB {
+	return emitterLighting(w, comps, w.Emitters())
+}
+
+func emitterLighting(w World, comps Computation, emitters []object.Object) (color object.RGB) {
+	for _, emitter := range emitters {
+		for i := 0; i < defaultEmitterSamples; i++ {
+			s, ok := sampleEmitter(w, comps, emitter, rand.Float64(), rand.Float64(), false)
+			if !ok {
+				continue
+			}
+			cos := ray.Dot(s.direction, comps.normalv)
+			color = color.Add(surfaceBRDF(comps, s.direction).
+				Multiply(s.radiance).
+				MultiplyBy(cos / s.pdf / defaultEmitterSamples))
+		}
+	}
+	return color
+}
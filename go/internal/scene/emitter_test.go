@@ -0,0 +1,151 @@
+package scene_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+func emissiveMaterial(strength float64) object.Material {
+	m := object.DefaultMaterial()
+	m.Ambient, m.Diffuse, m.Specular = 0, 0, 0
+	m.Emission = object.White
+	m.EmissionStrength = strength
+	return m
+}
+
+// lightPanel is a small square light facing down at height
+func lightPanel(height, strength float64) object.Object {
+	return object.NewQuad(
+		object.WithMaterial(emissiveMaterial(strength)),
+		object.WithTransform(ray.Translation(0, height, 0).Multiply(ray.Scaling(0.05, 1, 0.05))))
+}
+
+func TestWorld_Emitters(t *testing.T) {
+	w := scene.NewWorld()
+	panel := lightPanel(1, 1)
+	w.AddObjects(
+		object.DefaultSphere(),
+		panel,
+		object.NewPlane(object.WithMaterial(emissiveMaterial(1))),
+	)
+	assert.Equal(t, []object.Object{panel}, w.Emitters(), "only emissive shapes that can be sampled are lights")
+
+	t.Run("A glowing group is a single emitter", func(t *testing.T) {
+		w := scene.NewWorld()
+		g := object.NewGroup(object.WithMaterial(emissiveMaterial(1)))
+		g.AddChild(object.NewQuad(), object.DefaultSphere())
+		w.AddObject(&g)
+		assert.Equal(t, []object.Object{&g}, w.Emitters())
+	})
+
+	t.Run("Glowing shapes inside a group are emitters", func(t *testing.T) {
+		w := scene.NewWorld()
+		inner := object.NewGroup(
+			object.WithMaterial(emissiveMaterial(2)),
+			object.WithChildren(object.NewQuad(), object.NewPlane()))
+		outer := object.NewGroup(object.WithChildren(inner.Object()))
+		w.AddObject(outer.Object())
+		require.Len(t, w.Emitters(), 1, "the plane can't be sampled")
+		assert.Equal(t, emissiveMaterial(2).Emitted(), w.Emitters()[0].Material().Emitted())
+	})
+
+	t.Run("Emission without a strength still glows", func(t *testing.T) {
+		w := scene.NewWorld()
+		panel := object.NewQuad(object.WithMaterial(object.Material{Emission: object.White}))
+		w.AddObject(panel)
+		assert.Equal(t, []object.Object{panel}, w.Emitters())
+	})
+}
+
+func TestEmitterLighting(t *testing.T) {
+	// a 0.1 by 0.1 panel one unit above the floor gives about strength * 0.01 irradiance
+	const strength = 100
+	expected := object.NewColor(0.5, 0.5, 0.5).MultiplyBy(strength * 0.01 / math.Pi)
+
+	testCases := []struct {
+		name       string
+		integrator scene.Integrator
+		samples    int
+		blocked    bool
+		expected   object.RGB
+	}{
+		{
+			name:       "A light panel lights the floor under it",
+			integrator: scene.NewWhittedIntegrator(),
+			samples:    1,
+			expected:   expected,
+		},
+		{
+			name:       "A light panel lights the floor under it when path tracing",
+			integrator: scene.NewPathTracer(),
+			samples:    200,
+			expected:   expected,
+		},
+		{
+			name:       "A light panel is blocked by something in the way",
+			integrator: scene.NewWhittedIntegrator(),
+			samples:    1,
+			blocked:    true,
+			expected:   object.Black,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			w := scene.NewWorld()
+			w.AddObjects(
+				lightPanel(1, strength),
+				object.NewPlane(object.WithMaterial(diffuseMaterial(0.5))),
+			)
+			if tt.blocked {
+				w.AddObject(object.NewSphere(ray.NewPoint(0, 0.5, 0), 0.2))
+			}
+			r := ray.NewRayAt(ray.NewPoint(0, 0.5, -1), ray.NewVec(0, -0.5, 1))
+			actual := averageLi(w, tt.integrator, r, tt.samples)
+			assert.InDelta(t, tt.expected.R, actual.R, tt.expected.R*0.03+1e-9)
+		})
+	}
+}
+
+func TestEmissiveObjects(t *testing.T) {
+	mirror := object.DefaultMaterial()
+	mirror.Ambient, mirror.Diffuse, mirror.Specular = 0, 0, 0
+	mirror.Reflective = 0.5
+
+	testCases := []struct {
+		name     string
+		objs     []object.Object
+		r        ray.Ray
+		expected object.RGB
+	}{
+		{
+			name:     "An emissive sphere glows without any lights",
+			objs:     []object.Object{object.DefaultSphere(object.WithMaterial(emissiveMaterial(2)))},
+			r:        ray.NewRayAt(ray.NewPoint(0, 0, -5), ray.NewVec(0, 0, 1)),
+			expected: object.NewColor(2, 2, 2),
+		},
+		{
+			name: "A mirror reflects an emitter",
+			objs: []object.Object{
+				lightPanel(1, 4),
+				object.NewPlane(object.WithMaterial(mirror)),
+			},
+			r:        ray.NewRayAt(ray.NewPoint(0, 1, -1), ray.NewVec(0, -1, 0.5)),
+			expected: object.NewColor(2, 2, 2),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			w := scene.NewWorld()
+			w.AddObjects(tt.objs...)
+			assertColorEqual(t, tt.expected, scene.NewWhittedIntegrator().Li(w, tt.r))
+			assertColorEqual(t, tt.expected, scene.NewPathTracer().Li(w, tt.r))
+		})
+	}
+}
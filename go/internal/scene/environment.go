@@ -0,0 +1,161 @@
+package scene
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+const (
+	defaultEnvironmentSamples = 16
+)
+
+// EnvironmentLight lights the world from an equirectangular environment map.
+// Directions are importance sampled by luminance so the bright parts of the
+// map (the sun, studio soft boxes) get most of the shadow rays.
+type EnvironmentLight struct {
+	image     Canvas
+	Intensity float64
+	Samples   int
+
+	// marginal is the cumulative distribution over rows, conditional the
+	// cumulative distribution over the columns of each row.
+	marginal    []float64
+	conditional [][]float64
+	weights     [][]float64
+	total       float64
+}
+
+func NewEnvironmentLight(image Canvas) *EnvironmentLight {
+	width, height := image.Width(), image.Height()
+	l := &EnvironmentLight{
+		image:       image,
+		Intensity:   1,
+		Samples:     defaultEnvironmentSamples,
+		marginal:    make([]float64, height),
+		conditional: make([][]float64, height),
+		weights:     make([][]float64, height),
+	}
+
+	for y := 0; y < height; y++ {
+		// rows near the poles cover less of the sphere
+		sinTheta := math.Sin(math.Pi * (float64(y) + 0.5) / float64(height))
+		l.weights[y] = make([]float64, width)
+		l.conditional[y] = make([]float64, width)
+		row := 0.0
+		for x := 0; x < width; x++ {
+			l.weights[y][x] = image[x][y].Luminance() * sinTheta
+			row += l.weights[y][x]
+			l.conditional[y][x] = row
+		}
+		l.total += row
+		l.marginal[y] = l.total
+	}
+	return l
+}
+
+// ColorFor lets the light also be used as the world's background.
+func (l *EnvironmentLight) ColorFor(direction ray.Vector) object.RGB {
+	u, v := equirectangularUV(direction)
+	return l.image.Sample(u, v).MultiplyBy(l.Intensity)
+}
+
+// Sample picks a direction toward the environment, returning the radiance
+// arriving from it and the probability density per unit solid angle.
+func (l *EnvironmentLight) Sample(u1, u2 float64) (direction ray.Vector, radiance object.RGB, pdf float64) {
+	if l.total <= 0 {
+		return nil, object.Black, 0
+	}
+	width, height := l.image.Width(), l.image.Height()
+	y, fy := sampleCDF(l.marginal, u1*l.total)
+	x, fx := sampleCDF(l.conditional[y], u2*l.conditional[y][width-1])
+
+	u := (float64(x) + fx) / float64(width)
+	v := (float64(y) + fy) / float64(height)
+	direction = equirectangularDirection(u, v)
+	return direction, l.image[x][y].MultiplyBy(l.Intensity), l.pdf(x, y, v)
+}
+
+// Pdf is the density Sample would pick direction with.
+func (l *EnvironmentLight) Pdf(direction ray.Vector) float64 {
+	if l.total <= 0 {
+		return 0
+	}
+	u, v := equirectangularUV(direction)
+	width, height := l.image.Width(), l.image.Height()
+	x := int(math.Min(u*float64(width), float64(width-1)))
+	y := int(math.Min(v*float64(height), float64(height-1)))
+	return l.pdf(x, y, v)
+}
+
+func (l *EnvironmentLight) pdf(x, y int, v float64) float64 {
+	sinTheta := math.Sin(math.Pi * v)
+	if sinTheta <= 0 {
+		return 0
+	}
+	// density over the image, then over the sphere it wraps
+	pdfUV := l.weights[y][x] / l.total * float64(l.image.Width()*l.image.Height())
+	return pdfUV / (2 * math.Pi * math.Pi * sinTheta)
+}
+
+// sampleCDF finds the bucket a value falls in and how far through it the value is.
+func sampleCDF(cdf []float64, value float64) (idx int, fraction float64) {
+	idx = sort.SearchFloat64s(cdf, value)
+	if idx >= len(cdf) {
+		idx = len(cdf) - 1
+	}
+	// skip over buckets with no weight
+	for idx < len(cdf)-1 && cdf[idx] == 0 {
+		idx++
+	}
+	start := 0.0
+	if idx > 0 {
+		start = cdf[idx-1]
+	}
+	if width := cdf[idx] - start; width > 0 {
+		fraction = clamp((value-start)/width, 0, 1)
+	}
+	return idx, fraction
+}
+
+// EnvironmentLighting is the diffuse and glossy light the environment adds
+// to a hit, estimated with shadow rays toward importance sampled directions.
+func EnvironmentLighting(w World, comps Computation) (color object.RGB) {
+	l := w.EnvironmentLight()
+	if l == nil || l.Samples <= 0 {
+		return object.Black
+	}
+	for i := 0; i < l.Samples; i++ {
+		direction, radiance, pdf := l.Sample(rand.Float64(), rand.Float64())
+		if pdf <= 0 {
+			continue
+		}
+		cos := ray.Dot(direction, comps.normalv)
+		if cos <= 0 {
+			continue
+		}
+		radiance = radiance.Multiply(visibility(w, comps, direction, math.Inf(1), false))
+
+		color = color.Add(surfaceBRDF(comps, direction).Multiply(radiance).MultiplyBy(cos / pdf))
+	}
+	return color.MultiplyBy(1 / float64(l.Samples))
+}
+
+// surfaceBRDF is the reflectance of the hit for light arriving from
+// direction, with Phong normalised so it can be lit by area and environment
+// lights.
+func surfaceBRDF(comps Computation, direction ray.Vector) object.RGB {
+	m := comps.obj.Material()
+	if m.PBR != nil {
+		return m.BRDF(comps.obj, comps.overPoint, comps.normalv, comps.eyev, direction)
+	}
+	brdf := m.ColorAt(comps.obj, comps.overPoint).MultiplyBy(m.Diffuse / math.Pi)
+	if rde := ray.Dot(direction.Negate().Reflect(comps.normalv), comps.eyev); rde > 0 {
+		specular := m.Specular * (m.Shininess + 2) / (2 * math.Pi) * math.Pow(rde, m.Shininess)
+		brdf = brdf.Add(object.NewColor(specular, specular, specular))
+	}
+	return brdf
+}
@@ -0,0 +1,117 @@
+package scene_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+func uniformEnvironment(width, height int, color object.RGB) scene.Canvas {
+	c := scene.NewCanvas(width, height)
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			c.SetColor(x, y, color)
+		}
+	}
+	return c
+}
+
+func TestEnvironmentLight_Sample(t *testing.T) {
+	t.Run("A uniform map is sampled evenly over the sphere", func(t *testing.T) {
+		l := scene.NewEnvironmentLight(uniformEnvironment(64, 32, object.White))
+		const n = 10000
+		area := 0.0
+		for i := 0; i < n; i++ {
+			direction, radiance, pdf := l.Sample(rand.Float64(), rand.Float64())
+			assert.InDelta(t, 1, direction.Magnitude(), 0.00001)
+			assert.Equal(t, object.White, radiance)
+			assert.InDelta(t, pdf, l.Pdf(direction), 0.00001)
+			area += 1 / pdf
+		}
+		// integrating one over the sphere gives its area
+		assert.InDelta(t, 4*math.Pi, area/n, 0.1)
+	})
+
+	t.Run("A single bright pixel gets every sample", func(t *testing.T) {
+		c := uniformEnvironment(8, 4, object.Black)
+		c.SetColor(4, 1, object.NewColor(10, 10, 10))
+		l := scene.NewEnvironmentLight(c)
+		l.Intensity = 2
+		for i := 0; i < 100; i++ {
+			direction, radiance, pdf := l.Sample(rand.Float64(), rand.Float64())
+			assert.Equal(t, object.NewColor(20, 20, 20), radiance)
+			assert.Greater(t, pdf, 0.0)
+			// the pixel right of centre in the upper half looks up and along +x
+			assert.Greater(t, direction.GetY(), 0.0)
+			assert.GreaterOrEqual(t, direction.GetX(), 0.0)
+		}
+		assert.Equal(t, 0.0, l.Pdf(ray.NewVec(0, -1, 0)))
+	})
+
+	t.Run("A black map has nothing to sample", func(t *testing.T) {
+		l := scene.NewEnvironmentLight(uniformEnvironment(8, 4, object.Black))
+		_, radiance, pdf := l.Sample(0.5, 0.5)
+		assert.Equal(t, object.Black, radiance)
+		assert.Equal(t, 0.0, pdf)
+	})
+}
+
+func TestEnvironmentLight_ColorFor(t *testing.T) {
+	l := scene.NewEnvironmentLight(uniformEnvironment(8, 4, object.NewColor(0.5, 0.25, 1)))
+	l.Intensity = 2
+	assertColorEqual(t, object.NewColor(1, 0.5, 2), l.ColorFor(ray.NewVec(0, 1, 1)))
+}
+
+func TestEnvironmentLighting(t *testing.T) {
+	setup := func(t *testing.T) (scene.World, scene.Computation) {
+		w := scene.NewWorld()
+		l := scene.NewEnvironmentLight(uniformEnvironment(64, 32, object.White))
+		l.Samples = 4096
+		w.SetEnvironmentLight(l)
+
+		m := object.DefaultMaterial()
+		m.Ambient = 0
+		m.Diffuse = 1
+		m.Specular = 0
+		floor := object.NewPlane(object.WithMaterial(m))
+		w.AddObject(floor)
+
+		r := ray.NewRayAt(ray.NewPoint(0, 1, 0), ray.NewVec(0, -1, 0))
+		return w, scene.PrepareComputations(object.Intersection{T: 1, Obj: floor}, r)
+	}
+
+	t.Run("A white diffuse floor under a white sky reflects all of it", func(t *testing.T) {
+		w, comps := setup(t)
+		actual := scene.EnvironmentLighting(w, comps)
+		assert.InDelta(t, 1, actual.R, 0.1)
+		assert.InDelta(t, 1, actual.G, 0.1)
+		assert.InDelta(t, 1, actual.B, 0.1)
+	})
+
+	t.Run("A floor under a roof is in shadow", func(t *testing.T) {
+		w, comps := setup(t)
+		w.AddObject(object.NewPlane(object.WithTransform(ray.Translation(0, 2, 0))))
+		assertColorEqual(t, object.Black, scene.EnvironmentLighting(w, comps))
+	})
+
+	t.Run("No environment light adds nothing", func(t *testing.T) {
+		w, comps := setup(t)
+		w.SetEnvironmentLight(nil)
+		assertColorEqual(t, object.Black, scene.EnvironmentLighting(w, comps))
+	})
+}
+
+func TestWorld_IsOccluded(t *testing.T) {
+	w, err := scene.DefaultWorld()
+	require.NoError(t, err)
+	assert.True(t, w.IsOccluded(ray.NewPoint(0, 0, -5), ray.NewVec(0, 0, 1)))
+	assert.False(t, w.IsOccluded(ray.NewPoint(0, 0, -5), ray.NewVec(0, 0, -1)))
+	assert.False(t, w.IsOccluded(ray.NewPoint(0, 5, 0), ray.NewVec(0, 1, 0)))
+}
@@ -0,0 +1,177 @@
+package scene
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+const (
+	defaultPathMaxDepth      = 8
+	defaultPathRouletteDepth = 3
+	minRouletteSurvival      = 0.05
+)
+
+// Integrator works out the light arriving back along a camera ray.
+type Integrator interface {
+	Li(w World, r ray.Ray) object.RGB
+}
+
+// ParseIntegrator is the integrator called name, whitted or path.
+func ParseIntegrator(name string) (Integrator, error) {
+	switch strings.ToLower(name) {
+	case "", "whitted":
+		return NewWhittedIntegrator(), nil
+	case "path":
+		return NewPathTracer(), nil
+	default:
+		return nil, fmt.Errorf("unknown integrator %q, expected whitted or path", name)
+	}
+}
+
+// Whitted is the classic recursive ray tracer: Phong lighting plus perfect
+// reflection and refraction.
+type Whitted struct {
+	Depth int
+}
+
+func NewWhittedIntegrator() Integrator {
+	return Whitted{Depth: defaultRecursiveDepth}
+}
+
+func (i Whitted) Li(w World, r ray.Ray) object.RGB {
+	return w.ColorAt(r, i.Depth)
+}
+
+// PathTracer is a Monte Carlo integrator that follows a single random path
+// per sample, so it converges to global illumination as samples go up.
+type PathTracer struct {
+	MaxDepth int
+	// RouletteDepth is the bounce after which dim paths start to be
+	// terminated at random.
+	RouletteDepth int
+}
+
+func NewPathTracer() Integrator {
+	return PathTracer{
+		MaxDepth:      defaultPathMaxDepth,
+		RouletteDepth: defaultPathRouletteDepth,
+	}
+}
+
+func (p PathTracer) Li(w World, r ray.Ray) (radiance object.RGB) {
+	throughput := object.White
+	env := w.EnvironmentLight()
+	var lastPdf float64
+	lastDelta := true
+
+	for depth := 0; depth < p.MaxDepth; depth++ {
+		xs := Intersect(w, r)
+		hit := object.Hit(xs)
+		if hit == object.NoHit {
+			if background := w.Background(); background != nil {
+				le := background.ColorFor(r.Direction())
+				if !lastDelta && env != nil && background == Background(env) {
+					// the environment was already sampled directly from the last hit
+					le = le.MultiplyBy(powerHeuristic(lastPdf, env.Pdf(r.Direction().Normalize())))
+				}
+				radiance = radiance.Add(throughput.Multiply(le))
+			}
+			break
+		}
+
+		comps := PrepareComputations(hit, r, xs...)
+		if comps.inside {
+			throughput = throughput.Multiply(comps.obj.Material().Transmittance(comps.distance))
+		}
+		// emitters lit the last hit directly, unless a delta lobe couldn't be
+		if m := comps.obj.Material(); m.IsEmissive() && (lastDelta || !isSampledEmitter(comps.obj)) {
+			radiance = radiance.Add(throughput.Multiply(m.Emitted()))
+		}
+		if comps.wavelength == 0 && comps.obj.Material().Disperses() {
+			// follow one channel's wavelength from here on
+			channel := rand.Intn(3)
+			throughput = throughput.Multiply(dispersionChannels[channel].MultiplyBy(3))
+			comps = spectralComputation(comps, dispersionWavelengths[channel])
+		}
+		b := newBSDF(comps)
+		if !b.IsDelta() {
+			radiance = radiance.Add(throughput.Multiply(p.directLighting(w, comps, b)))
+		}
+
+		s, ok := b.Sample(rand.Float64(), rand.Float64(), rand.Float64())
+		if !ok {
+			break
+		}
+		throughput = throughput.Multiply(s.weight)
+		lastPdf, lastDelta = s.pdf, s.delta
+
+		origin := comps.overPoint
+		if s.transmitted {
+			origin = comps.underPoint
+		}
+		r = comps.spawn(origin, s.direction)
+
+		if depth >= p.RouletteDepth {
+			survive := math.Max(minRouletteSurvival,
+				math.Min(1, math.Max(throughput.R, math.Max(throughput.G, throughput.B))))
+			if rand.Float64() >= survive {
+				break
+			}
+			throughput = throughput.MultiplyBy(1 / survive)
+		}
+	}
+	return radiance
+}
+
+// directLighting is next event estimation: light reaching the hit straight
+// from the point light and the environment.
+func (p PathTracer) directLighting(w World, comps Computation, b bsdf) (radiance object.RGB) {
+	if light := w.Light(); light.Position != nil {
+		toLight := light.Position.Subtract(comps.overPoint)
+		direction := toLight.Normalize()
+		cos := ray.Dot(direction, comps.normalv)
+		if cos > 0 {
+			// point lights keep the Phong convention of no fall off, π makes a
+			// white diffuse surface facing the light as bright as the light.
+			// No path can find a point light through glass so let it shine through.
+			radiance = radiance.Add(b.Eval(direction).
+				Multiply(light.Intensity).
+				Multiply(visibility(w, comps, toLight, 1, false)).
+				MultiplyBy(math.Pi * cos))
+		}
+	}
+
+	if emitters := w.Emitters(); len(emitters) > 0 {
+		// one emitter picked at random is enough per bounce
+		emitter := emitters[rand.Intn(len(emitters))]
+		// light through glass is found by following the path, not here
+		if s, ok := sampleEmitter(w, comps, emitter, rand.Float64(), rand.Float64(), true); ok {
+			cos := ray.Dot(s.direction, comps.normalv)
+			radiance = radiance.Add(b.Eval(s.direction).
+				Multiply(s.radiance).
+				MultiplyBy(cos * float64(len(emitters)) / s.pdf))
+		}
+	}
+
+	if env := w.EnvironmentLight(); env != nil {
+		direction, le, pdf := env.Sample(rand.Float64(), rand.Float64())
+		if pdf > 0 {
+			cos := ray.Dot(direction, comps.normalv)
+			if cos > 0 && visibility(w, comps, direction, math.Inf(1), true) != object.Black {
+				weight := 1.0
+				if w.Background() == Background(env) {
+					weight = powerHeuristic(pdf, b.Pdf(direction))
+				}
+				radiance = radiance.Add(b.Eval(direction).
+					Multiply(le).
+					MultiplyBy(cos * weight / pdf))
+			}
+		}
+	}
+	return radiance
+}
@@ -0,0 +1,186 @@
+package scene_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+func TestWhitted_Li(t *testing.T) {
+	w, err := scene.DefaultWorld()
+	require.NoError(t, err)
+	r := ray.NewRayAt(ray.NewPoint(0, 0, -5), ray.NewVec(0, 0, 1))
+	assert.Equal(t, w.ColorAt(r, 5), scene.NewWhittedIntegrator().Li(w, r))
+}
+
+func diffuseMaterial(albedo float64) object.Material {
+	m := object.DefaultMaterial()
+	m.Color = object.NewColor(albedo, albedo, albedo)
+	m.Ambient = 0
+	m.Diffuse = 1
+	m.Specular = 0
+	return m
+}
+
+func averageLi(w scene.World, integrator scene.Integrator, r ray.Ray, samples int) (color object.RGB) {
+	for i := 0; i < samples; i++ {
+		color = color.Add(integrator.Li(w, r))
+	}
+	return color.MultiplyBy(1 / float64(samples))
+}
+
+func TestPathTracer_Li(t *testing.T) {
+	glass := object.DefaultMaterial()
+	glass.Transparency = 1
+	glass.RefractiveIndex = 1.5
+	glass.Diffuse = 0
+	glass.Specular = 0
+
+	mirror := object.DefaultMaterial()
+	mirror.Diffuse = 0
+	mirror.Specular = 0
+	mirror.Reflective = 1
+
+	testCases := []struct {
+		name     string
+		setup    func(w scene.World)
+		expected object.RGB
+		delta    float64
+	}{
+		{
+			name:     "An empty world shows the background",
+			setup:    func(w scene.World) { w.SetBackground(scene.NewSolidBackground(object.Red)) },
+			expected: object.Red,
+		},
+		{
+			name: "A white diffuse sphere disappears in a white furnace",
+			setup: func(w scene.World) {
+				w.SetBackground(scene.NewSolidBackground(object.White))
+				w.AddObject(object.DefaultSphere(object.WithMaterial(diffuseMaterial(1))))
+			},
+			expected: object.White,
+		},
+		{
+			name: "A grey diffuse sphere in a white furnace reflects its albedo",
+			setup: func(w scene.World) {
+				w.SetBackground(scene.NewSolidBackground(object.White))
+				w.AddObject(object.DefaultSphere(object.WithMaterial(diffuseMaterial(0.5))))
+			},
+			expected: object.NewColor(0.5, 0.5, 0.5),
+		},
+		{
+			name: "A glass sphere disappears in a white furnace",
+			setup: func(w scene.World) {
+				w.SetBackground(scene.NewSolidBackground(object.White))
+				w.AddObject(object.DefaultSphere(object.WithMaterial(glass)))
+			},
+			expected: object.White,
+		},
+		{
+			name: "A PBR sphere without specular is lambertian in a white furnace",
+			setup: func(w scene.World) {
+				m := object.NewPBRMaterial(object.NewColor(0.5, 0.5, 0.5), 0, 0.5)
+				m.PBR.Specular = 0
+				w.SetBackground(scene.NewSolidBackground(object.White))
+				w.AddObject(object.DefaultSphere(object.WithMaterial(m)))
+			},
+			expected: object.NewColor(0.5, 0.5, 0.5),
+			delta:    0.05,
+		},
+		{
+			name: "A rough white PBR metal loses little light in a white furnace",
+			setup: func(w scene.World) {
+				w.SetBackground(scene.NewSolidBackground(object.White))
+				w.AddObject(object.DefaultSphere(object.WithMaterial(object.NewPBRMaterial(object.White, 1, 0.5))))
+			},
+			expected: object.NewColor(0.95, 0.95, 0.95),
+			delta:    0.06,
+		},
+		{
+			name: "A mirror reflects the background",
+			setup: func(w scene.World) {
+				w.SetBackground(scene.NewGradientBackground(object.Black, object.White))
+				w.AddObject(object.NewPlane(
+					object.WithMaterial(mirror),
+					object.WithTransform(ray.Translation(0, 0, 1).Multiply(ray.Rotation(ray.X, math.Pi/2))),
+				))
+			},
+			expected: object.NewColor(0.5, 0.5, 0.5),
+		},
+		{
+			name: "A point light lights a diffuse wall directly",
+			setup: func(w scene.World) {
+				w.AddLight(object.NewPointLight(ray.NewPoint(0, 0, -10), object.White))
+				w.AddObject(object.NewPlane(
+					object.WithMaterial(diffuseMaterial(0.5)),
+					object.WithTransform(ray.Translation(0, 0, 1).Multiply(ray.Rotation(ray.X, math.Pi/2))),
+				))
+			},
+			expected: object.NewColor(0.5, 0.5, 0.5),
+		},
+		{
+			name: "An environment light is sampled directly and by the BSDF",
+			setup: func(w scene.World) {
+				env := scene.NewEnvironmentLight(uniformEnvironment(16, 8, object.White))
+				w.SetBackground(env)
+				w.SetEnvironmentLight(env)
+				w.AddObject(object.DefaultSphere(object.WithMaterial(diffuseMaterial(0.5))))
+			},
+			expected: object.NewColor(0.5, 0.5, 0.5),
+			delta:    0.05,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			w := scene.NewWorld()
+			tt.setup(w)
+			r := ray.NewRayAt(ray.NewPoint(0, 0, -5), ray.NewVec(0, 0, 1))
+			actual := averageLi(w, scene.NewPathTracer(), r, 1000)
+			delta := math.Max(tt.delta, 0.0001)
+			assert.InDelta(t, tt.expected.R, actual.R, delta, "R")
+			assert.InDelta(t, tt.expected.G, actual.G, delta, "G")
+			assert.InDelta(t, tt.expected.B, actual.B, delta, "B")
+		})
+	}
+}
+
+func TestPathTracer_Li_roulette(t *testing.T) {
+	// two facing mirrors would bounce forever without a depth limit
+	mirror := object.DefaultMaterial()
+	mirror.Diffuse = 0
+	mirror.Specular = 0
+	mirror.Reflective = 0.5
+	w := scene.NewWorld()
+	w.SetBackground(scene.NewSolidBackground(object.White))
+	w.AddObjects(
+		object.NewPlane(object.WithMaterial(mirror)),
+		object.NewPlane(object.WithMaterial(mirror), object.WithTransform(ray.Translation(0, 1, 0))),
+	)
+	p := scene.PathTracer{MaxDepth: 100, RouletteDepth: 0}
+	actual := averageLi(w, p, ray.NewRayAt(ray.NewPoint(0, 0.5, 0), ray.NewVec(0, 1, 0)), 100)
+	assertColorEqual(t, object.Black, actual)
+}
+
+func TestRenderPixel(t *testing.T) {
+	w := scene.NewWorld()
+	w.SetBackground(scene.NewGradientBackground(object.Black, object.White))
+	c, err := scene.NewBasicCamera(11, 11, math.Pi/2)
+	require.NoError(t, err)
+
+	t.Run("A single sample goes through the centre", func(t *testing.T) {
+		actual := scene.RenderPixel(c, w, scene.DefaultRenderSettings(), 5, 5)
+		assertColorEqual(t, object.NewColor(0.5, 0.5, 0.5), actual)
+	})
+
+	t.Run("Many samples average over the pixel", func(t *testing.T) {
+		settings := scene.RenderSettings{Integrator: scene.NewPathTracer(), Samples: 64}
+		actual := scene.RenderPixel(c, w, settings, 5, 5)
+		assert.InDelta(t, 0.5, actual.R, 0.01)
+	})
+}
@@ -0,0 +1,179 @@
+package scene
+
+import (
+	"math"
+	"sort"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+const (
+	defaultMediumSamples = 8
+	maxMediumSamples     = 64
+	// rays through fog that miss everything are marched until this little light gets through
+	mediumCutoff = 1e-3
+)
+
+// Medium is a homogeneous participating medium. Density is the extinction
+// per unit distance and Color is what the medium scatters back toward the
+// eye when fully lit.
+type Medium struct {
+	Density float64
+	Color   object.RGB
+}
+
+func NewMedium(density float64, color object.RGB) Medium {
+	return Medium{
+		Density: density,
+		Color:   color,
+	}
+}
+
+func (m Medium) IsEmpty() bool {
+	return m.Density <= 0
+}
+
+// Volume fills a closed object with a medium. The boundary is never shaded
+// as a surface, only used to find where rays enter and leave.
+type Volume struct {
+	Boundary object.Object
+	Medium   Medium
+}
+
+type mediumSegment struct {
+	t0, t1  float64
+	density float64
+	color   object.RGB
+}
+
+func hasMedia(w World) bool {
+	return !w.Fog().IsEmpty() || len(w.Volumes()) > 0
+}
+
+// applyMedia attenuates the color seen at distance along the ray and adds
+// the light scattered toward the eye by the media in between.
+func (w *world) applyMedia(r ray.Ray, distance float64, color object.RGB) object.RGB {
+	length := r.Direction().Magnitude()
+	if math.IsInf(distance, 1) && !w.fog.IsEmpty() {
+		distance = -math.Log(mediumCutoff) / (w.fog.Density * length)
+	}
+
+	transmittance := 1.0
+	scattered := object.Black
+	for _, s := range mediumSegments(w, r, distance) {
+		if s.density <= 0 {
+			continue
+		}
+		// denser segments change faster so need more samples
+		depth := s.density * (s.t1 - s.t0) * length
+		samples := defaultMediumSamples + int(math.Min(depth*4, maxMediumSamples))
+		step := (s.t1 - s.t0) / float64(samples)
+		for i := 0; i < samples; i++ {
+			// weight each sample by the light the medium scatters over its step
+			start := math.Exp(-s.density * step * float64(i) * length)
+			end := math.Exp(-s.density * step * float64(i+1) * length)
+			point := r.PointAt(s.t0 + step*(float64(i)+0.5))
+			scattered = scattered.Add(
+				w.lightAt(point, r.Time()).
+					Multiply(s.color).
+					MultiplyBy(transmittance * (start - end)))
+		}
+		transmittance *= math.Exp(-depth)
+	}
+
+	return color.MultiplyBy(transmittance).Add(scattered)
+}
+
+// lightAt is the light reaching a point inside a medium.
+func (w *world) lightAt(point ray.Vector, time float64) object.RGB {
+	if w.light.Position == nil {
+		return object.Black
+	}
+	return w.light.Intensity.Multiply(lightTransmittance(w, point, time))
+}
+
+func opticalDepth(w World, from, to ray.Vector, time float64) (depth float64) {
+	r := ray.NewRayAt(from, to.Subtract(from))
+	r.SetTime(time)
+	length := r.Direction().Magnitude()
+	for _, s := range mediumSegments(w, r, 1) {
+		depth += s.density * (s.t1 - s.t0) * length
+	}
+	return depth
+}
+
+// mediumSegments splits the ray between 0 and distance into pieces where the
+// media are constant.
+func mediumSegments(w World, r ray.Ray, distance float64) (segments []mediumSegment) {
+	fog, volumes := w.Fog(), w.Volumes()
+	type span struct {
+		t0, t1 float64
+		medium Medium
+	}
+	var spans []span
+	bounds := []float64{0}
+	if !math.IsInf(distance, 1) {
+		bounds = append(bounds, distance)
+	}
+	for i := range volumes {
+		for _, in := range insideIntervals(volumes[i].Boundary, r) {
+			t0 := math.Max(in[0], 0)
+			t1 := math.Min(in[1], distance)
+			if t0 >= t1 || math.IsInf(t1, 1) {
+				continue
+			}
+			spans = append(spans, span{t0: t0, t1: t1, medium: volumes[i].Medium})
+			bounds = append(bounds, t0, t1)
+		}
+	}
+	sort.Float64s(bounds)
+
+	for i := 0; i < len(bounds)-1; i++ {
+		s := mediumSegment{
+			t0:      bounds[i],
+			t1:      bounds[i+1],
+			density: fog.Density,
+			color:   fog.Color.MultiplyBy(fog.Density),
+		}
+		if s.t1 <= s.t0 {
+			continue
+		}
+		mid := (s.t0 + s.t1) / 2
+		for j := range spans {
+			if spans[j].t0 <= mid && mid <= spans[j].t1 {
+				s.density += spans[j].medium.Density
+				s.color = s.color.Add(spans[j].medium.Color.MultiplyBy(spans[j].medium.Density))
+			}
+		}
+		if s.density > 0 {
+			// overlapping media scatter in proportion to their density
+			s.color = s.color.MultiplyBy(1 / s.density)
+		}
+		segments = append(segments, s)
+	}
+	return segments
+}
+
+// insideIntervals returns the ranges of t where the ray is inside a closed object.
+func insideIntervals(boundary object.Object, r ray.Ray) (intervals [][2]float64) {
+	xs := object.Intersect(boundary, r)
+	sort.SliceStable(xs, func(i, j int) bool {
+		return xs[i].T < xs[j].T
+	})
+
+	start := math.Inf(-1)
+	inside := false
+	for i := range xs {
+		if inside {
+			intervals = append(intervals, [2]float64{start, xs[i].T})
+		} else {
+			start = xs[i].T
+		}
+		inside = !inside
+	}
+	if inside {
+		intervals = append(intervals, [2]float64{start, math.Inf(1)})
+	}
+	return intervals
+}
@@ -0,0 +1,138 @@
+package scene_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+func TestWorld_SetFog(t *testing.T) {
+	w := scene.NewWorld()
+	assert.True(t, w.Fog().IsEmpty())
+	fog := scene.NewMedium(0.1, object.White)
+	w.SetFog(fog)
+	assert.Equal(t, fog, w.Fog())
+}
+
+func TestWorld_AddVolume(t *testing.T) {
+	w := scene.NewWorld()
+	boundary := object.NewCube()
+	medium := scene.NewMedium(0.5, object.Red)
+	w.AddVolume(boundary, medium)
+	assert.Equal(t, []scene.Volume{{Boundary: boundary, Medium: medium}}, w.Volumes())
+	assert.Empty(t, w.Objects())
+}
+
+func TestWorld_ColorAt_media(t *testing.T) {
+	glowing := func() object.Object {
+		m := object.DefaultMaterial()
+		m.Ambient = 1
+		m.Diffuse = 0
+		m.Specular = 0
+		return object.NewPlane(
+			object.WithMaterial(m),
+			object.WithTransform(ray.Translation(0, 0, 5).Multiply(ray.Rotation(ray.X, math.Pi/2))),
+		)
+	}
+	lit := func() object.Object {
+		m := object.DefaultMaterial()
+		m.Ambient = 0
+		m.Diffuse = 1
+		m.Specular = 0
+		return object.NewPlane(
+			object.WithMaterial(m),
+			object.WithTransform(ray.Translation(0, 0, 5).Multiply(ray.Rotation(ray.X, math.Pi/2))),
+		)
+	}
+	testCases := []struct {
+		name     string
+		setup    func(w scene.World)
+		expected object.RGB
+	}{
+		{
+			name:     "No media leaves the surface alone",
+			setup:    func(w scene.World) { w.AddObject(glowing()) },
+			expected: object.White,
+		},
+		{
+			name: "Fog attenuates the surface colour over distance",
+			setup: func(w scene.World) {
+				w.AddObject(glowing())
+				w.SetFog(scene.NewMedium(0.1, object.Black))
+			},
+			expected: object.White.MultiplyBy(math.Exp(-1)),
+		},
+		{
+			name: "Fog fills a miss with its lit colour",
+			setup: func(w scene.World) {
+				w.SetFog(scene.NewMedium(0.1, object.Red))
+			},
+			// lit from the eye, so the light is attenuated on the way in as well
+			expected: object.Red.MultiplyBy(0.5),
+		},
+		{
+			name: "Fog in front of a surface scatters light toward the eye",
+			setup: func(w scene.World) {
+				w.AddObject(glowing())
+				w.SetFog(scene.NewMedium(0.1, object.Red))
+			},
+			expected: object.NewColor(math.Exp(-1)+(1-math.Exp(-2))/2, math.Exp(-1), math.Exp(-1)),
+		},
+		{
+			name: "Fog dims the light on its way to the surface",
+			setup: func(w scene.World) {
+				w.AddObject(lit())
+				w.SetFog(scene.NewMedium(0.1, object.Black))
+			},
+			expected: object.White.MultiplyBy(math.Exp(-2)),
+		},
+		{
+			name: "A volume between the light and the surface dims the light",
+			setup: func(w scene.World) {
+				w.AddObject(lit())
+				w.AddVolume(object.NewCube(), scene.NewMedium(0.5, object.Black))
+			},
+			expected: object.White.MultiplyBy(math.Exp(-2)),
+		},
+		{
+			name: "A volume only attenuates where the ray is inside it",
+			setup: func(w scene.World) {
+				w.AddObject(glowing())
+				w.AddVolume(object.NewCube(), scene.NewMedium(0.5, object.Black))
+			},
+			expected: object.White.MultiplyBy(math.Exp(-1)),
+		},
+		{
+			name: "A volume the ray starts in",
+			setup: func(w scene.World) {
+				w.AddObject(glowing())
+				w.AddVolume(object.NewSphere(ray.NewPoint(0, 0, -5), 2), scene.NewMedium(0.5, object.Black))
+			},
+			expected: object.White.MultiplyBy(math.Exp(-1)),
+		},
+		{
+			name: "A volume scatters its own colour",
+			setup: func(w scene.World) {
+				w.AddVolume(object.NewCube(), scene.NewMedium(0.5, object.Green))
+			},
+			expected: object.Green.MultiplyBy((1 - math.Exp(-2)) / 2),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			w := scene.NewWorld()
+			w.AddLight(object.NewPointLight(ray.NewPoint(0, 0, -5), object.White))
+			tt.setup(w)
+			r := ray.NewRayAt(ray.NewPoint(0, 0, -5), ray.NewVec(0, 0, 1))
+			actual := w.ColorAt(r, 1)
+			assert.InDelta(t, tt.expected.R, actual.R, 0.01, "R")
+			assert.InDelta(t, tt.expected.G, actual.G, 0.01, "G")
+			assert.InDelta(t, tt.expected.B, actual.B, 0.01, "B")
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package scene
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+)
+
+// Accumulator adds up the samples for each pixel over many passes, so the
+// image can be looked at while it's still being rendered.
+type Accumulator struct {
+	mu            sync.RWMutex
+	width, height int
+	// the sum of the samples and how many there are, a row at a time
+	sum     []object.RGB
+	samples []int
+}
+
+func NewAccumulator(width, height int) *Accumulator {
+	return &Accumulator{
+		width:   width,
+		height:  height,
+		sum:     make([]object.RGB, width*height),
+		samples: make([]int, width*height),
+	}
+}
+
+func (a *Accumulator) Width() int {
+	return a.width
+}
+
+func (a *Accumulator) Height() int {
+	return a.height
+}
+
+// Add adds samples more samples adding up to sum to the pixel.
+func (a *Accumulator) Add(x, y int, sum object.RGB, samples int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.add(x, y, sum, samples)
+}
+
+func (a *Accumulator) add(x, y int, sum object.RGB, samples int) {
+	i := y*a.width + x
+	a.sum[i] = a.sum[i].Add(sum)
+	a.samples[i] += samples
+}
+
+// Samples is how many samples the pixel has had.
+func (a *Accumulator) Samples(x, y int) int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.samples[y*a.width+x]
+}
+
+// MinSamples is the fewest samples any pixel has had, how far the render
+// has got everywhere.
+func (a *Accumulator) MinSamples() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if len(a.samples) == 0 {
+		return 0
+	}
+	least := a.samples[0]
+	for _, n := range a.samples[1:] {
+		if n < least {
+			least = n
+		}
+	}
+	return least
+}
+
+// Canvas is the average of each pixel's samples so far, black for those
+// without any.
+func (a *Accumulator) Canvas() Canvas {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	c := NewCanvas(a.width, a.height)
+	for y := 0; y < a.height; y++ {
+		for x := 0; x < a.width; x++ {
+			if n := a.samples[y*a.width+x]; n > 0 {
+				c[x][y] = a.sum[y*a.width+x].MultiplyBy(1 / float64(n))
+			}
+		}
+	}
+	return c
+}
+
+// ProgressiveSettings controls a progressive render, which renders the
+// whole image again and again adding more samples to each pixel.
+type ProgressiveSettings struct {
+	// Samples is how many samples each pixel gets before stopping
+	Samples int
+	// TimeLimit stops the render after that long, however many samples
+	// there are
+	TimeLimit time.Duration
+	// PassSamples is how many samples each pixel gets each pass, one by
+	// default
+	PassSamples int
+	// SnapshotEvery and SnapshotPasses call Snapshot every so often and
+	// every so many passes, as well as when the render stops
+	SnapshotEvery  time.Duration
+	SnapshotPasses int
+	Snapshot       func(s Snapshot) error
+}
+
+// Snapshot is the image so far during a progressive render.
+type Snapshot struct {
+	Canvas Canvas
+	// Accumulator has the samples so far, for checkpoints
+	Accumulator *Accumulator
+	// Passes is how many passes are finished and Samples the fewest samples
+	// any pixel has
+	Passes, Samples int
+	Elapsed         time.Duration
+}
+
+// RenderProgressive renders pass after pass into an accumulator until each
+// pixel has had the progressive settings' samples, the time limit is up or
+// ctx is cancelled, whichever is first. With neither samples nor a time
+// limit it stops after the render settings' samples. Each pass renders
+// settings.Samples as PassSamples, so the other settings are used as they
+// are.
+func RenderProgressive(ctx context.Context, c Camera, w World, settings RenderSettings, progressive ProgressiveSettings, opts ...TileOption) (*Accumulator, error) {
+	return ResumeProgressive(ctx, NewAccumulator(c.HSize(), c.VSize()), c, w, settings, progressive, opts...)
+}
+
+// ResumeProgressive carries on a progressive render, adding samples to acc.
+func ResumeProgressive(ctx context.Context, acc *Accumulator, c Camera, w World, settings RenderSettings, progressive ProgressiveSettings, opts ...TileOption) (*Accumulator, error) {
+	if err := checkImageSize(c); err != nil {
+		return acc, err
+	}
+	if acc.Width() != c.HSize() || acc.Height() != c.VSize() {
+		return acc, errors.New("accumulator isn't the same size as the camera's image")
+	}
+	r := tileRenderer{workers: 1, tileSize: DefaultTileSize}
+	for i := range opts {
+		opts[i](&r)
+	}
+	if r.workers < 1 {
+		r.workers = 1
+	}
+	if progressive.PassSamples < 1 {
+		progressive.PassSamples = 1
+	}
+	if progressive.Samples <= 0 && progressive.TimeLimit <= 0 {
+		progressive.Samples = settings.Samples
+	}
+	settings.Samples = progressive.PassSamples
+
+	start := time.Now()
+	renderCtx, cancel := ctx, context.CancelFunc(func() {})
+	if progressive.TimeLimit > 0 {
+		renderCtx, cancel = context.WithTimeout(ctx, progressive.TimeLimit)
+	}
+	defer cancel()
+
+	snapshots := snapshotter{acc: acc, start: start, snapshot: progressive.Snapshot}
+	stopTicker := snapshots.every(progressive.SnapshotEvery)
+	defer stopTicker()
+
+	tiles := Tiles(c.HSize(), c.VSize(), r.tileSize)
+	for pass := 1; progressive.Samples <= 0 || acc.MinSamples() < progressive.Samples; pass++ {
+		forEachTile(renderCtx, tiles, r.workers, func(t Tile) {
+			accumulateTile(renderCtx, acc, c, w, settings, t)
+		})
+		if renderCtx.Err() != nil {
+			break
+		}
+		snapshots.finishedPass()
+		if progressive.SnapshotPasses > 0 && pass%progressive.SnapshotPasses == 0 {
+			_ = snapshots.take()
+		}
+		if err := snapshots.failed(); err != nil {
+			return acc, err
+		}
+	}
+	stopTicker()
+
+	if err := snapshots.take(); err != nil {
+		return acc, err
+	}
+	// running out of time is how the render is meant to stop
+	return acc, ctx.Err()
+}
+
+// accumulateTile adds a pass of samples to the tile's pixels, as many rows
+// as it gets through before ctx is cancelled.
+func accumulateTile(ctx context.Context, acc *Accumulator, c Camera, w World, settings RenderSettings, t Tile) {
+	rows := make([]object.RGB, t.Width)
+	for y := t.Y; y < t.Y+t.Height; y++ {
+		if ctx.Err() != nil {
+			return
+		}
+		for x := range rows {
+			// jittered even a sample at a time, or every pass would be the same
+			rows[x] = samplePixel(c, w, settings, t.X+x, y)
+		}
+		acc.mu.Lock()
+		for x := range rows {
+			acc.add(t.X+x, y, rows[x], settings.Samples)
+		}
+		acc.mu.Unlock()
+	}
+}
+
+// snapshotter takes the snapshots, one at a time.
+type snapshotter struct {
+	mu       sync.Mutex
+	acc      *Accumulator
+	start    time.Time
+	passes   int
+	snapshot func(s Snapshot) error
+	err      error
+}
+
+func (s *snapshotter) finishedPass() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passes++
+}
+
+// failed is the error from the last snapshot, which stops the render.
+func (s *snapshotter) failed() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *snapshotter) take() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.snapshot == nil || s.err != nil {
+		return s.err
+	}
+	s.err = s.snapshot(Snapshot{
+		Canvas:      s.acc.Canvas(),
+		Accumulator: s.acc,
+		Passes:      s.passes,
+		Samples:     s.acc.MinSamples(),
+		Elapsed:     time.Since(s.start),
+	})
+	return s.err
+}
+
+// every takes a snapshot every so often until stopped, which is safe to do
+// more than once.
+func (s *snapshotter) every(interval time.Duration) (stop func()) {
+	if interval <= 0 || s.snapshot == nil {
+		return func() {}
+	}
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				_ = s.take()
+			case <-done:
+				return
+			}
+		}
+	}()
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+			<-stopped
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package scene_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+func TestAccumulator(t *testing.T) {
+	a := scene.NewAccumulator(3, 2)
+	assert.Equal(t, 3, a.Width())
+	assert.Equal(t, 2, a.Height())
+	assert.Equal(t, 0, a.MinSamples())
+
+	a.Add(2, 1, object.NewColor(1, 2, 3), 4)
+	a.Add(2, 1, object.NewColor(1, 0, 1), 4)
+	assert.Equal(t, 8, a.Samples(2, 1))
+	assert.Equal(t, 0, a.MinSamples(), "other pixels have none")
+
+	c := a.Canvas()
+	assert.Equal(t, object.NewColor(0.25, 0.25, 0.5), c[2][1], "the average")
+	assert.Equal(t, object.Black, c[0][0])
+}
+
+func progressiveTestScene(t *testing.T) (scene.World, scene.Camera) {
+	w := scene.NewWorld()
+	w.SetBackground(scene.NewSolidBackground(object.NewColor(0.5, 0.5, 0.5)))
+	c, err := scene.NewBasicCamera(9, 5, math.Pi/2)
+	require.NoError(t, err)
+	return w, c
+}
+
+func TestRenderProgressive(t *testing.T) {
+	w, c := progressiveTestScene(t)
+
+	t.Run("stops at the samples", func(t *testing.T) {
+		var snapshots []scene.Snapshot
+		acc, err := scene.RenderProgressive(context.Background(), c, w, scene.DefaultRenderSettings(),
+			scene.ProgressiveSettings{
+				Samples:        4,
+				PassSamples:    2,
+				SnapshotPasses: 1,
+				Snapshot: func(s scene.Snapshot) error {
+					snapshots = append(snapshots, s)
+					return nil
+				},
+			}, scene.WithWorkers(2), scene.WithTileSize(4))
+		require.NoError(t, err)
+		assert.Equal(t, 4, acc.MinSamples())
+		assert.Equal(t, 4, acc.Samples(8, 4), "last row and column")
+		assertColorEqual(t, object.NewColor(0.5, 0.5, 0.5), acc.Canvas()[8][4])
+
+		require.Len(t, snapshots, 3, "every pass and at the end")
+		assert.Equal(t, 1, snapshots[0].Passes)
+		assert.Equal(t, 2, snapshots[0].Samples)
+		assert.Equal(t, 2, snapshots[2].Passes)
+		assert.Equal(t, 4, snapshots[2].Samples)
+		assertColorEqual(t, object.NewColor(0.5, 0.5, 0.5), snapshots[0].Canvas[0][0])
+	})
+
+	t.Run("render settings' samples by default", func(t *testing.T) {
+		settings := scene.DefaultRenderSettings()
+		settings.Samples = 3
+		acc, err := scene.RenderProgressive(context.Background(), c, w, settings, scene.ProgressiveSettings{})
+		require.NoError(t, err)
+		assert.Equal(t, 3, acc.MinSamples())
+	})
+
+	t.Run("stops at the time limit", func(t *testing.T) {
+		snapshots := 0
+		start := time.Now()
+		acc, err := scene.RenderProgressive(context.Background(), c, w, scene.DefaultRenderSettings(),
+			scene.ProgressiveSettings{
+				TimeLimit:     100 * time.Millisecond,
+				SnapshotEvery: 10 * time.Millisecond,
+				Snapshot: func(s scene.Snapshot) error {
+					snapshots++
+					return nil
+				},
+			})
+		require.NoError(t, err, "running out of time isn't an error")
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Greater(t, acc.MinSamples(), 1)
+		assert.Greater(t, snapshots, 2)
+	})
+
+	t.Run("stops when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := scene.RenderProgressive(ctx, c, w, scene.DefaultRenderSettings(),
+			scene.ProgressiveSettings{TimeLimit: time.Minute})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("stops when a snapshot fails", func(t *testing.T) {
+		failed := errors.New("disk full")
+		_, err := scene.RenderProgressive(context.Background(), c, w, scene.DefaultRenderSettings(),
+			scene.ProgressiveSettings{
+				Samples:        100,
+				SnapshotPasses: 1,
+				Snapshot: func(s scene.Snapshot) error {
+					return failed
+				},
+			})
+		assert.ErrorIs(t, err, failed)
+	})
+}
+
+func TestResumeProgressive(t *testing.T) {
+	w, c := progressiveTestScene(t)
+	acc, err := scene.RenderProgressive(context.Background(), c, w, scene.DefaultRenderSettings(),
+		scene.ProgressiveSettings{Samples: 2})
+	require.NoError(t, err)
+	acc, err = scene.ResumeProgressive(context.Background(), acc, c, w, scene.DefaultRenderSettings(),
+		scene.ProgressiveSettings{Samples: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 5, acc.MinSamples())
+
+	_, err = scene.ResumeProgressive(context.Background(), scene.NewAccumulator(1, 1), c, w, scene.DefaultRenderSettings(),
+		scene.ProgressiveSettings{Samples: 5})
+	assert.Error(t, err)
+
+	empty, err := scene.NewBasicCamera(0, 0, math.Pi/2)
+	require.NoError(t, err)
+	_, err = scene.RenderProgressive(context.Background(), empty, w, scene.DefaultRenderSettings(),
+		scene.ProgressiveSettings{Samples: 5})
+	assert.ErrorIs(t, err, scene.ErrEmptyImage)
+}
+
+func TestRenderProgressive_matchesSamples(t *testing.T) {
+	// a black sphere on white, its edge part way across pixels
+	w := scene.NewWorld()
+	w.SetBackground(scene.NewSolidBackground(object.White))
+	sphere := object.DefaultSphere()
+	m := sphere.Material()
+	m.Color = object.Black
+	m.Ambient, m.Diffuse, m.Specular = 0, 0, 0
+	sphere.SetMaterial(m)
+	w.AddObjects(sphere)
+	c, err := scene.NewLookAtCamera(7, 7, ray.NewPoint(0, 0, -5), ray.NewPoint(0, 0, 0), ray.NewVec(0, 1, 0),
+		scene.WithFieldOfView(math.Pi/4))
+	require.NoError(t, err)
+
+	const samples = 128
+	settings := scene.DefaultRenderSettings()
+	settings.Samples = samples
+	want := scene.RenderWith(c, w, settings)
+	acc, err := scene.RenderProgressive(context.Background(), c, w, scene.DefaultRenderSettings(),
+		scene.ProgressiveSettings{Samples: samples})
+	require.NoError(t, err)
+	got := acc.Canvas()
+
+	edges := 0
+	for x := range want {
+		for y := range want[x] {
+			assert.InDelta(t, want[x][y].R, got[x][y].R, 0.2, "pixel %v,%v", x, y)
+			if got[x][y].R > 0.05 && got[x][y].R < 0.95 {
+				edges++
+			}
+		}
+	}
+	assert.NotZero(t, edges, "pixels on the edge are anti-aliased")
+}
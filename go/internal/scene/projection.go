@@ -0,0 +1,105 @@
+package scene
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+// Projection is how a camera maps pixels onto rays.
+type Projection string
+
+const (
+	Perspective Projection = "perspective"
+	// Orthographic fires parallel rays, so things don't shrink with
+	// distance, handy for technical drawings.
+	Orthographic Projection = "orthographic"
+	// Fisheye is an equidistant fisheye, the angle off the view direction
+	// growing evenly with the distance from the image's centre.
+	Fisheye Projection = "fisheye"
+	// Equirectangular sees all the way around, longitude across the image
+	// and latitude down it, for 360° panoramas.
+	Equirectangular Projection = "equirectangular"
+)
+
+func ParseProjection(name string) (Projection, error) {
+	switch p := Projection(strings.ToLower(name)); p {
+	case "":
+		return Perspective, nil
+	case Perspective, Orthographic, Fisheye, Equirectangular:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown camera %q, expected perspective, orthographic, fisheye or equirectangular", name)
+	}
+}
+
+// NewProjectionCamera makes a camera for the projection. fieldOfView is the
+// angle across the longer side of the image for perspective and fisheye
+// cameras, and for orthographic cameras the width of the view in world
+// units. Equirectangular cameras always see all the way around.
+func NewProjectionCamera(p Projection, hSize, vSize int, fieldOfView float64) (Camera, error) {
+	switch p {
+	case Perspective, Orthographic, Fisheye:
+		return newCamera(p, hSize, vSize, fieldOfView, ray.IdentityMatrix(4, 4))
+	case Equirectangular:
+		return NewEquirectangularCamera(hSize, vSize)
+	default:
+		return nil, fmt.Errorf("unknown camera %q", p)
+	}
+}
+
+// NewOrthographicCamera looks down -z, viewWidth world units across the
+// longer side of the image.
+func NewOrthographicCamera(hSize, vSize int, viewWidth float64) (c Camera, err error) {
+	return newCamera(Orthographic, hSize, vSize, viewWidth, ray.IdentityMatrix(4, 4))
+}
+
+// NewFisheyeCamera sees fieldOfView across the longer side of the image,
+// up to 2π for everything around.
+func NewFisheyeCamera(hSize, vSize int, fieldOfView float64) (c Camera, err error) {
+	return newCamera(Fisheye, hSize, vSize, fieldOfView, ray.IdentityMatrix(4, 4))
+}
+
+func NewEquirectangularCamera(hSize, vSize int) (c Camera, err error) {
+	return newCamera(Equirectangular, hSize, vSize, 2*math.Pi, ray.IdentityMatrix(4, 4))
+}
+
+func (c camera) orthographicRay(nx, ny float64) ray.Ray {
+	worldX, worldY := c.planeAt(nx, ny)
+	inv := c.transformInverse
+	origin := inv.MultiplyByVector(ray.NewPoint(worldX, worldY, 0))
+	direction := inv.MultiplyByVector(ray.NewVec(0, 0, -1)).Normalize()
+	return ray.NewRayAt(origin, direction)
+}
+
+func (c camera) fisheyeRay(nx, ny float64) ray.Ray {
+	x, y := c.planeAt(nx, ny)
+	r := math.Hypot(x, y)
+	theta := math.Min(r*c.fieldOfView/2, math.Pi)
+
+	direction := ray.NewVec(0, 0, -1)
+	if r > 0 {
+		sin := math.Sin(theta)
+		direction = ray.NewVec(sin*x/r, sin*y/r, -math.Cos(theta))
+	}
+	return c.cameraRay(direction)
+}
+
+func (c camera) equirectangularRay(nx, ny float64) ray.Ray {
+	// the centre of the image looks down -z, with +x on the left like the
+	// other cameras
+	longitude := (0.5 - (nx+0.5)/float64(c.hSize)) * 2 * math.Pi
+	latitude := (0.5 - (ny+0.5)/float64(c.vSize)) * math.Pi
+	cos := math.Cos(latitude)
+	return c.cameraRay(ray.NewVec(
+		math.Sin(longitude)*cos,
+		math.Sin(latitude),
+		-math.Cos(longitude)*cos))
+}
+
+// cameraRay starts a ray at the camera going in a camera space direction.
+func (c camera) cameraRay(direction ray.Vector) ray.Ray {
+	return ray.NewRayAt(c.position, c.transformInverse.MultiplyByVector(direction).Normalize())
+}
@@ -0,0 +1,174 @@
+package scene_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+func TestParseProjection(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected scene.Projection
+		err      bool
+	}{
+		{name: "", expected: scene.Perspective},
+		{name: "perspective", expected: scene.Perspective},
+		{name: "Orthographic", expected: scene.Orthographic},
+		{name: "fisheye", expected: scene.Fisheye},
+		{name: "equirectangular", expected: scene.Equirectangular},
+		{name: "pinhole", err: true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := scene.ParseProjection(tt.name)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestNewProjectionCamera(t *testing.T) {
+	testCases := []struct {
+		name        string
+		projection  scene.Projection
+		hSize       int
+		vSize       int
+		fieldOfView float64
+		transform   ray.Matrix
+		nx, ny      float64
+		origin      ray.Vector
+		direction   ray.Vector
+	}{
+		{
+			name:        "perspective through the centre",
+			projection:  scene.Perspective,
+			hSize:       201,
+			vSize:       101,
+			fieldOfView: math.Pi / 2,
+			nx:          100,
+			ny:          50,
+			origin:      ray.ZeroPoint,
+			direction:   ray.NewVec(0, 0, -1),
+		},
+		{
+			name:        "orthographic rays are parallel",
+			projection:  scene.Orthographic,
+			hSize:       200,
+			vSize:       100,
+			fieldOfView: 4,
+			nx:          0,
+			ny:          0,
+			origin:      ray.NewPoint(1.99, 0.99, 0),
+			direction:   ray.NewVec(0, 0, -1),
+		},
+		{
+			name:        "orthographic when the camera is transformed",
+			projection:  scene.Orthographic,
+			hSize:       200,
+			vSize:       100,
+			fieldOfView: 4,
+			transform:   ray.Rotation(ray.Y, math.Pi/2).Multiply(ray.Translation(0, -2, 0)),
+			nx:          99.5,
+			ny:          49.5,
+			origin:      ray.NewPoint(0, 2, 0),
+			direction:   ray.NewVec(1, 0, 0),
+		},
+		{
+			name:        "fisheye through the centre",
+			projection:  scene.Fisheye,
+			hSize:       100,
+			vSize:       100,
+			fieldOfView: math.Pi,
+			nx:          49.5,
+			ny:          49.5,
+			origin:      ray.ZeroPoint,
+			direction:   ray.NewVec(0, 0, -1),
+		},
+		{
+			name:        "180° fisheye sees sideways at the edge",
+			projection:  scene.Fisheye,
+			hSize:       100,
+			vSize:       100,
+			fieldOfView: math.Pi,
+			nx:          99.5,
+			ny:          49.5,
+			origin:      ray.ZeroPoint,
+			direction:   ray.NewVec(-1, 0, 0),
+		},
+		{
+			name:        "fisheye angle grows evenly",
+			projection:  scene.Fisheye,
+			hSize:       100,
+			vSize:       100,
+			fieldOfView: math.Pi,
+			nx:          49.5,
+			ny:          24.5,
+			origin:      ray.ZeroPoint,
+			direction:   ray.NewVec(0, math.Sqrt(2)/2, -math.Sqrt(2)/2),
+		},
+		{
+			name:       "equirectangular through the centre",
+			projection: scene.Equirectangular,
+			hSize:      200,
+			vSize:      100,
+			nx:         99.5,
+			ny:         49.5,
+			origin:     ray.ZeroPoint,
+			direction:  ray.NewVec(0, 0, -1),
+		},
+		{
+			name:       "equirectangular a quarter of the way across",
+			projection: scene.Equirectangular,
+			hSize:      200,
+			vSize:      100,
+			nx:         49.5,
+			ny:         49.5,
+			origin:     ray.ZeroPoint,
+			direction:  ray.NewVec(1, 0, 0),
+		},
+		{
+			name:       "equirectangular edge looks behind",
+			projection: scene.Equirectangular,
+			hSize:      200,
+			vSize:      100,
+			nx:         -0.5,
+			ny:         49.5,
+			origin:     ray.ZeroPoint,
+			direction:  ray.NewVec(0, 0, 1),
+		},
+		{
+			name:       "equirectangular top looks up",
+			projection: scene.Equirectangular,
+			hSize:      200,
+			vSize:      100,
+			nx:         99.5,
+			ny:         -0.5,
+			origin:     ray.ZeroPoint,
+			direction:  ray.NewVec(0, 1, 0),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := scene.NewProjectionCamera(tt.projection, tt.hSize, tt.vSize, tt.fieldOfView)
+			require.NoError(t, err)
+			assert.Equal(t, tt.hSize, c.HSize())
+			assert.Equal(t, tt.vSize, c.VSize())
+			if tt.transform != nil {
+				require.NoError(t, c.SetTransform(tt.transform))
+			}
+			r := c.RayForPixel(tt.nx, tt.ny)
+			assertVectorEqual(t, tt.origin, r.Origin())
+			assertVectorEqual(t, tt.direction, r.Direction())
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package scene
+
+import (
+	"math"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+// orthonormalBasis returns two vectors perpendicular to n and each other.
+func orthonormalBasis(n ray.Vector) (t, b ray.Vector) {
+	if math.Abs(n.GetX()) > 0.9 {
+		t = ray.Cross(ray.NewVec(0, 1, 0), n).Normalize()
+	} else {
+		t = ray.Cross(ray.NewVec(1, 0, 0), n).Normalize()
+	}
+	return t, ray.Cross(n, t)
+}
+
+// fromBasis turns a direction local to axis (z along axis) into world space.
+func fromBasis(axis ray.Vector, x, y, z float64) ray.Vector {
+	t, b := orthonormalBasis(axis)
+	return t.Multiply(x).
+		Add(b.Multiply(y)).
+		Add(axis.Multiply(z))
+}
+
+// sampleCosineHemisphere picks a direction around n with density cos/π.
+func sampleCosineHemisphere(n ray.Vector, u1, u2 float64) ray.Vector {
+	r := math.Sqrt(u1)
+	phi := 2 * math.Pi * u2
+	return fromBasis(n, r*math.Cos(phi), r*math.Sin(phi), math.Sqrt(math.Max(0, 1-u1)))
+}
+
+// samplePhongLobe picks a direction around axis with density
+// (n+1)/2π cos^n of the angle to the axis.
+func samplePhongLobe(axis ray.Vector, exponent, u1, u2 float64) ray.Vector {
+	cosTheta := math.Pow(u1, 1/(exponent+1))
+	sinTheta := math.Sqrt(math.Max(0, 1-cosTheta*cosTheta))
+	phi := 2 * math.Pi * u2
+	return fromBasis(axis, sinTheta*math.Cos(phi), sinTheta*math.Sin(phi), cosTheta)
+}
+
+func phongLobePdf(axis, direction ray.Vector, exponent float64) float64 {
+	cos := ray.Dot(axis, direction)
+	if cos <= 0 {
+		return 0
+	}
+	return (exponent + 1) / (2 * math.Pi) * math.Pow(cos, exponent)
+}
+
+// sampleCone picks a direction uniformly within angle of axis.
+func sampleCone(axis ray.Vector, angle, u1, u2 float64) ray.Vector {
+	cosTheta := 1 - u1*(1-math.Cos(angle))
+	sinTheta := math.Sqrt(math.Max(0, 1-cosTheta*cosTheta))
+	phi := 2 * math.Pi * u2
+	return fromBasis(axis, sinTheta*math.Cos(phi), sinTheta*math.Sin(phi), cosTheta)
+}
+
+// glossyDirection jitters a reflected or refracted direction by the material
+// roughness, keeping it on the same side of the surface.
+func glossyDirection(direction, normalv ray.Vector, roughness, u1, u2 float64) ray.Vector {
+	if roughness <= 0 {
+		return direction
+	}
+	jittered := sampleCone(direction.Normalize(), clamp(roughness, 0, 1)*math.Pi/2, u1, u2)
+	if side := ray.Dot(direction, normalv); side*ray.Dot(jittered, normalv) < 0 {
+		// mirror back across the surface rather than go through it
+		jittered = jittered.Subtract(normalv.Multiply(2 * ray.Dot(jittered, normalv)))
+	}
+	return jittered
+}
+
+// sampleGGX picks a microfacet normal with density D(h) cos and reflects the
+// eye about it.
+func sampleGGX(n, eyev ray.Vector, alpha, u1, u2 float64) ray.Vector {
+	cosTheta := math.Sqrt((1 - u1) / (1 + (alpha*alpha-1)*u1))
+	sinTheta := math.Sqrt(math.Max(0, 1-cosTheta*cosTheta))
+	phi := 2 * math.Pi * u2
+	h := fromBasis(n, sinTheta*math.Cos(phi), sinTheta*math.Sin(phi), cosTheta)
+	return eyev.Negate().Reflect(h)
+}
+
+func ggxPdf(n, eyev, direction ray.Vector, alpha float64) float64 {
+	h := direction.Add(eyev).Normalize()
+	vdh := ray.Dot(eyev, h)
+	if vdh <= 0 {
+		return 0
+	}
+	ndh := ray.Dot(n, h)
+	return object.GGX(ndh, alpha) * ndh / (4 * vdh)
+}
+
+// powerHeuristic weighs a sample from one strategy against another that
+// could also have produced it.
+func powerHeuristic(pdf, otherPdf float64) float64 {
+	a := pdf * pdf
+	b := otherPdf * otherPdf
+	if a+b == 0 {
+		return 0
+	}
+	return a / (a + b)
+}
+
+// sampleDisk picks a point uniformly on the unit disk.
+func sampleDisk(u1, u2 float64) (x, y float64) {
+	r := math.Sqrt(u1)
+	phi := 2 * math.Pi * u2
+	return r * math.Cos(phi), r * math.Sin(phi)
+}
+
+// samplePolygon picks a point uniformly on a regular polygon with its
+// corners on the unit circle, the first at angle rotation.
+func samplePolygon(sides int, rotation, u1, u2 float64) (x, y float64) {
+	// pick a wedge from the centre, then reuse what's left of u1 within it
+	n := float64(sides)
+	wedge := math.Min(math.Floor(u1*n), n-1)
+	u1 = u1*n - wedge
+	a0 := rotation + 2*math.Pi*wedge/n
+	a1 := rotation + 2*math.Pi*(wedge+1)/n
+
+	su := math.Sqrt(u1)
+	b0, b1 := su*(1-u2), su*u2
+	return b0*math.Cos(a0) + b1*math.Cos(a1), b0*math.Sin(a0) + b1*math.Sin(a1)
+}
@@ -0,0 +1,93 @@
+package scene
+
+import (
+	"math"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+// ShadowTransmittance is the light that gets from point along direction as
+// far as distance (in lengths of direction). With TransparentShadows on,
+// transparent objects on the way dim and tint it by their colour and
+// absorption, otherwise every shadow caster stops it. Fog and volumes on the
+// way dim it too, unless distance is infinite.
+func (w *world) ShadowTransmittance(point, direction ray.Vector, distance float64) object.RGB {
+	return shadowTransmittance(w, point, direction, distance, false, 0)
+}
+
+// LightTransmittance is the light from the point light that gets to point.
+func (w *world) LightTransmittance(point ray.Vector) object.RGB {
+	return lightTransmittance(w, point, 0)
+}
+
+// lightTransmittance is LightTransmittance with the moving objects where
+// they were at time.
+func lightTransmittance(w World, point ray.Vector, time float64) object.RGB {
+	v := w.Light().Position.Subtract(point)
+	return shadowTransmittance(w, point, v.Normalize(), v.Magnitude(), false, time)
+}
+
+func (w *world) IsShadowed(point ray.Vector) bool {
+	return w.LightTransmittance(point) == object.Black
+}
+
+// IsOccluded reports whether anything that casts shadows lies along the ray
+// from point toward direction.
+func (w *world) IsOccluded(point, direction ray.Vector) bool {
+	return isBlocked(w, point, direction, math.Inf(1))
+}
+
+// isBlocked is like ShadowTransmittance with every shadow caster opaque,
+// for integrators that find the light through glass by themselves.
+func isBlocked(w World, point, direction ray.Vector, distance float64) bool {
+	return shadowTransmittance(w, point, direction, distance, true, 0) == object.Black
+}
+
+func shadowTransmittance(w World, point, direction ray.Vector, distance float64, opaque bool, time float64) object.RGB {
+	r := ray.NewRayAt(point, direction)
+	r.SetTime(time)
+	length := direction.Magnitude()
+	transmittance := object.White
+	// where the ray went into each object it is still inside
+	entered := map[object.Object]float64{}
+	for _, x := range Intersect(w, r) {
+		if x.T < 0 || !x.Obj.CastsShadow() {
+			continue
+		}
+		if x.T >= distance {
+			break
+		}
+		m := x.Obj.Material()
+		if opaque || !w.TransparentShadows() || m.Transparency <= 0 {
+			return object.Black
+		}
+
+		p := r.PointAt(x.T)
+		if ray.Dot(object.NormalAt(x, p), direction) < 0 {
+			transmittance = transmittance.Multiply(m.ColorAt(x.Obj, p).MultiplyBy(m.Transparency))
+			entered[x.Obj] = x.T
+		} else {
+			// leaving, so absorbed over the distance inside
+			transmittance = transmittance.Multiply(m.Transmittance((x.T - entered[x.Obj]) * length))
+			delete(entered, x.Obj)
+		}
+		if transmittance == object.Black {
+			return transmittance
+		}
+	}
+	if !math.IsInf(distance, 1) && hasMedia(w) {
+		to := point.Add(direction.Multiply(distance))
+		transmittance = transmittance.MultiplyBy(math.Exp(-opticalDepth(w, point, to, time)))
+	}
+	return transmittance
+}
+
+// visibility is how much light arriving from direction gets to the hit,
+// skipping the shadow rays for objects that don't receive shadows.
+func visibility(w World, comps Computation, direction ray.Vector, distance float64, opaque bool) object.RGB {
+	if !comps.obj.ReceivesShadow() {
+		return object.White
+	}
+	return shadowTransmittance(w, comps.overPoint, direction, distance, opaque, comps.time)
+}
@@ -0,0 +1,175 @@
+package scene
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+)
+
+// StereoLayout is how the two eyes' renders are put into one image.
+type StereoLayout string
+
+const (
+	SideBySide StereoLayout = "side-by-side"
+	OverUnder  StereoLayout = "over-under"
+	Anaglyph   StereoLayout = "anaglyph"
+)
+
+func ParseStereoLayout(name string) (StereoLayout, error) {
+	switch l := StereoLayout(strings.ToLower(name)); l {
+	case SideBySide, OverUnder, Anaglyph:
+		return l, nil
+	default:
+		return "", fmt.Errorf("unknown stereo layout %q, expected side-by-side, over-under or anaglyph", name)
+	}
+}
+
+// StereoRig is a pair of eyes either side of a camera, Interocular apart
+// and converging Convergence in front of it, where things appear at the
+// depth of the screen.
+type StereoRig struct {
+	Camera      Camera
+	Interocular float64
+	Convergence float64
+}
+
+func NewStereoRig(c Camera, interocular, convergence float64) StereoRig {
+	return StereoRig{
+		Camera:      c,
+		Interocular: interocular,
+		Convergence: convergence,
+	}
+}
+
+func (s StereoRig) Left() Camera {
+	// the camera's +x is on the left of the image
+	return newEyeCamera(s.Camera, s.Interocular/2, s.Convergence)
+}
+
+func (s StereoRig) Right() Camera {
+	return newEyeCamera(s.Camera, -s.Interocular/2, s.Convergence)
+}
+
+// Render draws both eyes and puts them together with layout.
+func (s StereoRig) Render(w World, settings RenderSettings, layout StereoLayout, noOfWorkers int) (Canvas, error) {
+	if err := checkImageSize(s.Camera); err != nil {
+		return nil, err
+	}
+	left := MultiThreadedRenderWith(s.Left(), w, settings, noOfWorkers)
+	right := MultiThreadedRenderWith(s.Right(), w, settings, noOfWorkers)
+	return ComposeStereo(layout, left, right)
+}
+
+// eyeCamera moves a camera's rays sideways by offset, turning them in so
+// they still meet the original rays convergence along the view.
+type eyeCamera struct {
+	Camera
+	offset, convergence float64
+	side, forward       ray.Vector
+}
+
+func newEyeCamera(c Camera, offset, convergence float64) *eyeCamera {
+	e := &eyeCamera{Camera: c, offset: offset, convergence: convergence}
+	e.orient()
+	return e
+}
+
+// orient finds the world directions the camera's sideways and forward.
+func (e *eyeCamera) orient() {
+	inv, err := e.Transform().Inverse()
+	if err != nil {
+		inv = ray.DefaultIdentityMatrix()
+	}
+	e.side = inv.MultiplyByVector(ray.NewVec(1, 0, 0)).Normalize()
+	e.forward = inv.MultiplyByVector(ray.NewVec(0, 0, -1)).Normalize()
+}
+
+func (e *eyeCamera) SetTransform(by ray.Matrix) error {
+	err := e.Camera.SetTransform(by)
+	e.orient()
+	return err
+}
+
+func (e *eyeCamera) SetLookAt(from, to, up ray.Vector) error {
+	err := e.Camera.SetLookAt(from, to, up)
+	e.orient()
+	return err
+}
+
+func (e *eyeCamera) SetRoll(roll float64) error {
+	err := e.Camera.SetRoll(roll)
+	e.orient()
+	return err
+}
+
+func (e eyeCamera) RayForPixel(nx, ny float64) ray.Ray {
+	r := e.Camera.RayForPixel(nx, ny)
+
+	// meet on the plane convergence in front, or the sphere for rays that
+	// look sideways or behind like a panorama's
+	t := e.convergence
+	if cos := ray.Dot(r.Direction(), e.forward); cos > 0.1 {
+		t /= cos
+	}
+	target := r.PointAt(t)
+	origin := r.Origin().Add(e.side.Multiply(e.offset))
+	eye := ray.NewRayAt(origin, target.Subtract(origin).Normalize())
+	eye.SetTime(r.Time())
+	return eye
+}
+
+// ComposeStereo puts the left and right eyes' renders into one image.
+func ComposeStereo(layout StereoLayout, left, right Canvas) (Canvas, error) {
+	if left.Width() != right.Width() || left.Height() != right.Height() {
+		return nil, fmt.Errorf("eyes are different sizes, %vx%v and %vx%v",
+			left.Width(), left.Height(), right.Width(), right.Height())
+	}
+	switch layout {
+	case SideBySide:
+		return StereoSideBySide(left, right), nil
+	case OverUnder:
+		return StereoOverUnder(left, right), nil
+	case Anaglyph:
+		return StereoAnaglyph(left, right), nil
+	default:
+		return nil, fmt.Errorf("unknown stereo layout %q", layout)
+	}
+}
+
+// StereoSideBySide puts the left eye on the left and the right eye on the
+// right, twice as wide as each.
+func StereoSideBySide(left, right Canvas) Canvas {
+	w, h := left.Width(), left.Height()
+	c := NewCanvas(w*2, h)
+	for x := 0; x < w; x++ {
+		copy(c[x], left[x])
+		copy(c[x+w], right[x])
+	}
+	return c
+}
+
+// StereoOverUnder puts the left eye above the right eye.
+func StereoOverUnder(left, right Canvas) Canvas {
+	w, h := left.Width(), left.Height()
+	c := NewCanvas(w, h*2)
+	for x := 0; x < w; x++ {
+		copy(c[x], left[x])
+		copy(c[x][h:], right[x])
+	}
+	return c
+}
+
+// StereoAnaglyph is a red/cyan colour anaglyph, red from the left eye and
+// green and blue from the right, for glasses with the red lens on the left.
+func StereoAnaglyph(left, right Canvas) Canvas {
+	w, h := left.Width(), left.Height()
+	c := NewCanvas(w, h)
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			c[x][y] = object.NewColor(left[x][y].R, right[x][y].G, right[x][y].B)
+		}
+	}
+	return c
+}
@@ -0,0 +1,121 @@
+package scene_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/ray"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+func TestParseStereoLayout(t *testing.T) {
+	for _, name := range []string{"side-by-side", "over-under", "Anaglyph"} {
+		_, err := scene.ParseStereoLayout(name)
+		assert.NoError(t, err, name)
+	}
+	_, err := scene.ParseStereoLayout("interlaced")
+	assert.Error(t, err)
+}
+
+func TestStereoRig(t *testing.T) {
+	testCases := []struct {
+		name      string
+		transform ray.Matrix
+		left      ray.Vector
+		right     ray.Vector
+	}{
+		{
+			name:  "eyes either side of the camera",
+			left:  ray.NewPoint(0.5, 0, 0),
+			right: ray.NewPoint(-0.5, 0, 0),
+		},
+		{
+			name:      "eyes follow the camera",
+			transform: ray.Rotation(ray.Y, math.Pi/2).Multiply(ray.Translation(0, -2, 0)),
+			left:      ray.NewPoint(0, 2, 0.5),
+			right:     ray.NewPoint(0, 2, -0.5),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := scene.NewBasicCamera(201, 101, math.Pi/2)
+			require.NoError(t, err)
+			if tt.transform != nil {
+				require.NoError(t, c.SetTransform(tt.transform))
+			}
+			rig := scene.NewStereoRig(c, 1, 4)
+			left, right := rig.Left(), rig.Right()
+			assert.Equal(t, c.HSize(), left.HSize())
+			assert.Equal(t, c.VSize(), right.VSize())
+
+			for _, pixel := range [][2]float64{{100, 50}, {0, 0}, {150, 20}} {
+				centre := c.RayForPixel(pixel[0], pixel[1])
+				l := left.RayForPixel(pixel[0], pixel[1])
+				r := right.RayForPixel(pixel[0], pixel[1])
+				assertVectorEqual(t, tt.left, l.Origin())
+				assertVectorEqual(t, tt.right, r.Origin())
+
+				// both eyes see the same point on the plane of convergence
+				forward := c.RayForPixel(100, 50).Direction()
+				target := centre.PointAt(4 / ray.Dot(centre.Direction(), forward))
+				assertVectorEqual(t, target.Subtract(l.Origin()).Normalize(), l.Direction())
+				assertVectorEqual(t, target.Subtract(r.Origin()).Normalize(), r.Direction())
+			}
+		})
+	}
+
+	t.Run("moving an eye moves the camera", func(t *testing.T) {
+		c, err := scene.NewBasicCamera(201, 101, math.Pi/2)
+		require.NoError(t, err)
+		left := scene.NewStereoRig(c, 1, 4).Left()
+		require.NoError(t, left.SetTransform(ray.Translation(0, -2, 0)))
+		assertVectorEqual(t, ray.NewPoint(0.5, 2, 0), left.RayForPixel(100, 50).Origin())
+		assertVectorEqual(t, ray.NewPoint(0, 2, 0), c.RayForPixel(100, 50).Origin())
+	})
+}
+
+func TestComposeStereo(t *testing.T) {
+	left := scene.NewCanvas(2, 1)
+	left.SetColor(0, 0, object.NewColor(1, 0.5, 0.25))
+	left.SetColor(1, 0, object.White)
+	right := scene.NewCanvas(2, 1)
+	right.SetColor(0, 0, object.NewColor(0.1, 0.2, 0.3))
+
+	t.Run("side by side", func(t *testing.T) {
+		c, err := scene.ComposeStereo(scene.SideBySide, left, right)
+		require.NoError(t, err)
+		require.Equal(t, 4, c.Width())
+		require.Equal(t, 1, c.Height())
+		assert.Equal(t, left.Get(0, 0), c.Get(0, 0))
+		assert.Equal(t, left.Get(1, 0), c.Get(1, 0))
+		assert.Equal(t, right.Get(0, 0), c.Get(2, 0))
+		assert.Equal(t, right.Get(1, 0), c.Get(3, 0))
+	})
+
+	t.Run("over under", func(t *testing.T) {
+		c, err := scene.ComposeStereo(scene.OverUnder, left, right)
+		require.NoError(t, err)
+		require.Equal(t, 2, c.Width())
+		require.Equal(t, 2, c.Height())
+		assert.Equal(t, left.Get(0, 0), c.Get(0, 0))
+		assert.Equal(t, right.Get(0, 0), c.Get(0, 1))
+		assert.Equal(t, left.Get(1, 0), c.Get(1, 0))
+	})
+
+	t.Run("anaglyph", func(t *testing.T) {
+		c, err := scene.ComposeStereo(scene.Anaglyph, left, right)
+		require.NoError(t, err)
+		require.Equal(t, 2, c.Width())
+		assert.Equal(t, object.NewColor(1, 0.2, 0.3), c.Get(0, 0))
+		assert.Equal(t, object.NewColor(1, 0, 0), c.Get(1, 0))
+	})
+
+	t.Run("eyes must match", func(t *testing.T) {
+		_, err := scene.ComposeStereo(scene.Anaglyph, left, scene.NewCanvas(1, 1))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,204 @@
+package scene
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+)
+
+const (
+	DefaultTileSize = 32
+)
+
+// ErrEmptyImage is returned when asked to render an image with no pixels,
+// there'd be nothing to write.
+var ErrEmptyImage = errors.New("image has no pixels")
+
+// Tile is a rectangle of pixels, rendered by a worker in one go.
+type Tile struct {
+	X, Y          int
+	Width, Height int
+}
+
+// TileEvent is sent as each tile is finished.
+type TileEvent struct {
+	Tile Tile
+	// Done is how many tiles are finished, this one included, of Total
+	Done, Total int
+	// Elapsed is the time since the render started
+	Elapsed time.Duration
+}
+
+// Remaining guesses how much longer the render will take, if the rest of
+// the tiles take as long as the ones so far.
+func (e TileEvent) Remaining() time.Duration {
+	if e.Done == 0 {
+		return 0
+	}
+	return time.Duration(float64(e.Elapsed) * float64(e.Total-e.Done) / float64(e.Done))
+}
+
+type tileRenderer struct {
+	workers  int
+	tileSize int
+	events   chan<- TileEvent
+}
+
+type TileOption func(r *tileRenderer)
+
+// WithWorkers renders that many tiles at once, one by default.
+func WithWorkers(workers int) TileOption {
+	return func(r *tileRenderer) {
+		r.workers = workers
+	}
+}
+
+// WithTileSize makes the tiles size pixels square, smaller at the right and
+// bottom edges when the image doesn't divide up evenly.
+func WithTileSize(size int) TileOption {
+	return func(r *tileRenderer) {
+		r.tileSize = size
+	}
+}
+
+// WithTileEvents sends a TileEvent to events as each tile finishes. The
+// render waits for them to be received, so events should be buffered or
+// read promptly, and it isn't closed afterwards.
+func WithTileEvents(events chan<- TileEvent) TileOption {
+	return func(r *tileRenderer) {
+		r.events = events
+	}
+}
+
+// Tiles splits an image up into tiles of size pixels square, a row at a
+// time from the top left.
+func Tiles(hSize, vSize, size int) (tiles []Tile) {
+	if size < 1 {
+		size = DefaultTileSize
+	}
+	for y := 0; y < vSize; y += size {
+		for x := 0; x < hSize; x += size {
+			tiles = append(tiles, Tile{
+				X:      x,
+				Y:      y,
+				Width:  minInt(size, hSize-x),
+				Height: minInt(size, vSize-y),
+			})
+		}
+	}
+	return tiles
+}
+
+// RenderTiles renders the image a tile at a time across the workers.
+// Cancelling ctx stops it promptly, returning what was rendered so far
+// along with the context's error.
+func RenderTiles(ctx context.Context, c Camera, w World, settings RenderSettings, opts ...TileOption) (Canvas, error) {
+	r := tileRenderer{workers: 1, tileSize: DefaultTileSize}
+	for i := range opts {
+		opts[i](&r)
+	}
+	if r.workers < 1 {
+		r.workers = 1
+	}
+	if err := checkImageSize(c); err != nil {
+		return nil, err
+	}
+
+	canvas := NewCanvas(c.HSize(), c.VSize())
+	tiles := Tiles(c.HSize(), c.VSize(), r.tileSize)
+	start := time.Now()
+
+	mu := sync.Mutex{}
+	done := 0
+	forEachTile(ctx, tiles, r.workers, func(t Tile) {
+		if !renderTile(ctx, canvas, c, w, settings, t) {
+			return
+		}
+		// each tile has its own pixels, only the count is shared
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		if r.events != nil {
+			select {
+			case r.events <- TileEvent{Tile: t, Done: done, Total: len(tiles), Elapsed: time.Since(start)}:
+			case <-ctx.Done():
+			}
+		}
+	})
+	return canvas, ctx.Err()
+}
+
+func checkImageSize(c Camera) error {
+	if c.HSize() < 1 || c.VSize() < 1 {
+		return fmt.Errorf("%w: %v,%v", ErrEmptyImage, c.HSize(), c.VSize())
+	}
+	return nil
+}
+
+// forEachTile hands the tiles out to the workers, returning once they're
+// all done or ctx is cancelled.
+func forEachTile(ctx context.Context, tiles []Tile, workers int, render func(t Tile)) {
+	queue := make(chan Tile)
+	go func() {
+		defer close(queue)
+		for _, t := range tiles {
+			select {
+			case queue <- t:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for t := range queue {
+				render(t)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// renderTile fills in the tile's pixels, false if it was cancelled part
+// way through.
+func renderTile(ctx context.Context, canvas Canvas, c Camera, w World, settings RenderSettings, t Tile) bool {
+	for y := t.Y; y < t.Y+t.Height; y++ {
+		if ctx.Err() != nil {
+			return false
+		}
+		for x := t.X; x < t.X+t.Width; x++ {
+			canvas[x][y] = RenderPixel(c, w, settings, x, y)
+		}
+	}
+	return true
+}
+
+// RenderTile renders just the tile, its pixels a row at a time from the
+// top left. Cancelling ctx stops it, returning the context's error.
+func RenderTile(ctx context.Context, c Camera, w World, settings RenderSettings, t Tile) ([]object.RGB, error) {
+	pixels := make([]object.RGB, 0, t.Width*t.Height)
+	for y := t.Y; y < t.Y+t.Height; y++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for x := t.X; x < t.X+t.Width; x++ {
+			pixels = append(pixels, RenderPixel(c, w, settings, x, y))
+		}
+	}
+	return pixels, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
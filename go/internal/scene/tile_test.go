@@ -0,0 +1,121 @@
+package scene_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+func TestTiles(t *testing.T) {
+	tiles := scene.Tiles(10, 5, 4)
+	assert.Equal(t, []scene.Tile{
+		{X: 0, Y: 0, Width: 4, Height: 4},
+		{X: 4, Y: 0, Width: 4, Height: 4},
+		{X: 8, Y: 0, Width: 2, Height: 4},
+		{X: 0, Y: 4, Width: 4, Height: 1},
+		{X: 4, Y: 4, Width: 4, Height: 1},
+		{X: 8, Y: 4, Width: 2, Height: 1},
+	}, tiles)
+
+	assert.Len(t, scene.Tiles(64, 64, 0), 4, "default tile size")
+}
+
+func TestTileEvent_Remaining(t *testing.T) {
+	e := scene.TileEvent{Done: 1, Total: 4, Elapsed: time.Second}
+	assert.Equal(t, 3*time.Second, e.Remaining())
+	assert.Equal(t, time.Duration(0), scene.TileEvent{Total: 4}.Remaining())
+}
+
+func TestRenderTiles(t *testing.T) {
+	w := scene.NewWorld()
+	w.SetBackground(scene.NewSolidBackground(object.White))
+	c, err := scene.NewBasicCamera(13, 7, math.Pi/2)
+	require.NoError(t, err)
+
+	t.Run("renders every pixel", func(t *testing.T) {
+		events := make(chan scene.TileEvent, 100)
+		canvas, err := scene.RenderTiles(context.Background(), c, w, scene.DefaultRenderSettings(),
+			scene.WithWorkers(3), scene.WithTileSize(4), scene.WithTileEvents(events))
+		require.NoError(t, err)
+		for x := 0; x < c.HSize(); x++ {
+			for y := 0; y < c.VSize(); y++ {
+				assert.Equal(t, object.White, canvas[x][y], "pixel %v,%v", x, y)
+			}
+		}
+
+		close(events)
+		count := 0
+		for e := range events {
+			count++
+			assert.Equal(t, count, e.Done, "counts up")
+			assert.Equal(t, 8, e.Total)
+		}
+		assert.Equal(t, 8, count)
+	})
+
+	t.Run("same as rendering one pixel at a time", func(t *testing.T) {
+		w, c := setupRenderTest(t)
+		canvas, err := scene.RenderTiles(context.Background(), c, w, scene.DefaultRenderSettings(), scene.WithWorkers(4))
+		require.NoError(t, err)
+		assert.Equal(t, scene.Render(c, w), canvas)
+	})
+
+	t.Run("stops when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		events := make(chan scene.TileEvent)
+		go func() {
+			<-events
+			cancel()
+		}()
+		canvas, err := scene.RenderTiles(ctx, c, w, scene.DefaultRenderSettings(),
+			scene.WithTileSize(1), scene.WithTileEvents(events))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, object.White, canvas[0][0], "keeps what was rendered")
+		assert.Equal(t, object.Black, canvas[12][6])
+	})
+
+	t.Run("rejects an image with no pixels", func(t *testing.T) {
+		for _, size := range [][2]int{{0, 0}, {640, 0}, {0, 360}} {
+			c, err := scene.NewBasicCamera(size[0], size[1], math.Pi/2)
+			require.NoError(t, err)
+			_, err = scene.RenderTiles(context.Background(), c, w, scene.DefaultRenderSettings())
+			assert.ErrorIs(t, err, scene.ErrEmptyImage, "%v,%v", size[0], size[1])
+		}
+	})
+}
+
+func TestRenderWith_everyPixel(t *testing.T) {
+	w := scene.NewWorld()
+	w.SetBackground(scene.NewSolidBackground(object.White))
+	c, err := scene.NewBasicCamera(5, 3, math.Pi/2)
+	require.NoError(t, err)
+	canvas := scene.RenderWith(c, w, scene.DefaultRenderSettings())
+	assert.Equal(t, object.White, canvas[4][2], "last row and column")
+	canvas = scene.MultiThreadedRenderWith(c, w, scene.DefaultRenderSettings(), 2)
+	assert.Equal(t, object.White, canvas[4][2], "last row and column")
+}
+
+func TestRenderTile(t *testing.T) {
+	w, c := setupRenderTest(t)
+	whole := scene.Render(c, w)
+
+	tile := scene.Tile{X: 3, Y: 2, Width: 4, Height: 3}
+	pixels, err := scene.RenderTile(context.Background(), c, w, scene.DefaultRenderSettings(), tile)
+	require.NoError(t, err)
+	require.Len(t, pixels, 12)
+	for i, p := range pixels {
+		assert.Equal(t, whole[tile.X+i%tile.Width][tile.Y+i/tile.Width], p, "pixel %v", i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = scene.RenderTile(ctx, c, w, scene.DefaultRenderSettings(), tile)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -2,6 +2,7 @@ package scene
 
 import (
 	"math"
+	"math/rand"
 	"sort"
 
 	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/object"
@@ -16,11 +17,33 @@ type World interface {
 	AddLight(light object.PointLight)
 	ColorAt(r ray.Ray, remaining int) object.RGB
 	IsShadowed(point ray.Vector) bool
+	LightTransmittance(point ray.Vector) object.RGB
+	ShadowTransmittance(point, direction ray.Vector, distance float64) object.RGB
+	TransparentShadows() bool
+	SetTransparentShadows(on bool)
+	Fog() Medium
+	SetFog(fog Medium)
+	Volumes() []Volume
+	AddVolume(boundary object.Object, medium Medium)
+	Background() Background
+	SetBackground(background Background)
+	EnvironmentLight() *EnvironmentLight
+	SetEnvironmentLight(light *EnvironmentLight)
+	IsOccluded(point, direction ray.Vector) bool
+	Emitters() []object.Object
 }
 
 type world struct {
-	objs  []object.Object
-	light object.PointLight
+	objs       []object.Object
+	light      object.PointLight
+	fog        Medium
+	volumes    []Volume
+	background Background
+	envLight   *EnvironmentLight
+	// emitters are collected as objects are added, ShadeHit needs them for
+	// every hit
+	emitters           []object.Object
+	transparentShadows bool
 }
 
 func (w world) Objects() []object.Object {
@@ -29,11 +52,12 @@ func (w world) Objects() []object.Object {
 
 func (w *world) AddObject(obj object.Object) {
 	w.objs = append(w.objs, obj)
+	w.emitters = appendEmitters(w.emitters, obj)
 }
 
 func (w *world) AddObjects(objs ...object.Object) {
 	for _, obj := range objs {
-		w.objs = append(w.objs, obj)
+		w.AddObject(obj)
 	}
 }
 
@@ -45,29 +69,73 @@ func (w *world) AddLight(light object.PointLight) {
 	w.light = light
 }
 
+func (w world) Fog() Medium {
+	return w.fog
+}
+
+func (w *world) SetFog(fog Medium) {
+	w.fog = fog
+}
+
+func (w world) Volumes() []Volume {
+	return w.volumes
+}
+
+func (w *world) AddVolume(boundary object.Object, medium Medium) {
+	w.volumes = append(w.volumes, Volume{
+		Boundary: boundary,
+		Medium:   medium,
+	})
+}
+
+func (w world) Background() Background {
+	return w.background
+}
+
+func (w *world) SetBackground(background Background) {
+	w.background = background
+}
+
+func (w world) EnvironmentLight() *EnvironmentLight {
+	return w.envLight
+}
+
+func (w *world) SetEnvironmentLight(light *EnvironmentLight) {
+	w.envLight = light
+}
+
+// TransparentShadows reports whether transparent objects let light through
+// to the surfaces behind them, off by default like in the book.
+func (w world) TransparentShadows() bool {
+	return w.transparentShadows
+}
+
+func (w *world) SetTransparentShadows(on bool) {
+	w.transparentShadows = on
+}
+
+// Emitters are the emissive objects that can be sampled as lights.
+func (w world) Emitters() []object.Object {
+	return w.emitters
+}
+
 func (w *world) ColorAt(r ray.Ray, remaining int) (color object.RGB) {
 	intersections := Intersect(w, r)
 	hit := object.Hit(intersections)
-	if hit == object.NoHit {
-		return color
+	distance := math.Inf(1)
+	if hit != object.NoHit {
+		distance = hit.T
+		color = ShadeHit(
+			w,
+			PrepareComputations(hit, r, intersections...),
+			remaining)
+	} else if w.background != nil {
+		color = w.background.ColorFor(r.Direction())
 	}
-	return ShadeHit(
-		w,
-		PrepareComputations(hit, r, intersections...),
-		remaining)
-}
-
-func (w *world) IsShadowed(point ray.Vector) bool {
-	v := w.light.Position.Subtract(point)
-	distance := v.Magnitude()
-	direction := v.Normalize()
-	r := ray.NewRayAt(point, direction)
-	intersections := Intersect(w, r)
-	h := object.Hit(intersections)
-	if h != object.NoHit && h.T < distance {
-		return true
+	if !hasMedia(w) {
+		return color
 	}
-	return false
+	return w.applyMedia(r, distance, color)
 }
 
 func NewWorld() World {
@@ -91,23 +159,39 @@ func ShadeHit(w World, comps Computation, remaining int) object.RGB {
 	reflected := ReflectedColor(w, comps, remaining)
 	refracted := RefractedColor(w, comps, remaining)
 
-	surface := object.Lighting(
-		comps.obj.Material(),
-		comps.obj,
-		w.Light(),
-		comps.overPoint, comps.eyev, comps.normalv,
-		w.IsShadowed(comps.overPoint))
+	surface := comps.obj.Material().Emitted()
+	if w.Light().Position != nil {
+		transmittance := object.White
+		if comps.obj.ReceivesShadow() {
+			transmittance = lightTransmittance(w, comps.overPoint, comps.time)
+		}
+		surface = surface.Add(object.LightingThrough(
+			comps.obj.Material(),
+			comps.obj,
+			w.Light(),
+			comps.overPoint, comps.eyev, comps.normalv,
+			transmittance))
+	}
+	if w.EnvironmentLight() != nil {
+		surface = surface.Add(EnvironmentLighting(w, comps))
+	}
+	if emitters := w.Emitters(); len(emitters) > 0 {
+		surface = surface.Add(emitterLighting(w, comps, emitters))
+	}
 
+	color := surface.Add(reflected).Add(refracted)
 	if comps.obj.Material().Reflective > 0 &&
 		comps.obj.Material().Transparency > 0 {
 		reflectance := Schlick(comps)
-		return surface.
+		color = surface.
 			Add(reflected.MultiplyBy(reflectance)).
 			Add(refracted.MultiplyBy(1 - reflectance))
 	}
-	return surface.
-		Add(reflected).
-		Add(refracted)
+	if comps.inside {
+		// the light travelled through the object to get back to the ray's origin
+		color = color.Multiply(comps.obj.Material().Transmittance(comps.distance))
+	}
+	return color
 }
 
 func ReflectedColor(w World, comps Computation, remaining int) object.RGB {
@@ -117,9 +201,8 @@ func ReflectedColor(w World, comps Computation, remaining int) object.RGB {
 	if comps.obj.Material().Reflective == 0 {
 		return object.Black
 	}
-	reflectRay := ray.NewRayAt(comps.overPoint, comps.reflectv)
 	remaining--
-	color := w.ColorAt(reflectRay, remaining)
+	color := glossyColor(w, comps, comps.overPoint, comps.reflectv, remaining)
 	return color.MultiplyBy(comps.obj.Material().Reflective)
 }
 
@@ -131,20 +214,86 @@ func RefractedColor(w World, comps Computation, remaining int) object.RGB {
 		return object.Black
 	}
 
+	if comps.wavelength == 0 && comps.obj.Material().Disperses() {
+		return dispersedColor(w, comps, remaining).
+			MultiplyBy(comps.obj.Material().Transparency)
+	}
+
+	direction, ok := refractDirection(comps)
+	if !ok {
+		return object.Black
+	}
+
+	remaining--
+	return glossyColor(w, comps, comps.underPoint, direction, remaining).
+		MultiplyBy(comps.obj.Material().Transparency)
+}
+
+var (
+	dispersionWavelengths = [3]float64{object.WavelengthC, object.WavelengthD, object.WavelengthF}
+	dispersionChannels    = [3]object.RGB{object.Red, object.Green, object.Blue}
+)
+
+// dispersedColor splits white light into a wavelength per channel, each
+// bending by its own refractive index.
+func dispersedColor(w World, comps Computation, remaining int) (color object.RGB) {
+	for i, wavelength := range dispersionWavelengths {
+		spectral := spectralComputation(comps, wavelength)
+		direction, ok := refractDirection(spectral)
+		if !ok {
+			continue
+		}
+		c := glossyColor(w, spectral, spectral.underPoint, direction, remaining-1)
+		color = color.Add(c.Multiply(dispersionChannels[i]))
+	}
+	return color
+}
+
+// spectralComputation is comps for a single wavelength passing through the
+// surface of comps' object.
+func spectralComputation(comps Computation, wavelength float64) Computation {
+	comps.wavelength = wavelength
+	if comps.inside {
+		comps.n1 = comps.obj.Material().IndexAt(wavelength)
+	} else {
+		comps.n2 = comps.obj.Material().IndexAt(wavelength)
+	}
+	return comps
+}
+
+// glossyColor averages rays jittered around direction by the material's
+// roughness. Each bounce halves the number of rays so blurry surfaces seen
+// in each other don't multiply the work.
+func glossyColor(w World, comps Computation, origin, direction ray.Vector, remaining int) (color object.RGB) {
+	m := comps.obj.Material()
+	if m.Roughness <= 0 {
+		return w.ColorAt(comps.spawn(origin, direction), remaining)
+	}
+	samples := m.GlossySamples >> uint(comps.bounce)
+	if samples < 1 {
+		samples = 1
+	}
+	for i := 0; i < samples; i++ {
+		// stratify over the cone so few samples still cover it
+		u1 := (float64(i) + rand.Float64()) / float64(samples)
+		jittered := glossyDirection(direction, comps.normalv, m.Roughness, u1, rand.Float64())
+		color = color.Add(w.ColorAt(comps.spawn(origin, jittered), remaining))
+	}
+	return color.MultiplyBy(1 / float64(samples))
+}
+
+// refractDirection bends the eye ray through the surface using Snell's law,
+// failing on total internal reflection.
+func refractDirection(comps Computation) (direction ray.Vector, ok bool) {
 	nRatio := comps.n1 / comps.n2
 	cosI := ray.Dot(comps.eyev, comps.normalv)
 	sin2t := math.Pow(nRatio, 2) * (1 - math.Pow(cosI, 2))
 	if sin2t > 1 {
-		return object.Black
+		return nil, false
 	}
 
 	cosT := math.Sqrt(1.0 - sin2t)
-	direction := comps.normalv.
+	return comps.normalv.
 		Multiply(nRatio*cosI - cosT).
-		Subtract(comps.eyev.Multiply(nRatio))
-
-	refractRay := ray.NewRayAt(comps.underPoint, direction)
-	remaining--
-	return w.ColorAt(refractRay, remaining).
-		MultiplyBy(comps.obj.Material().Transparency)
+		Subtract(comps.eyev.Multiply(nRatio)), true
 }
@@ -508,3 +508,388 @@ func assertColorEqual(t *testing.T, expected object.RGB, actual object.RGB) {
 	assert.InDelta(t, expected.G, actual.G, 0.0001, "G")
 	assert.InDelta(t, expected.B, actual.B, 0.0001, "B")
 }
+
+func TestGlossyReflectionAndRefraction(t *testing.T) {
+	testCases := []struct {
+		name      string
+		roughness float64
+		refract   bool
+		bg        scene.Background
+		expected  object.RGB
+		less      bool
+	}{
+		{
+			name:     "A smooth mirror reflects a single direction",
+			bg:       scene.NewGradientBackground(object.Black, object.White),
+			expected: object.White,
+		},
+		{
+			name:      "A rough mirror blurs what it reflects",
+			roughness: 0.5,
+			bg:        scene.NewGradientBackground(object.Black, object.White),
+			expected:  object.NewColor(0.99, 0.99, 0.99),
+			less:      true,
+		},
+		{
+			name:      "A rough mirror never reflects through its surface",
+			roughness: 1,
+			bg:        scene.NewSolidBackground(object.White),
+			expected:  object.White,
+		},
+		{
+			name:     "Clear glass refracts a single direction",
+			refract:  true,
+			bg:       scene.NewGradientBackground(object.Black, object.White),
+			expected: object.White,
+		},
+		{
+			name:      "Frosted glass blurs what is behind it",
+			roughness: 0.5,
+			refract:   true,
+			bg:        scene.NewGradientBackground(object.Black, object.White),
+			expected:  object.NewColor(0.99, 0.99, 0.99),
+			less:      true,
+		},
+		{
+			name:      "Frosted glass never refracts back out of its surface",
+			roughness: 1,
+			refract:   true,
+			bg:        scene.NewSolidBackground(object.White),
+			expected:  object.White,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			w := scene.NewWorld()
+			w.AddLight(object.NewPointLight(ray.NewPoint(0, 10, 0), object.White))
+			w.SetBackground(tt.bg)
+
+			shape := object.NewPlane()
+			material := shape.Material()
+			material.Ambient, material.Diffuse, material.Specular = 0, 0, 0
+			material.Roughness = tt.roughness
+			material.GlossySamples = 64
+			r := ray.NewRayAt(ray.NewPoint(0, 1, 0), ray.NewVec(0, -1, 0))
+			if tt.refract {
+				material.Transparency = 1
+				r = ray.NewRayAt(ray.NewPoint(0, -1, 0), ray.NewVec(0, 1, 0))
+			} else {
+				material.Reflective = 1
+			}
+			shape.SetMaterial(material)
+			w.AddObject(shape)
+
+			i := object.Intersection{T: 1, Obj: shape}
+			comps := scene.PrepareComputations(i, r, i)
+			actual := scene.ReflectedColor(w, comps, 5)
+			if tt.refract {
+				actual = scene.RefractedColor(w, comps, 5)
+			}
+			if tt.less {
+				assert.Less(t, actual.R, tt.expected.R)
+				assert.Greater(t, actual.R, 0.5)
+				return
+			}
+			assertColorEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+// countingBackground counts the rays that miss everything.
+type countingBackground struct {
+	rays int
+}
+
+func (b *countingBackground) ColorFor(_ ray.Vector) object.RGB {
+	b.rays++
+	return object.White
+}
+
+func TestReflectedColor_glossySamples(t *testing.T) {
+	testCases := []struct {
+		name      string
+		bounce    int
+		remaining int
+		expected  int
+	}{
+		{name: "Every sample on the first bounce", remaining: 5, expected: 16},
+		{name: "However deep the recursion goes", remaining: 2, expected: 16},
+		{name: "Halved for each bounce before", bounce: 2, remaining: 5, expected: 4},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			bg := &countingBackground{}
+			w := scene.NewWorld()
+			w.SetBackground(bg)
+			material := object.DefaultMaterial()
+			material.Reflective = 1
+			material.Roughness = 0.1
+			material.GlossySamples = 16
+			shape := object.NewPlane(object.WithMaterial(material))
+			w.AddObject(shape)
+
+			r := ray.NewRayAt(ray.NewPoint(0, 1, 0), ray.NewVec(0, -1, 0))
+			r.SetBounce(tt.bounce)
+			i := object.Intersection{T: 1, Obj: shape}
+			scene.ReflectedColor(w, scene.PrepareComputations(i, r, i), tt.remaining)
+			assert.Equal(t, tt.expected, bg.rays)
+		})
+	}
+}
+
+func TestWorld_ColorAt_absorption(t *testing.T) {
+	testCases := []struct {
+		name     string
+		radius   float64
+		expected object.RGB
+	}{
+		{name: "Thin glass absorbs a little", radius: 0.5, expected: object.NewColor(0.5, 1, 0.8)},
+		{name: "Thick glass absorbs more", radius: 1, expected: object.NewColor(0.25, 1, 0.64)},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			glass := object.DefaultMaterial()
+			glass.Ambient, glass.Diffuse, glass.Specular = 0, 0, 0
+			glass.Transparency = 1
+			glass.Absorption = object.NewColor(0.5, 1, 0.8)
+			glass.AbsorptionDistance = 1
+
+			w := scene.NewWorld()
+			w.AddLight(object.NewPointLight(ray.NewPoint(0, 10, 0), object.White))
+			w.SetBackground(scene.NewSolidBackground(object.White))
+			w.AddObject(object.NewSphere(ray.ZeroPoint, tt.radius, object.WithMaterial(glass)))
+
+			actual := w.ColorAt(ray.NewRayAt(ray.NewPoint(0, 0, -5), ray.NewVec(0, 0, 1)), 5)
+			assertColorEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestRefractedColor_dispersion(t *testing.T) {
+	testCases := []struct {
+		name      string
+		abbe      float64
+		disperses bool
+	}{
+		{name: "Glass without an Abbe number bends all colours the same", abbe: 0},
+		{name: "Dispersive glass splits colours apart", abbe: 20, disperses: true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			glass := object.DefaultMaterial()
+			glass.Transparency = 1
+			glass.RefractiveIndex = 1.5
+			glass.Abbe = tt.abbe
+			shape := object.NewPlane(object.WithMaterial(glass))
+
+			w := scene.NewWorld()
+			w.AddLight(object.NewPointLight(ray.NewPoint(0, 10, 0), object.White))
+			w.SetBackground(scene.NewGradientBackground(object.White, object.Black))
+			w.AddObject(shape)
+
+			r := ray.NewRayAt(ray.NewPoint(0, 1, -1), ray.NewVec(0, -math.Sqrt(2)/2, math.Sqrt(2)/2))
+			i := object.Intersection{T: math.Sqrt(2), Obj: shape}
+			comps := scene.PrepareComputations(i, r, i)
+			actual := scene.RefractedColor(w, comps, 5)
+
+			if tt.disperses {
+				assert.Greater(t, actual.B, actual.R, "blue bends further toward the white ground than red")
+			} else {
+				assert.InDelta(t, actual.R, actual.B, 1e-9)
+			}
+		})
+	}
+}
+
+func TestWorld_ShadowTransmittance(t *testing.T) {
+	glass := func(color object.RGB, transparency float64) object.Material {
+		m := object.DefaultMaterial()
+		m.Color = color
+		m.Transparency = transparency
+		m.RefractiveIndex = 1.5
+		return m
+	}
+	absorbing := glass(object.White, 1)
+	absorbing.Absorption = object.NewColor(0.5, 1, 1)
+	absorbing.AbsorptionDistance = 1
+
+	testCases := []struct {
+		name     string
+		obj      object.Object
+		distance float64
+		expected object.RGB
+	}{
+		{
+			name:     "An opaque object blocks the light",
+			obj:      object.DefaultSphere(),
+			distance: 10,
+			expected: object.Black,
+		},
+		{
+			name:     "Clear glass lets the light through",
+			obj:      object.DefaultSphere(object.WithMaterial(glass(object.White, 1))),
+			distance: 10,
+			expected: object.White,
+		},
+		{
+			name:     "Coloured glass tints the light",
+			obj:      object.DefaultSphere(object.WithMaterial(glass(object.NewColor(1, 0.5, 0.5), 0.8))),
+			distance: 10,
+			expected: object.NewColor(0.8, 0.4, 0.4),
+		},
+		{
+			name:     "Thick glass absorbs the light",
+			obj:      object.DefaultSphere(object.WithMaterial(absorbing)),
+			distance: 10,
+			expected: object.NewColor(0.25, 1, 1),
+		},
+		{
+			name:     "An object that doesn't cast shadows is ignored",
+			obj:      object.DefaultSphere(object.WithShadows(false, true)),
+			distance: 10,
+			expected: object.White,
+		},
+		{
+			name:     "An object beyond the light doesn't block it",
+			obj:      object.DefaultSphere(),
+			distance: 3,
+			expected: object.White,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			w := scene.NewWorld()
+			w.SetTransparentShadows(true)
+			w.AddObject(tt.obj)
+			actual := w.ShadowTransmittance(ray.NewPoint(0, 0, -5), ray.NewVec(0, 0, 1), tt.distance)
+			assertColorEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestWorld_ShadowTransmittance_opaqueByDefault(t *testing.T) {
+	m := object.DefaultMaterial()
+	m.Transparency = 1
+	w := scene.NewWorld()
+	w.AddObject(object.DefaultSphere(object.WithMaterial(m)))
+	assert.False(t, w.TransparentShadows())
+	assert.Equal(t, object.Black, w.ShadowTransmittance(ray.NewPoint(0, 0, -5), ray.NewVec(0, 0, 1), 10))
+}
+
+func TestShadeHit_receivesShadow(t *testing.T) {
+	testCases := []struct {
+		name     string
+		receives bool
+		expected object.RGB
+	}{
+		{name: "A shadowed object only gets ambient light", receives: true, expected: object.NewColor(0.1, 0.1, 0.1)},
+		{name: "An object that doesn't receive shadows ignores the blocker", receives: false, expected: object.NewColor(1.9, 1.9, 1.9)},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			w := scene.NewWorld()
+			w.AddLight(object.NewPointLight(ray.NewPoint(0, 0, -10), object.White))
+			s2 := object.DefaultSphere(
+				object.WithTransform(ray.Translation(0, 0, 10)),
+				object.WithShadows(true, tt.receives))
+			w.AddObjects(object.DefaultSphere(), s2)
+			r := ray.NewRayAt(ray.NewPoint(0, 0, 5), ray.NewVec(0, 0, 1))
+			i := object.Intersection{T: 4, Obj: s2}
+			comps := scene.PrepareComputations(i, r)
+			assertColorEqual(t, tt.expected, scene.ShadeHit(w, comps, 5))
+		})
+	}
+}
+
+func TestShadeHit_sdfMatchesSphere(t *testing.T) {
+	glass := object.DefaultMaterial()
+	glass.Transparency = 1
+	glass.RefractiveIndex = 1.5
+	glass.Diffuse = 0.1
+
+	testCases := []struct {
+		name     string
+		material object.Material
+		r        ray.Ray
+	}{
+		{
+			name:     "lit head on",
+			material: object.DefaultMaterial(),
+			r:        ray.NewRayAt(ray.NewPoint(0, 0, -5), ray.NewVec(0, 0, 1)),
+		},
+		{
+			name:     "lit off centre",
+			material: object.DefaultMaterial(),
+			r:        ray.NewRayAt(ray.NewPoint(-0.5, 0.4, -5), ray.NewVec(0, 0, 1)),
+		},
+		{
+			name:     "refracted through",
+			material: glass,
+			r:        ray.NewRayAt(ray.NewPoint(0.3, 0.2, -5), ray.NewVec(0, 0, 1)),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			colorAt := func(sphere object.Object) object.RGB {
+				w := scene.NewWorld()
+				w.AddLight(object.NewPointLight(ray.NewPoint(-10, 10, -10), object.White))
+				sphere.SetMaterial(tt.material)
+				floor := object.NewPlane()
+				require.NoError(t, floor.SetTransform(ray.Translation(0, -1, 0)))
+				m := floor.Material()
+				m.Pattern = object.NewCheckerPattern(object.White, object.Black)
+				floor.SetMaterial(m)
+				w.AddObjects(sphere, floor)
+				return w.ColorAt(tt.r, 5)
+			}
+
+			want := colorAt(object.DefaultSphere())
+			got := colorAt(object.NewSDF(object.SDFSphere(1)))
+			assert.Greater(t, want.R, 0.1, "more than ambient")
+			assert.InDelta(t, want.R, got.R, 0.001, "R")
+			assert.InDelta(t, want.G, got.G, 0.001, "G")
+			assert.InDelta(t, want.B, got.B, 0.001, "B")
+		})
+	}
+}
+
+func TestShadeHit_transparentShadows(t *testing.T) {
+	testCases := []struct {
+		name       string
+		reflective float64
+		expected   object.RGB
+	}{
+		{name: "The ball is lit through a transparent floor", expected: object.NewColor(1.31451, 0.68642, 0.68642)},
+		{name: "The ball is lit through a reflective transparent floor", reflective: 0.5, expected: object.NewColor(1.29609, 0.69643, 0.69243)},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := scene.DefaultWorld()
+			require.NoError(t, err)
+			w.SetTransparentShadows(true)
+
+			floorM := object.DefaultMaterial()
+			floorM.Reflective = tt.reflective
+			floorM.Transparency = 0.5
+			floorM.RefractiveIndex = 1.5
+			floor := object.NewPlane(
+				object.WithTransform(ray.Translation(0, -1, 0)),
+				object.WithMaterial(floorM))
+			w.AddObject(floor)
+
+			ballM := object.DefaultMaterial()
+			ballM.Color = object.NewColor(1, 0, 0)
+			ballM.Ambient = 0.5
+			w.AddObject(object.DefaultSphere(
+				object.WithTransform(ray.Translation(0, -3.5, -0.5)),
+				object.WithMaterial(ballM)))
+
+			r := ray.NewRayAt(ray.NewPoint(0, 0, -3), ray.NewVec(0, -math.Sqrt(2)/2, math.Sqrt(2)/2))
+			xs := object.Intersections{
+				{T: math.Sqrt(2), Obj: floor},
+			}
+			comps := scene.PrepareComputations(xs[0], r, xs...)
+			assertColorEqual(t, tt.expected, scene.ShadeHit(w, comps, 5))
+		})
+	}
+}
@@ -0,0 +1,490 @@
+// Package server renders scene files submitted over HTTP, queueing them as
+// jobs that can be watched, fetched once they're finished and cancelled.
+//
+//	POST   /jobs                 submit a YAML or JSON scene file
+//	GET    /jobs                 list the jobs
+//	GET    /jobs/{id}            a job's status and progress
+//	GET    /jobs/{id}/image.png  the finished image, or image.ppm
+//	DELETE /jobs/{id}            cancel the job
+//
+// Submitting takes samples, integrator, width and camera query parameters
+// to override the scene's, like the render command's flags.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/input"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/output"
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/scene"
+)
+
+const (
+	// maxSceneSize is the most a submitted scene file can be, models are
+	// loaded from the server's directory rather than sent
+	maxSceneSize = 10 << 20
+)
+
+type Status string
+
+const (
+	Queued    Status = "queued"
+	Rendering Status = "rendering"
+	Done      Status = "done"
+	Failed    Status = "failed"
+	Cancelled Status = "cancelled"
+)
+
+// Job is what's known about a submitted scene.
+type Job struct {
+	ID      string `json:"id"`
+	Status  Status `json:"status"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Samples int    `json:"samples"`
+	// Progress is how much of the image is rendered, from 0 to 1
+	Progress  float64    `json:"progress"`
+	Error     string     `json:"error,omitempty"`
+	Submitted time.Time  `json:"submitted"`
+	Started   *time.Time `json:"started,omitempty"`
+	Finished  *time.Time `json:"finished,omitempty"`
+}
+
+type job struct {
+	info Job
+	// file is built when the job starts, models can take a while to load
+	file   input.SceneFile
+	canvas scene.Canvas
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Server queues the submitted scenes, rendering them in order.
+type Server struct {
+	dir            string
+	workers        int
+	concurrentJobs int
+	limits         Limits
+	retention      int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	queue  chan *job
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	jobs     map[string]*job
+	order    []string
+	finished []string
+	nextID   int
+}
+
+// Limits are the biggest renders the server takes, so one job can't use up
+// all its memory or time. Zero is no limit.
+type Limits struct {
+	Width, Height, Samples int
+}
+
+func DefaultLimits() Limits {
+	return Limits{
+		Width:   4096,
+		Height:  4096,
+		Samples: 4096,
+	}
+}
+
+func (l Limits) check(width, height, samples int) error {
+	switch {
+	case l.Width > 0 && width > l.Width:
+		return fmt.Errorf("width %v is more than the most allowed, %v", width, l.Width)
+	case l.Height > 0 && height > l.Height:
+		return fmt.Errorf("height %v is more than the most allowed, %v", height, l.Height)
+	case l.Samples > 0 && samples > l.Samples:
+		return fmt.Errorf("samples %v is more than the most allowed, %v", samples, l.Samples)
+	}
+	return nil
+}
+
+type Option func(s *Server)
+
+// WithDir is where models and environment maps the scenes load are
+// relative to, the current directory by default. Scenes can't load files
+// from outside it.
+func WithDir(dir string) Option {
+	return func(s *Server) {
+		s.dir = dir
+	}
+}
+
+// WithWorkers renders each job across that many goroutines, one by
+// default.
+func WithWorkers(workers int) Option {
+	return func(s *Server) {
+		s.workers = workers
+	}
+}
+
+// WithConcurrentJobs renders that many jobs at once, one by default.
+func WithConcurrentJobs(n int) Option {
+	return func(s *Server) {
+		s.concurrentJobs = n
+	}
+}
+
+// WithQueueSize turns jobs away once that many are waiting, 100 by
+// default.
+func WithQueueSize(n int) Option {
+	return func(s *Server) {
+		s.queue = make(chan *job, n)
+	}
+}
+
+// WithLimits turns away jobs bigger than limits, DefaultLimits if not set.
+func WithLimits(limits Limits) Option {
+	return func(s *Server) {
+		s.limits = limits
+	}
+}
+
+// WithRetention keeps the last n finished jobs and their images, forgetting
+// older ones, 100 by default and at least one.
+func WithRetention(n int) Option {
+	return func(s *Server) {
+		s.retention = n
+	}
+}
+
+// NewServer starts rendering jobs as they're submitted, until it's closed.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		dir:            ".",
+		workers:        1,
+		concurrentJobs: 1,
+		limits:         DefaultLimits(),
+		retention:      100,
+		queue:          make(chan *job, 100),
+		jobs:           map[string]*job{},
+	}
+	for i := range opts {
+		opts[i](s)
+	}
+	if s.workers < 1 {
+		s.workers = 1
+	}
+	if s.concurrentJobs < 1 {
+		s.concurrentJobs = 1
+	}
+	if s.retention < 1 {
+		s.retention = 1
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.wg.Add(s.concurrentJobs)
+	for i := 0; i < s.concurrentJobs; i++ {
+		go s.run()
+	}
+	return s
+}
+
+// Close cancels every job, waiting for the ones rendering to stop.
+func (s *Server) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case path == "jobs" && r.Method == http.MethodGet:
+		s.list(w)
+	case path == "jobs" && r.Method == http.MethodPost:
+		s.submit(w, r)
+	case len(parts) == 2 && parts[0] == "jobs" && r.Method == http.MethodGet:
+		s.status(w, parts[1])
+	case len(parts) == 2 && parts[0] == "jobs" && r.Method == http.MethodDelete:
+		s.cancelJob(w, parts[1])
+	case len(parts) == 3 && parts[0] == "jobs" && r.Method == http.MethodGet:
+		s.image(w, parts[1], parts[2])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	sf, err := input.ParseSceneFile(http.MaxBytesReader(w, r.Body, maxSceneSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = override(&sf, r.URL.Query()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = sf.Within(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	width, height := sf.Size()
+	samples := sf.Render.Samples
+	if samples < 1 {
+		samples = scene.DefaultRenderSettings().Samples
+	}
+	if err = s.limits.check(width, height, samples); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err = scene.ParseIntegrator(sf.Render.Integrator); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	j := &job{
+		info: Job{
+			ID:        strconv.Itoa(s.nextID),
+			Status:    Queued,
+			Width:     width,
+			Height:    height,
+			Samples:   samples,
+			Submitted: time.Now(),
+		},
+		file: sf,
+	}
+	j.ctx, j.cancel = context.WithCancel(s.ctx)
+	select {
+	case s.queue <- j:
+	default:
+		s.mu.Unlock()
+		j.cancel()
+		http.Error(w, "too many jobs queued, try again later", http.StatusServiceUnavailable)
+		return
+	}
+	s.jobs[j.info.ID] = j
+	s.order = append(s.order, j.info.ID)
+	info := j.info
+	s.mu.Unlock()
+
+	w.Header().Set("Location", "/jobs/"+info.ID)
+	writeJSON(w, http.StatusCreated, info)
+}
+
+// override changes the scene like the render command's flags do.
+func override(sf *input.SceneFile, q url.Values) error {
+	if v := q.Get("width"); v != "" {
+		width, err := strconv.Atoi(v)
+		if err != nil || width < 1 {
+			return fmt.Errorf("width %q isn't a number of pixels", v)
+		}
+		w, h := sf.Size()
+		sf.Width, sf.Height = width, width*h/w
+		if sf.Height < 1 {
+			sf.Height = 1
+		}
+	}
+	if v := q.Get("samples"); v != "" {
+		samples, err := strconv.Atoi(v)
+		if err != nil || samples < 1 {
+			return fmt.Errorf("samples %q isn't a number of samples", v)
+		}
+		sf.Render.Samples = samples
+	}
+	if v := q.Get("integrator"); v != "" {
+		sf.Render.Integrator = v
+	}
+	if v := q.Get("camera"); v != "" {
+		sf.Camera.Projection = v
+	}
+	return nil
+}
+
+func (s *Server) list(w http.ResponseWriter) {
+	s.mu.Lock()
+	jobs := make([]Job, 0, len(s.order))
+	for _, id := range s.order {
+		jobs = append(jobs, s.jobs[id].info)
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (s *Server) status(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	j, ok := s.jobs[id]
+	var info Job
+	if ok {
+		info = j.info
+	}
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "no such job", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// cancelJob stops a job rendering, or from rendering if it's still queued.
+func (s *Server) cancelJob(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	j, ok := s.jobs[id]
+	if !ok {
+		s.mu.Unlock()
+		http.Error(w, "no such job", http.StatusNotFound)
+		return
+	}
+	switch j.info.Status {
+	case Queued:
+		s.finish(j, Cancelled, nil)
+	case Rendering:
+		// run marks it cancelled once it's stopped
+	default:
+		info := j.info
+		s.mu.Unlock()
+		writeJSON(w, http.StatusConflict, info)
+		return
+	}
+	j.cancel()
+	info := j.info
+	s.mu.Unlock()
+	writeJSON(w, http.StatusAccepted, info)
+}
+
+func (s *Server) image(w http.ResponseWriter, id, name string) {
+	s.mu.Lock()
+	j, ok := s.jobs[id]
+	var status Status
+	var canvas scene.Canvas
+	if ok {
+		status, canvas = j.info.Status, j.canvas
+	}
+	s.mu.Unlock()
+	switch {
+	case !ok || (name != "image.png" && name != "image.ppm"):
+		http.Error(w, "no such job", http.StatusNotFound)
+		return
+	case status != Done:
+		http.Error(w, fmt.Sprintf("job is %s", status), http.StatusConflict)
+		return
+	}
+
+	img := canvas.GenerateImg()
+	if name == "image.png" {
+		w.Header().Set("Content-Type", "image/png")
+		_ = png.Encode(w, img)
+		return
+	}
+	ppm, err := output.NewPPMOutput(img)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/x-portable-pixmap")
+	_, _ = io.Copy(w, ppm)
+}
+
+// run renders the queued jobs one after another until the server's closed.
+func (s *Server) run() {
+	defer s.wg.Done()
+	for {
+		select {
+		case j := <-s.queue:
+			s.render(j)
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Server) render(j *job) {
+	s.mu.Lock()
+	if j.info.Status != Queued {
+		// cancelled while it was queued
+		s.mu.Unlock()
+		return
+	}
+	started := time.Now()
+	j.info.Status = Rendering
+	j.info.Started = &started
+	s.mu.Unlock()
+
+	sc, err := j.file.Build(s.dir)
+	if err != nil {
+		j.cancel()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.finish(j, Failed, err)
+		return
+	}
+
+	events := make(chan scene.TileEvent, s.workers)
+	progressed := make(chan struct{})
+	go func() {
+		defer close(progressed)
+		for e := range events {
+			s.mu.Lock()
+			j.info.Progress = float64(e.Done) / float64(e.Total)
+			s.mu.Unlock()
+		}
+	}()
+	canvas, err := scene.RenderTiles(j.ctx, sc.Camera, sc.World, sc.Settings,
+		scene.WithWorkers(s.workers), scene.WithTileEvents(events))
+	close(events)
+	<-progressed
+	j.cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case errors.Is(err, context.Canceled):
+		s.finish(j, Cancelled, nil)
+	case err != nil:
+		s.finish(j, Failed, err)
+	default:
+		j.canvas = canvas
+		j.info.Progress = 1
+		s.finish(j, Done, nil)
+	}
+}
+
+// finish records how the job ended, with the server locked, forgetting the
+// oldest finished jobs past the retention.
+func (s *Server) finish(j *job, status Status, err error) {
+	finished := time.Now()
+	j.info.Status = status
+	j.info.Finished = &finished
+	if err != nil {
+		j.info.Error = err.Error()
+	}
+	// the scene's no longer needed
+	j.file = input.SceneFile{}
+
+	s.finished = append(s.finished, j.info.ID)
+	for len(s.finished) > s.retention {
+		id := s.finished[0]
+		s.finished = s.finished[1:]
+		delete(s.jobs, id)
+		for i := range s.order {
+			if s.order[i] == id {
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
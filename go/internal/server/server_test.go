@@ -0,0 +1,217 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlosroman/aun-otra-ray-tracer/go/internal/server"
+)
+
+const sceneYAML = `
+width: 40
+height: 20
+camera:
+  from: [0, 1, -5]
+  to: [0, 1, 0]
+light:
+  position: [-10, 10, -10]
+objects:
+  - type: sphere
+    material:
+      color: [1, 0, 0]
+`
+
+func do(t *testing.T, method, url, body string) (*http.Response, []byte) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, b
+}
+
+func submit(t *testing.T, url, query, scene string) server.Job {
+	resp, body := do(t, http.MethodPost, url+"/jobs"+query, scene)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+	var job server.Job
+	require.NoError(t, json.Unmarshal(body, &job))
+	assert.Equal(t, "/jobs/"+job.ID, resp.Header.Get("Location"))
+	return job
+}
+
+func status(t *testing.T, url, id string) server.Job {
+	resp, body := do(t, http.MethodGet, url+"/jobs/"+id, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	var job server.Job
+	require.NoError(t, json.Unmarshal(body, &job))
+	return job
+}
+
+func waitFor(t *testing.T, url, id string, want server.Status) server.Job {
+	var job server.Job
+	require.Eventually(t, func() bool {
+		job = status(t, url, id)
+		return job.Status == want
+	}, 10*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestServer(t *testing.T) {
+	s := server.NewServer(server.WithWorkers(2))
+	defer s.Close()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	job := submit(t, srv.URL, "?samples=2&width=20", sceneYAML)
+	assert.Equal(t, 20, job.Width)
+	assert.Equal(t, 10, job.Height, "keeps the aspect ratio")
+	assert.Equal(t, 2, job.Samples)
+
+	job = waitFor(t, srv.URL, job.ID, server.Done)
+	assert.Equal(t, float64(1), job.Progress)
+	assert.NotNil(t, job.Started)
+	assert.NotNil(t, job.Finished)
+
+	resp, body := do(t, http.MethodGet, srv.URL+"/jobs/"+job.ID+"/image.png", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	img, err := png.Decode(bytes.NewReader(body))
+	require.NoError(t, err)
+	assert.Equal(t, 20, img.Bounds().Dx())
+	assert.Equal(t, 10, img.Bounds().Dy())
+
+	resp, body = do(t, http.MethodGet, srv.URL+"/jobs/"+job.ID+"/image.ppm", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, bytes.HasPrefix(body, []byte("P3\n20 10\n")), string(body[:10]))
+
+	resp, _ = do(t, http.MethodDelete, srv.URL+"/jobs/"+job.ID, "")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "already finished")
+
+	resp, body = do(t, http.MethodGet, srv.URL+"/jobs", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var jobs []server.Job
+	require.NoError(t, json.Unmarshal(body, &jobs))
+	require.Len(t, jobs, 1)
+	assert.Equal(t, job.ID, jobs[0].ID)
+}
+
+func TestServer_cancel(t *testing.T) {
+	s := server.NewServer(server.WithQueueSize(1))
+	defer s.Close()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	// slow enough to still be rendering when it's cancelled
+	rendering := submit(t, srv.URL, "?samples=64&width=400", sceneYAML)
+	waitFor(t, srv.URL, rendering.ID, server.Rendering)
+	queued := submit(t, srv.URL, "", sceneYAML)
+	resp, _ := do(t, http.MethodPost, srv.URL+"/jobs", sceneYAML)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "queue full")
+
+	resp, _ = do(t, http.MethodDelete, srv.URL+"/jobs/"+queued.ID, "")
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, server.Cancelled, status(t, srv.URL, queued.ID).Status, "straight away when queued")
+
+	resp, _ = do(t, http.MethodGet, srv.URL+"/jobs/"+rendering.ID+"/image.png", "")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "not finished")
+
+	resp, _ = do(t, http.MethodDelete, srv.URL+"/jobs/"+rendering.ID, "")
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	waitFor(t, srv.URL, rendering.ID, server.Cancelled)
+}
+
+func TestServer_errors(t *testing.T) {
+	s := server.NewServer()
+	defer s.Close()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{name: "not a scene", method: http.MethodPost, path: "/jobs", body: "width: [", want: http.StatusBadRequest},
+		{name: "unknown field", method: http.MethodPost, path: "/jobs", body: "size: 10", want: http.StatusBadRequest},
+		{name: "bad samples", method: http.MethodPost, path: "/jobs?samples=lots", body: sceneYAML, want: http.StatusBadRequest},
+		{name: "bad width", method: http.MethodPost, path: "/jobs?width=0", body: sceneYAML, want: http.StatusBadRequest},
+		{name: "bad integrator", method: http.MethodPost, path: "/jobs?integrator=magic", body: sceneYAML, want: http.StatusBadRequest},
+		{name: "too wide", method: http.MethodPost, path: "/jobs?width=100000", body: sceneYAML, want: http.StatusBadRequest},
+		{name: "too many samples", method: http.MethodPost, path: "/jobs?samples=100000", body: sceneYAML, want: http.StatusBadRequest},
+		{name: "too tall", method: http.MethodPost, path: "/jobs", body: "height: 100000", want: http.StatusBadRequest},
+		{name: "absolute model", method: http.MethodPost, path: "/jobs", body: "objects: [{type: model, file: /etc/passwd}]", want: http.StatusBadRequest},
+		{name: "model outside the directory", method: http.MethodPost, path: "/jobs", body: "objects: [{type: model, file: ../../go.mod}]", want: http.StatusBadRequest},
+		{name: "environment outside the directory", method: http.MethodPost, path: "/jobs", body: "environment: ../sky.hdr", want: http.StatusBadRequest},
+		{name: "unknown job", method: http.MethodGet, path: "/jobs/42", want: http.StatusNotFound},
+		{name: "unknown job image", method: http.MethodGet, path: "/jobs/42/image.png", want: http.StatusNotFound},
+		{name: "cancel unknown job", method: http.MethodDelete, path: "/jobs/42", want: http.StatusNotFound},
+		{name: "unknown path", method: http.MethodGet, path: "/render", want: http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, body := do(t, tc.method, srv.URL+tc.path, tc.body)
+			assert.Equal(t, tc.want, resp.StatusCode, string(body))
+		})
+	}
+}
+
+func TestServer_widthKeepsAspectRatio(t *testing.T) {
+	s := server.NewServer()
+	defer s.Close()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	job := submit(t, srv.URL, "?width=64", "light: {position: [-10, 10, -10]}")
+	assert.Equal(t, 64, job.Width)
+	assert.Equal(t, 32, job.Height, "the default size is 2:1")
+}
+
+func TestServer_failed(t *testing.T) {
+	s := server.NewServer(server.WithDir(t.TempDir()))
+	defer s.Close()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	job := submit(t, srv.URL, "", "objects: [{type: model, file: missing.obj}]")
+	job = waitFor(t, srv.URL, job.ID, server.Failed)
+	assert.Contains(t, job.Error, "missing.obj")
+}
+
+func TestServer_retention(t *testing.T) {
+	s := server.NewServer(server.WithRetention(2))
+	defer s.Close()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		job := submit(t, srv.URL, "?width=8", sceneYAML)
+		waitFor(t, srv.URL, job.ID, server.Done)
+		ids = append(ids, job.ID)
+	}
+
+	resp, _ := do(t, http.MethodGet, srv.URL+"/jobs/"+ids[0], "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "the oldest is forgotten")
+	resp, body := do(t, http.MethodGet, srv.URL+"/jobs", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var jobs []server.Job
+	require.NoError(t, json.Unmarshal(body, &jobs))
+	require.Len(t, jobs, 2)
+	assert.Equal(t, ids[1], jobs[0].ID)
+	assert.Equal(t, ids[2], jobs[1].ID)
+}